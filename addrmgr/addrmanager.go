@@ -47,6 +47,21 @@ type AddrManager struct {
 	lamtx          sync.Mutex
 	localAddresses map[string]*localAddress
 	version        int
+
+	// addrResponses caches the responses to getaddr requests by the network
+	// group of the requesting peer.  addrResponseFallback is the response
+	// shared by all network groups which don't fit in the cache anymore.
+	// Both are protected by addrResponseMtx.
+	addrResponseMtx      sync.Mutex
+	addrResponses        map[string]*addrResponse
+	addrResponseFallback *addrResponse
+}
+
+// addrResponse is a cached response to getaddr requests which is served for
+// the duration of a single time bucket.
+type addrResponse struct {
+	bucket int64
+	addrs  []*wire.NetAddressV2
 }
 
 type serializedKnownAddress struct {
@@ -157,6 +172,18 @@ const (
 	// will share with a call to AddressCache.
 	getAddrPercent = 23
 
+	// addrResponseInterval is the duration of the time buckets for which
+	// the response to getaddr requests from a network group is cached.
+	// Serving the same addresses for the entire interval prevents peers
+	// from enumerating the address table by repeatedly connecting.
+	addrResponseInterval = 24 * time.Hour
+
+	// maxAddrResponseGroups is the maximum number of network groups
+	// responses to getaddr requests are cached for at once.  Peers from
+	// further network groups share a single response until the time bucket
+	// ends.
+	maxAddrResponseGroups = 1024
+
 	// serialisationVersion is the current version of the on-disk format.
 	serialisationVersion = 2
 )
@@ -684,6 +711,64 @@ func (a *AddrManager) AddressCache() []*wire.NetAddressV2 {
 	return allAddr[0:numAddresses]
 }
 
+// AddressCacheForGroup returns the addresses to serve in response to a getaddr
+// request from a peer in the passed network group as returned by GroupKey.  All
+// peers in the same network group are served the same addresses until the
+// current time bucket ends so repeated requests can't be used to enumerate the
+// full address table.  At most wire.MaxAddrPerMsg addresses are returned so
+// they can be served in a single message as is.  The returned addresses must be
+// treated as read-only.
+func (a *AddrManager) AddressCacheForGroup(group string) []*wire.NetAddressV2 {
+	return a.addressCacheForGroup(group, time.Now())
+}
+
+// addressCacheForGroup implements AddressCacheForGroup using the passed time
+// to determine the current time bucket.
+func (a *AddrManager) addressCacheForGroup(group string, now time.Time) []*wire.NetAddressV2 {
+	bucket := now.Unix() / int64(addrResponseInterval/time.Second)
+
+	a.addrResponseMtx.Lock()
+	defer a.addrResponseMtx.Unlock()
+
+	if resp, ok := a.addrResponses[group]; ok && resp.bucket == bucket {
+		return resp.addrs
+	}
+
+	// Evict the responses of previous time buckets.  Responses of the
+	// current time bucket are never evicted since a peer could otherwise
+	// obtain fresh addresses by cycling through enough network groups.
+	// Instead, once the cache is full, the network groups which don't fit
+	// in it share a single fallback response.
+	for key, resp := range a.addrResponses {
+		if resp.bucket != bucket {
+			delete(a.addrResponses, key)
+		}
+	}
+	if len(a.addrResponses) >= maxAddrResponseGroups {
+		resp := a.addrResponseFallback
+		if resp == nil || resp.bucket != bucket {
+			resp = &addrResponse{bucket: bucket, addrs: a.addrResponse()}
+			a.addrResponseFallback = resp
+		}
+		return resp.addrs
+	}
+
+	addrs := a.addrResponse()
+	a.addrResponses[group] = &addrResponse{bucket: bucket, addrs: addrs}
+	return addrs
+}
+
+// addrResponse returns a new response to getaddr requests.  The addresses are
+// already in random order, so the response is capped to fit in a single
+// message by slicing off the excess.
+func (a *AddrManager) addrResponse() []*wire.NetAddressV2 {
+	addrs := a.AddressCache()
+	if len(addrs) > wire.MaxAddrPerMsg {
+		addrs = addrs[:wire.MaxAddrPerMsg]
+	}
+	return addrs
+}
+
 // getAddresses returns all of the addresses currently found within the
 // manager's address cache.
 func (a *AddrManager) getAddresses() []*wire.NetAddressV2 {
@@ -1046,6 +1131,28 @@ func (a *AddrManager) AddLocalAddress(na *wire.NetAddressV2, priority AddressPri
 	return nil
 }
 
+// LocalAddress describes a known local address along with the score which
+// determines how preferred it is when advertising a local address to peers.
+type LocalAddress struct {
+	NetAddress *wire.NetAddressV2
+	Score      AddressPriority
+}
+
+// LocalAddresses returns all known local addresses along with their scores.
+func (a *AddrManager) LocalAddresses() []LocalAddress {
+	a.lamtx.Lock()
+	defer a.lamtx.Unlock()
+
+	addrs := make([]LocalAddress, 0, len(a.localAddresses))
+	for _, la := range a.localAddresses {
+		addrs = append(addrs, LocalAddress{
+			NetAddress: la.na,
+			Score:      la.score,
+		})
+	}
+	return addrs
+}
+
 // getReachabilityFrom returns the relative reachability of the provided local
 // address to the provided remote address.
 func getReachabilityFrom(localAddr, remoteAddr *wire.NetAddressV2) int {
@@ -1212,6 +1319,7 @@ func New(dataDir string, lookupFunc func(string) ([]net.IP, error)) *AddrManager
 		quit:           make(chan struct{}),
 		localAddresses: make(map[string]*localAddress),
 		version:        serialisationVersion,
+		addrResponses:  make(map[string]*addrResponse),
 	}
 	am.reset()
 	return &am
@@ -5,6 +5,8 @@ import (
 	"math/rand"
 	"net"
 	"os"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -198,3 +200,98 @@ func TestAddrManagerV1ToV2(t *testing.T) {
 	addrMgr.loadPeers()
 	assertAddrs(t, addrMgr, expectedAddrs)
 }
+
+// TestAddressCacheForGroup ensures the responses to getaddr requests are
+// cached per network group for the duration of a time bucket.
+func TestAddressCacheForGroup(t *testing.T) {
+	t.Parallel()
+
+	addrMgr := New("", nil)
+	for i := 0; i < 100; i++ {
+		addr := wire.NetAddressV2FromBytes(time.Now(), 0,
+			net.IPv4(173, 194, byte(i), 1), 8333)
+		addrMgr.AddAddress(addr, addr)
+	}
+
+	now := time.Unix(0, 0).Add(addrResponseInterval)
+	first := addrMgr.addressCacheForGroup("a", now)
+	if len(first) == 0 {
+		t.Fatal("no addresses returned")
+	}
+
+	// Requests from the same group within the time bucket are served the
+	// same addresses.
+	later := now.Add(addrResponseInterval - time.Second)
+	if got := addrMgr.addressCacheForGroup("a", later); !reflect.DeepEqual(got, first) {
+		t.Fatalf("response changed within the time bucket")
+	}
+	if len(addrMgr.addrResponses) != 1 {
+		t.Fatalf("unexpected number of cached responses %d",
+			len(addrMgr.addrResponses))
+	}
+
+	// Once the time bucket ends, a new response is generated and the
+	// responses of the previous bucket are evicted.
+	next := now.Add(addrResponseInterval)
+	addrMgr.addressCacheForGroup("b", next)
+	if _, ok := addrMgr.addrResponses["a"]; ok {
+		t.Fatalf("response of previous time bucket was not evicted")
+	}
+	resp := addrMgr.addrResponses["b"]
+	if resp == nil || resp.bucket != next.Unix()/int64(addrResponseInterval/time.Second) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	// The number of cached responses is bounded without evicting the
+	// responses of the current time bucket.  The network groups which don't
+	// fit in the cache share a single response.
+	for i := 0; i < maxAddrResponseGroups-1; i++ {
+		addrMgr.addressCacheForGroup(strconv.Itoa(i), next)
+	}
+	cached := addrMgr.addrResponses["b"].addrs
+	fallback := addrMgr.addressCacheForGroup("c", next)
+	if len(addrMgr.addrResponses) != maxAddrResponseGroups {
+		t.Fatalf("unexpected number of cached responses %d",
+			len(addrMgr.addrResponses))
+	}
+	if _, ok := addrMgr.addrResponses["c"]; ok {
+		t.Fatalf("response cached beyond the limit")
+	}
+	if got := addrMgr.addressCacheForGroup("d", later.Add(time.Second)); !reflect.DeepEqual(got, fallback) {
+		t.Fatalf("network groups beyond the limit are served different " +
+			"responses")
+	}
+	if got := addrMgr.addressCacheForGroup("b", next); !reflect.DeepEqual(got, cached) {
+		t.Fatalf("response of current time bucket changed")
+	}
+
+	// The fallback response is replaced once the time bucket ends.
+	last := next.Add(addrResponseInterval)
+	addrMgr.addressCacheForGroup("e", last)
+	if len(addrMgr.addrResponses) != 1 {
+		t.Fatalf("unexpected number of cached responses %d",
+			len(addrMgr.addrResponses))
+	}
+	for i := 0; i < maxAddrResponseGroups; i++ {
+		addrMgr.addressCacheForGroup(strconv.Itoa(i), last)
+	}
+	resp = addrMgr.addrResponseFallback
+	if resp == nil || resp.bucket != last.Unix()/int64(addrResponseInterval/time.Second) {
+		t.Fatalf("unexpected fallback response %+v", resp)
+	}
+
+	// Responses fit in a single message.
+	addrMgr = New("", nil)
+	for i := 0; i < 10000; i++ {
+		addr := wire.NetAddressV2FromBytes(time.Now(), 0,
+			net.IPv4(byte(20+i%50), byte(i/50), 1, 1), 8333)
+		addrMgr.AddAddress(addr, addr)
+	}
+	if n := len(addrMgr.AddressCache()); n <= wire.MaxAddrPerMsg {
+		t.Fatalf("too few addresses to exceed the limit: %d", n)
+	}
+	if got := addrMgr.addressCacheForGroup("a", now); len(got) != wire.MaxAddrPerMsg {
+		t.Fatalf("unexpected number of addresses %d, want %d",
+			len(got), wire.MaxAddrPerMsg)
+	}
+}
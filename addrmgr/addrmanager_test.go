@@ -208,7 +208,8 @@ func TestAddLocalAddress(t *testing.T) {
 	}
 	amgr := addrmgr.New("testaddlocaladdress", nil)
 	for x, test := range tests {
-		result := amgr.AddLocalAddress(&test.address, test.priority)
+		address := test.address
+		result := amgr.AddLocalAddress(&address, test.priority)
 		if result == nil && !test.valid {
 			t.Errorf("TestAddLocalAddress test #%d failed: %s should have "+
 				"been accepted", x, test.address.Addr.String())
@@ -220,6 +221,25 @@ func TestAddLocalAddress(t *testing.T) {
 			continue
 		}
 	}
+
+	// Only the accepted addresses are known, and adding an address again
+	// with a higher priority raises its score.
+	wantScores := map[string]addrmgr.AddressPriority{
+		"204.124.1.1": addrmgr.BoundPrio + 1,
+		"2620:100::1": addrmgr.InterfacePrio,
+	}
+	localAddrs := amgr.LocalAddresses()
+	if len(localAddrs) != len(wantScores) {
+		t.Fatalf("TestAddLocalAddress: got %d local addresses, want %d",
+			len(localAddrs), len(wantScores))
+	}
+	for _, la := range localAddrs {
+		addr := la.NetAddress.Addr.String()
+		if score, ok := wantScores[addr]; !ok || la.Score != score {
+			t.Errorf("TestAddLocalAddress: unexpected local address %s "+
+				"with score %d", addr, la.Score)
+		}
+	}
 }
 
 func TestAttempt(t *testing.T) {
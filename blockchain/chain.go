@@ -101,6 +101,7 @@ type BlockChain struct {
 	sigCache            *txscript.SigCache
 	indexManager        IndexManager
 	hashCache           *txscript.HashCache
+	scriptFlagOverrides ScriptFlagOverrides
 
 	// The following fields are calculated based upon the provided chain
 	// parameters.  They are also set when the instance is created and
@@ -177,9 +178,11 @@ type BlockChain struct {
 	unknownRulesWarned bool
 
 	// The notifications field stores a slice of callbacks to be executed on
-	// certain blockchain events.
-	notificationsLock sync.RWMutex
-	notifications     []NotificationCallback
+	// certain blockchain events.  The replaySubscriptions field stores the
+	// subscriptions created by SubscribeFrom, which can be removed again.
+	notificationsLock   sync.RWMutex
+	notifications       []NotificationCallback
+	replaySubscriptions map[*replaySubscription]struct{}
 }
 
 // HaveBlock returns whether or not the chain instance has the block represented
@@ -1700,6 +1703,52 @@ type Config struct {
 	// This field can be nil if the caller is not interested in using a
 	// signature cache.
 	HashCache *txscript.HashCache
+
+	// ScriptFlagOverrides defines script verification flags to enable or
+	// disable in addition to the flags the consensus rules call for when
+	// validating the scripts of blocks.  It is intended for testing and
+	// fork research and is only allowed on the regression test and
+	// simulation test networks.
+	//
+	// The zero value applies the consensus rules unchanged.
+	ScriptFlagOverrides ScriptFlagOverrides
+}
+
+// ScriptFlagOverrides describes script verification flags which are forced on
+// or off regardless of the consensus rules.  Disabling a flag takes precedence
+// over enabling it.
+type ScriptFlagOverrides struct {
+	Enable  txscript.ScriptFlags
+	Disable txscript.ScriptFlags
+}
+
+// apply returns the passed script flags with the overrides applied.
+func (o ScriptFlagOverrides) apply(flags txscript.ScriptFlags) txscript.ScriptFlags {
+	return (flags | o.Enable) &^ o.Disable
+}
+
+// Validate returns an error when applying the overrides to the script flags
+// required by the consensus rules results in a combination the script engine
+// refuses to verify scripts with.  Since the required flags depend on the
+// height, the overrides are applied to the flags before any soft fork other
+// than pay-to-script-hash is active and once all of them are.  The test
+// networks overrides are allowed on enforce pay-to-script-hash for all blocks
+// after the genesis block since their timestamps are past its activation.
+func (o ScriptFlagOverrides) Validate() error {
+	consensusFlags := []txscript.ScriptFlags{
+		txscript.ScriptBip16,
+		txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures |
+			txscript.ScriptVerifyCheckLockTimeVerify |
+			txscript.ScriptVerifyCheckSequenceVerify |
+			txscript.ScriptVerifyWitness | txscript.ScriptStrictMultiSig |
+			txscript.ScriptVerifyTaproot,
+	}
+	for _, flags := range consensusFlags {
+		if err := o.apply(flags).Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -1714,6 +1763,18 @@ func New(config *Config) (*BlockChain, error) {
 	if config.TimeSource == nil {
 		return nil, AssertError("blockchain.New timesource is nil")
 	}
+	if config.ScriptFlagOverrides != (ScriptFlagOverrides{}) {
+		switch config.ChainParams.Net {
+		case wire.TestNet, wire.SimNet:
+		default:
+			return nil, AssertError("blockchain.New script flag " +
+				"overrides are only allowed on test networks")
+		}
+		if err := config.ScriptFlagOverrides.Validate(); err != nil {
+			return nil, AssertError("blockchain.New invalid script " +
+				"flag overrides: " + err.Error())
+		}
+	}
 
 	// Generate a checkpoint by height map from the provided checkpoints
 	// and assert the provided checkpoints are sorted by height as required.
@@ -1750,6 +1811,7 @@ func New(config *Config) (*BlockChain, error) {
 		blocksPerRetarget:   int32(targetTimespan / targetTimePerBlock),
 		index:               newBlockIndex(config.DB, params),
 		hashCache:           config.HashCache,
+		scriptFlagOverrides: config.ScriptFlagOverrides,
 		bestChain:           newChainView(nil),
 		orphans:             make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:         make(map[chainhash.Hash][]*orphanBlock),
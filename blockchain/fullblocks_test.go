@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/dogesuite/doged/blockchain"
@@ -308,3 +309,98 @@ func TestFullBlocks(t *testing.T) {
 		}
 	}
 }
+
+// TestSubscribeFromReorg ensures subscribers created by SubscribeFrom receive
+// the notifications to disconnect the blocks of a chain which is no longer the
+// main chain followed by the ones to connect the blocks of the new main chain,
+// both while replaying and when the reorg happens after going live.
+func TestSubscribeFromReorg(t *testing.T) {
+	tests, err := fullblocktests.Generate(false)
+	if err != nil {
+		t.Fatalf("failed to generate tests: %v", err)
+	}
+
+	chain, teardownFunc, err := chainSetup("subscribefromreorg",
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// recordNotifications returns a callback which records the names of
+	// the connected and disconnected blocks in the order the notifications
+	// are received.
+	names := make(map[chainhash.Hash]string)
+	recordNotifications := func(got *[]string) blockchain.NotificationCallback {
+		return func(n *blockchain.Notification) {
+			switch n.Type {
+			case blockchain.NTBlockConnected:
+				block := n.Data.(*btcutil.Block)
+				*got = append(*got, "+"+names[*block.Hash()])
+			case blockchain.NTBlockDisconnected:
+				block := n.Data.(*btcutil.Block)
+				*got = append(*got, "-"+names[*block.Hash()])
+			}
+		}
+	}
+
+	// Process the blocks of the basic forking and reorg tests, which build
+	// the following chain, and subscribe to the chain once b4 is the tip:
+	//
+	//   ... -> b1(0) -> b2(1) -> b5(2) -> b6(3)
+	//               \-> b3(1) -> b4(2)
+	var live []string
+	var b4Hash chainhash.Hash
+out:
+	for _, test := range tests {
+		for _, item := range test {
+			accepted, ok := item.(fullblocktests.AcceptedBlock)
+			if !ok {
+				continue
+			}
+			block := btcutil.NewBlock(accepted.Block)
+			block.SetHeight(accepted.Height)
+			names[*block.Hash()] = accepted.Name
+			_, _, err := chain.ProcessBlock(block, blockchain.BFNone)
+			if err != nil {
+				t.Fatalf("block %q should have been accepted: %v",
+					accepted.Name, err)
+			}
+
+			switch accepted.Name {
+			case "b4":
+				b4Hash = *block.Hash()
+				unsubscribe, err := chain.SubscribeFrom(&b4Hash,
+					recordNotifications(&live), nil)
+				if err != nil {
+					t.Fatalf("SubscribeFrom: unexpected error: %v",
+						err)
+				}
+				defer unsubscribe()
+
+			case "b6":
+				break out
+			}
+		}
+	}
+
+	want := []string{"-b4", "-b3", "+b2", "+b5", "+b6"}
+	if !reflect.DeepEqual(live, want) {
+		t.Fatalf("unexpected live notifications - got %v, want %v",
+			live, want)
+	}
+
+	// Replaying from b4 now that it is no longer part of the main chain must
+	// produce the same notifications.
+	var replayed []string
+	unsubscribe, err := chain.SubscribeFrom(&b4Hash,
+		recordNotifications(&replayed), nil)
+	if err != nil {
+		t.Fatalf("SubscribeFrom: unexpected error: %v", err)
+	}
+	defer unsubscribe()
+	if !reflect.DeepEqual(replayed, want) {
+		t.Fatalf("unexpected replayed notifications - got %v, want %v",
+			replayed, want)
+	}
+}
@@ -6,6 +6,11 @@ package blockchain
 
 import (
 	"fmt"
+	"sync"
+
+	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/chaincfg/chainhash"
+	"github.com/dogesuite/doged/database"
 )
 
 // NotificationType represents the type of a notification message.
@@ -67,6 +72,159 @@ func (b *BlockChain) Subscribe(callback NotificationCallback) {
 	b.notificationsLock.Unlock()
 }
 
+// replaySubscription houses the state of a subscription created by
+// SubscribeFrom.
+type replaySubscription struct {
+	mtx      sync.Mutex
+	callback NotificationCallback
+
+	// tip is the hash of the last block the subscriber was brought to by
+	// the notifications it received so far.
+	tip chainhash.Hash
+
+	// live indicates the replay finished and the subscriber receives the
+	// notifications sent by the chain from now on.
+	live bool
+}
+
+// notify delivers the passed notification sent by the chain to the subscriber
+// once the replay finished.  Notifications for blocks connected or
+// disconnected before the replay caught up with the chain do not extend the
+// tip of the subscriber and are skipped since the replay already reflected
+// them.
+func (s *replaySubscription) notify(n *Notification) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if !s.live {
+		return
+	}
+	switch n.Type {
+	case NTBlockConnected:
+		block := n.Data.(*btcutil.Block)
+		if block.MsgBlock().Header.PrevBlock != s.tip {
+			return
+		}
+		s.tip = *block.Hash()
+
+	case NTBlockDisconnected:
+		block := n.Data.(*btcutil.Block)
+		if *block.Hash() != s.tip {
+			return
+		}
+		s.tip = block.MsgBlock().Header.PrevBlock
+	}
+	s.callback(n)
+}
+
+// SubscribeFrom registers a callback in the same manner as Subscribe, except
+// the callback is first brought up to date with the main chain starting from
+// the passed block, which is typically the last block processed by the
+// subscriber.  To do so, the callback receives an NTBlockDisconnected
+// notification for every block from the passed one back to the point it forks
+// from the main chain, when it is not part of the main chain, followed by an
+// NTBlockConnected notification for every main chain block after that point.
+// Afterwards, the callback receives the notifications of the chain as they
+// happen without any gaps or duplicates in between.
+//
+// The replay is performed by the calling goroutine and stops early with an
+// error when the passed interrupt channel is closed.  The returned function
+// removes the subscription and must not be invoked from within the callback.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SubscribeFrom(hash *chainhash.Hash,
+	callback NotificationCallback,
+	interrupt <-chan struct{}) (func(), error) {
+
+	node := b.index.LookupNode(hash)
+	if node == nil || !b.index.NodeStatus(node).HaveData() {
+		return nil, fmt.Errorf("block %s is not known", hash)
+	}
+
+	// Register the subscription before replaying so that no notification
+	// sent after the replay caught up can be missed.
+	sub := &replaySubscription{callback: callback, tip: *hash}
+	b.notificationsLock.Lock()
+	if b.replaySubscriptions == nil {
+		b.replaySubscriptions = make(map[*replaySubscription]struct{})
+	}
+	b.replaySubscriptions[sub] = struct{}{}
+	b.notificationsLock.Unlock()
+	unsubscribe := func() {
+		b.notificationsLock.Lock()
+		delete(b.replaySubscriptions, sub)
+		b.notificationsLock.Unlock()
+	}
+
+	for {
+		// Determine the blocks to detach and attach in order to bring
+		// the subscriber to the current best block.  The chain lock
+		// ensures the best chain does not change while doing so, which
+		// allows the subscription to go live atomically once there is
+		// nothing left to replay.
+		b.chainLock.RLock()
+		tipNode := b.index.LookupNode(&sub.tip)
+		fork := b.bestChain.FindFork(tipNode)
+		var detachNodes, attachNodes []*blockNode
+		for n := tipNode; n != fork; n = n.parent {
+			detachNodes = append(detachNodes, n)
+		}
+		for n := b.bestChain.Next(fork); n != nil; n = b.bestChain.Next(n) {
+			attachNodes = append(attachNodes, n)
+		}
+		if len(detachNodes) == 0 && len(attachNodes) == 0 {
+			sub.mtx.Lock()
+			sub.live = true
+			sub.mtx.Unlock()
+			b.chainLock.RUnlock()
+			return unsubscribe, nil
+		}
+		b.chainLock.RUnlock()
+
+		// Replay the notifications without holding the chain lock so
+		// the chain can make progress in the meantime.  Any blocks it
+		// processes are picked up by the next iteration.
+		replay := func(n *blockNode, typ NotificationType) error {
+			if interruptRequested(interrupt) {
+				return errInterruptRequested
+			}
+
+			var block *btcutil.Block
+			err := b.db.View(func(dbTx database.Tx) error {
+				var err error
+				block, err = dbFetchBlockByNode(dbTx, n)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			callback(&Notification{Type: typ, Data: block})
+
+			sub.mtx.Lock()
+			if typ == NTBlockConnected {
+				sub.tip = n.hash
+			} else {
+				sub.tip = n.parent.hash
+			}
+			sub.mtx.Unlock()
+			return nil
+		}
+		for _, n := range detachNodes {
+			if err := replay(n, NTBlockDisconnected); err != nil {
+				unsubscribe()
+				return nil, err
+			}
+		}
+		for _, n := range attachNodes {
+			if err := replay(n, NTBlockConnected); err != nil {
+				unsubscribe()
+				return nil, err
+			}
+		}
+	}
+}
+
 // sendNotification sends a notification with the passed type and data if the
 // caller requested notifications by providing a callback function in the call
 // to New.
@@ -77,5 +235,8 @@ func (b *BlockChain) sendNotification(typ NotificationType, data interface{}) {
 	for _, callback := range b.notifications {
 		callback(&n)
 	}
+	for sub := range b.replaySubscriptions {
+		sub.notify(&n)
+	}
 	b.notificationsLock.RUnlock()
 }
@@ -5,8 +5,10 @@
 package blockchain
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/dogesuite/doged/btcutil"
 	"github.com/dogesuite/doged/chaincfg"
 )
 
@@ -49,3 +51,69 @@ func TestNotifications(t *testing.T) {
 			"times, found %d", numSubscribers, notificationCount)
 	}
 }
+
+// TestSubscribeFrom ensures subscribers are brought up to date with the main
+// chain from the passed block before receiving the notifications of the chain.
+func TestSubscribeFrom(t *testing.T) {
+	blocks, err := loadBlocks("blk_0_to_4.dat.bz2")
+	if err != nil {
+		t.Fatalf("Error loading file: %v\n", err)
+	}
+
+	chain, teardownFunc, err := chainSetup("subscribefrom",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block chain, set the coinbase
+	// maturity to 1.
+	chain.TstSetCoinbaseMaturity(1)
+
+	for i := 1; i < 4; i++ {
+		_, _, err = chain.ProcessBlock(blocks[i], BFNone)
+		if err != nil {
+			t.Fatalf("ProcessBlock fail on block %d: %v\n", i, err)
+		}
+	}
+
+	// Unknown blocks can't be replayed from.
+	_, err = chain.SubscribeFrom(blocks[4].Hash(), func(*Notification) {},
+		nil)
+	if err == nil {
+		t.Fatal("SubscribeFrom: expected error for unknown block")
+	}
+
+	var connected []int32
+	callback := func(n *Notification) {
+		switch n.Type {
+		case NTBlockAccepted:
+			return
+		case NTBlockDisconnected:
+			t.Errorf("unexpected notification %v", n.Type)
+			return
+		}
+		connected = append(connected, n.Data.(*btcutil.Block).Height())
+	}
+	unsubscribe, err := chain.SubscribeFrom(blocks[1].Hash(), callback, nil)
+	if err != nil {
+		t.Fatalf("SubscribeFrom: unexpected error: %v", err)
+	}
+
+	// The replay must be followed by the live notifications.
+	_, _, err = chain.ProcessBlock(blocks[4], BFNone)
+	if err != nil {
+		t.Fatalf("ProcessBlock fail on block 4: %v\n", err)
+	}
+	if !reflect.DeepEqual(connected, []int32{2, 3, 4}) {
+		t.Fatalf("unexpected connected heights - got %v, want %v",
+			connected, []int32{2, 3, 4})
+	}
+
+	// No notifications are delivered after unsubscribing.
+	unsubscribe()
+	if len(chain.replaySubscriptions) != 0 {
+		t.Fatal("subscription was not removed")
+	}
+}
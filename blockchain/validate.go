@@ -964,6 +964,81 @@ func CheckTransactionInputs(tx *btcutil.Tx, txHeight int32, utxoView *UtxoViewpo
 	return txFeeInSatoshi, nil
 }
 
+// blockScriptFlags returns the script verification flags the scripts of a
+// block with the passed timestamp and version which extends the passed node
+// must be validated with.  This includes any configured script flag overrides.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) blockScriptFlags(prevNode *blockNode, timestamp int64, version int32) (txscript.ScriptFlags, error) {
+	height := prevNode.height + 1
+
+	// Blocks created after the BIP0016 activation time need to have the
+	// pay-to-script-hash checks enabled.
+	var scriptFlags txscript.ScriptFlags
+	if timestamp >= txscript.Bip16Activation.Unix() {
+		scriptFlags |= txscript.ScriptBip16
+	}
+
+	// Enforce DER signatures for block versions 3+ once the historical
+	// activation threshold has been reached.  This is part of BIP0066.
+	if version >= 3 && height >= b.chainParams.BIP0066Height {
+		scriptFlags |= txscript.ScriptVerifyDERSignatures
+	}
+
+	// Enforce CHECKLOCKTIMEVERIFY for block versions 4+ once the historical
+	// activation threshold has been reached.  This is part of BIP0065.
+	if version >= 4 && height >= b.chainParams.BIP0065Height {
+		scriptFlags |= txscript.ScriptVerifyCheckLockTimeVerify
+	}
+
+	// Enforce CHECKSEQUENCEVERIFY once the soft-fork deployment is fully
+	// active.
+	csvState, err := b.deploymentState(prevNode, chaincfg.DeploymentCSV)
+	if err != nil {
+		return 0, err
+	}
+	if csvState == ThresholdActive {
+		scriptFlags |= txscript.ScriptVerifyCheckSequenceVerify
+	}
+
+	// Enforce the segwit soft-fork package once the soft-fork has shifted
+	// into the "active" version bits state.
+	segwitState, err := b.deploymentState(prevNode, chaincfg.DeploymentSegwit)
+	if err != nil {
+		return 0, err
+	}
+	if segwitState == ThresholdActive {
+		scriptFlags |= txscript.ScriptVerifyWitness
+		scriptFlags |= txscript.ScriptStrictMultiSig
+	}
+
+	// Enforce taproot once its deployment is active as well.
+	taprootState, err := b.deploymentState(
+		prevNode, chaincfg.DeploymentTaproot,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if taprootState == ThresholdActive {
+		scriptFlags |= txscript.ScriptVerifyTaproot
+	}
+
+	return b.scriptFlagOverrides.apply(scriptFlags), nil
+}
+
+// NextScriptFlags returns the script verification flags, including any
+// configured overrides, the scripts of a block which extends the current best
+// chain are validated with.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) NextScriptFlags() (txscript.ScriptFlags, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	return b.blockScriptFlags(b.bestChain.Tip(),
+		b.timeSource.AdjustedTime().Unix(), vbTopBits)
+}
+
 // checkConnectBlock performs several checks to confirm connecting the passed
 // block to the chain represented by the passed view does not violate any rules.
 // In addition, the passed view is updated to spend all of the referenced
@@ -1152,38 +1227,23 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 		runScripts = false
 	}
 
-	// Blocks created after the BIP0016 activation time need to have the
-	// pay-to-script-hash checks enabled.
-	var scriptFlags txscript.ScriptFlags
-	if enforceBIP0016 {
-		scriptFlags |= txscript.ScriptBip16
-	}
-
-	// Enforce DER signatures for block versions 3+ once the historical
-	// activation threshold has been reached.  This is part of BIP0066.
+	// Determine the script verification flags the block must be validated
+	// with.
 	blockHeader := &block.MsgBlock().Header
-	if blockHeader.Version >= 3 && node.height >= b.chainParams.BIP0066Height {
-		scriptFlags |= txscript.ScriptVerifyDERSignatures
-	}
-
-	// Enforce CHECKLOCKTIMEVERIFY for block versions 4+ once the historical
-	// activation threshold has been reached.  This is part of BIP0065.
-	if blockHeader.Version >= 4 && node.height >= b.chainParams.BIP0065Height {
-		scriptFlags |= txscript.ScriptVerifyCheckLockTimeVerify
+	scriptFlags, err := b.blockScriptFlags(node.parent, node.timestamp,
+		blockHeader.Version)
+	if err != nil {
+		return err
 	}
 
-	// Enforce CHECKSEQUENCEVERIFY during all block validation checks once
-	// the soft-fork deployment is fully active.
+	// Enforce the relative lock-times of CHECKSEQUENCEVERIFY during all
+	// block validation checks once the soft-fork deployment is fully
+	// active.
 	csvState, err := b.deploymentState(node.parent, chaincfg.DeploymentCSV)
 	if err != nil {
 		return err
 	}
 	if csvState == ThresholdActive {
-		// If the CSV soft-fork is now active, then modify the
-		// scriptFlags to ensure that the CSV op code is properly
-		// validated during the script checks bleow.
-		scriptFlags |= txscript.ScriptVerifyCheckSequenceVerify
-
 		// We obtain the MTP of the *previous* block in order to
 		// determine if transactions in the current block are final.
 		medianTime := node.parent.CalcPastMedianTime()
@@ -1211,25 +1271,6 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 		}
 	}
 
-	// Enforce the segwit soft-fork package once the soft-fork has shifted
-	// into the "active" version bits state.
-	if enforceSegWit {
-		scriptFlags |= txscript.ScriptVerifyWitness
-		scriptFlags |= txscript.ScriptStrictMultiSig
-	}
-
-	// Before we execute the main scripts, we'll also check to see if
-	// taproot is active or not.
-	taprootState, err := b.deploymentState(
-		node.parent, chaincfg.DeploymentTaproot,
-	)
-	if err != nil {
-		return err
-	}
-	if taprootState == ThresholdActive {
-		scriptFlags |= txscript.ScriptVerifyTaproot
-	}
-
 	// Now that the inexpensive checks are done and have passed, verify the
 	// transactions are actually allowed to spend the coins by running the
 	// expensive ECDSA signature check scripts.  Doing this last helps
@@ -12,6 +12,7 @@ import (
 
 	"github.com/dogesuite/doged/chaincfg"
 	"github.com/dogesuite/doged/chaincfg/chainhash"
+	"github.com/dogesuite/doged/txscript"
 	"github.com/dogesuite/doged/wire"
 	"github.com/dogesuite/doged/btcutil"
 )
@@ -485,3 +486,42 @@ var Block100000 = wire.MsgBlock{
 		},
 	},
 }
+
+// TestScriptFlagOverrides ensures the configured script flag overrides are
+// applied on top of the script flags required by the consensus rules.
+func TestScriptFlagOverrides(t *testing.T) {
+	chain := newFakeChain(&chaincfg.RegressionNetParams)
+
+	consensusFlags, err := chain.NextScriptFlags()
+	if err != nil {
+		t.Fatalf("NextScriptFlags: unexpected error: %v", err)
+	}
+	if consensusFlags&txscript.ScriptBip16 == 0 {
+		t.Fatalf("P2SH is not enforced - got %v", consensusFlags.Names())
+	}
+
+	chain.scriptFlagOverrides = ScriptFlagOverrides{
+		Enable:  txscript.ScriptVerifyCleanStack | txscript.ScriptVerifyTaproot,
+		Disable: txscript.ScriptStrictMultiSig | txscript.ScriptVerifyTaproot,
+	}
+	if err := chain.scriptFlagOverrides.Validate(); err != nil {
+		t.Fatalf("Validate: unexpected error: %v", err)
+	}
+	flags, err := chain.NextScriptFlags()
+	if err != nil {
+		t.Fatalf("NextScriptFlags: unexpected error: %v", err)
+	}
+	want := (consensusFlags | txscript.ScriptVerifyCleanStack) &^
+		(txscript.ScriptStrictMultiSig | txscript.ScriptVerifyTaproot)
+	if flags != want {
+		t.Fatalf("unexpected script flags - got %v, want %v",
+			flags.Names(), want.Names())
+	}
+
+	// Disabling P2SH leaves witness verification without it once segwit
+	// is active, which the script engine refuses.
+	invalid := ScriptFlagOverrides{Disable: txscript.ScriptBip16}
+	if err := invalid.Validate(); err == nil {
+		t.Fatalf("Validate: expected error for %v", invalid.Disable.Names())
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"runtime"
 	"runtime/debug"
 	"runtime/pprof"
+	"strings"
 
 	"github.com/dogesuite/doged/blockchain/indexers"
 	"github.com/dogesuite/doged/database"
@@ -64,6 +65,35 @@ func btcdMain(serverChan chan<- *server) error {
 	// Show version at startup.
 	btcdLog.Infof("Version %s", version())
 
+	// Write the pid file if requested so supervisors can identify the
+	// process.  It is removed again on shutdown.
+	if cfg.PidFile != "" {
+		pidFile, err := createPidFile(cfg.PidFile)
+		if err != nil {
+			btcdLog.Errorf("Unable to create pid file: %v", err)
+			return err
+		}
+		if pidFile.stalePid != "" {
+			btcdLog.Warnf("Replaced stale pid file %s of process %s "+
+				"which did not exit cleanly", cfg.PidFile,
+				pidFile.stalePid)
+		}
+		defer func() {
+			if err := pidFile.Close(); err != nil {
+				btcdLog.Errorf("Unable to remove pid file: %v", err)
+			}
+		}()
+	}
+
+	// Notify systemd of the state of the service when it started btcd as a
+	// service with Type=notify.  Failing to do so is not fatal since
+	// systemd is merely informed.
+	sdNotify, err := newSdNotifier()
+	if err != nil {
+		btcdLog.Warnf("Unable to connect to systemd: %v", err)
+	}
+	defer sdNotify.Close()
+
 	// Enable http profiling server if requested.
 	if cfg.Profile != "" {
 		go func() {
@@ -94,6 +124,23 @@ func btcdMain(serverChan chan<- *server) error {
 		return err
 	}
 
+	// Lock the data directory of the network to prevent multiple instances
+	// from operating on the same chain state.
+	dataDirLock, err := lockDataDir(cfg.DataDir)
+	if err != nil {
+		btcdLog.Errorf("%v", err)
+		return err
+	}
+	defer dataDirLock.Close()
+
+	// Move data which resides directly in the data directory into the
+	// subdirectory of the network now that no other instance for the
+	// network can use or migrate it.
+	if err := upgradeNetDataDir(); err != nil {
+		btcdLog.Errorf("%v", err)
+		return err
+	}
+
 	// Return now if an interrupt signal was triggered.
 	if interruptRequested(interrupt) {
 		return nil
@@ -174,11 +221,18 @@ func btcdMain(serverChan chan<- *server) error {
 	if serverChan != nil {
 		serverChan <- server
 	}
+	sdNotify.Ready("Running", func() {
+		// The sync manager processes all blocks and transactions
+		// received from peers, so the node is considered hung when it
+		// doesn't respond.
+		server.syncManager.IsCurrent()
+	})
 
 	// Wait until the interrupt signal is received from an OS signal or
 	// shutdown is requested through one of the subsystems such as the RPC
 	// server.
 	<-interrupt
+	sdNotify.Stopping("Shutting down")
 	return nil
 }
 
@@ -328,6 +382,19 @@ func init() {
 }
 
 func main() {
+	// Run the requested offline utility subcommand and exit without
+	// starting the node when the first argument selects one.
+	if len(os.Args) > 1 && os.Args[1] == utilCmdName {
+		appName := filepath.Base(os.Args[0])
+		appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+		err := runUtilCommand(appName, os.Args[2:], os.Stdin, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// If GOGC is not explicitly set, override GC percent.
 	if os.Getenv("GOGC") == "" {
 		// Block and transaction processing can cause bursty allocations.  This
@@ -44,6 +44,32 @@ func NewNodeCmd(subCmd NodeSubCmd, target string, connectSubCmd *string) *NodeCm
 	}
 }
 
+// BumpFeePSBTCmd defines the bumpfeepsbt JSON-RPC command.  This command is
+// not a standard Bitcoin command.  It is an extension for btcd.
+type BumpFeePSBTCmd struct {
+	Txid        string
+	Method      *string  `jsonrpcdefault:"\"rbf\"" jsonrpcusage:"\"rbf|cpfp\""`
+	FeeRate     *float64 `jsonrpcdefault:"0"`
+	OutputIndex *int
+}
+
+// NewBumpFeePSBTCmd returns a new instance which can be used to issue a
+// bumpfeepsbt JSON-RPC command.  This command is not a standard Bitcoin
+// command.  It is an extension for btcd.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewBumpFeePSBTCmd(txid string, method *string, feeRate *float64,
+	outputIndex *int) *BumpFeePSBTCmd {
+
+	return &BumpFeePSBTCmd{
+		Txid:        txid,
+		Method:      method,
+		FeeRate:     feeRate,
+		OutputIndex: outputIndex,
+	}
+}
+
 // DebugLevelCmd defines the debuglevel JSON-RPC command.  This command is not a
 // standard Bitcoin command.  It is an extension for btcd.
 type DebugLevelCmd struct {
@@ -107,6 +133,15 @@ func NewGetCurrentNetCmd() *GetCurrentNetCmd {
 	return &GetCurrentNetCmd{}
 }
 
+// GetDustLimitsCmd defines the getdustlimits JSON-RPC command.
+type GetDustLimitsCmd struct{}
+
+// NewGetDustLimitsCmd returns a new instance which can be used to issue a
+// getdustlimits JSON-RPC command.
+func NewGetDustLimitsCmd() *GetDustLimitsCmd {
+	return &GetDustLimitsCmd{}
+}
+
 // GetHeadersCmd defines the getheaders JSON-RPC command.
 //
 // NOTE: This is a btcsuite extension ported from
@@ -128,6 +163,19 @@ func NewGetHeadersCmd(blockLocators []string, hashStop string) *GetHeadersCmd {
 	}
 }
 
+// GetTxRejectionInfoCmd defines the gettxrejectioninfo JSON-RPC command.
+type GetTxRejectionInfoCmd struct {
+	Txid string
+}
+
+// NewGetTxRejectionInfoCmd returns a new instance which can be used to issue a
+// gettxrejectioninfo JSON-RPC command.
+func NewGetTxRejectionInfoCmd(txHash string) *GetTxRejectionInfoCmd {
+	return &GetTxRejectionInfoCmd{
+		Txid: txHash,
+	}
+}
+
 // VersionCmd defines the version JSON-RPC command.
 //
 // NOTE: This is a btcsuite extension ported from
@@ -145,12 +193,15 @@ func init() {
 	// No special flags for commands in this file.
 	flags := UsageFlag(0)
 
+	MustRegisterCmd("bumpfeepsbt", (*BumpFeePSBTCmd)(nil), flags)
 	MustRegisterCmd("debuglevel", (*DebugLevelCmd)(nil), flags)
 	MustRegisterCmd("node", (*NodeCmd)(nil), flags)
 	MustRegisterCmd("generate", (*GenerateCmd)(nil), flags)
 	MustRegisterCmd("generatetoaddress", (*GenerateToAddressCmd)(nil), flags)
 	MustRegisterCmd("getbestblock", (*GetBestBlockCmd)(nil), flags)
 	MustRegisterCmd("getcurrentnet", (*GetCurrentNetCmd)(nil), flags)
+	MustRegisterCmd("getdustlimits", (*GetDustLimitsCmd)(nil), flags)
 	MustRegisterCmd("getheaders", (*GetHeadersCmd)(nil), flags)
+	MustRegisterCmd("gettxrejectioninfo", (*GetTxRejectionInfoCmd)(nil), flags)
 	MustRegisterCmd("version", (*VersionCmd)(nil), flags)
 }
@@ -30,6 +30,39 @@ func TestBtcdExtCmds(t *testing.T) {
 		marshalled   string
 		unmarshalled interface{}
 	}{
+		{
+			name: "bumpfeepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("bumpfeepsbt", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewBumpFeePSBTCmd("123", nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"bumpfeepsbt","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.BumpFeePSBTCmd{
+				Txid:    "123",
+				Method:  btcjson.String("rbf"),
+				FeeRate: btcjson.Float64(0),
+			},
+		},
+		{
+			name: "bumpfeepsbt optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("bumpfeepsbt", "123", "cpfp", 0.0002, 1)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewBumpFeePSBTCmd("123",
+					btcjson.String("cpfp"), btcjson.Float64(0.0002),
+					btcjson.Int(1))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"bumpfeepsbt","params":["123","cpfp",0.0002,1],"id":1}`,
+			unmarshalled: &btcjson.BumpFeePSBTCmd{
+				Txid:        "123",
+				Method:      btcjson.String("cpfp"),
+				FeeRate:     btcjson.Float64(0.0002),
+				OutputIndex: btcjson.Int(1),
+			},
+		},
 		{
 			name: "debuglevel",
 			newCmd: func() (interface{}, error) {
@@ -154,6 +187,17 @@ func TestBtcdExtCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getcurrentnet","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetCurrentNetCmd{},
 		},
+		{
+			name: "getdustlimits",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdustlimits")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDustLimitsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdustlimits","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetDustLimitsCmd{},
+		},
 		{
 			name: "getheaders",
 			newCmd: func() (interface{}, error) {
@@ -194,6 +238,19 @@ func TestBtcdExtCmds(t *testing.T) {
 				HashStop: "000000000000000000ba33b33e1fad70b69e234fc24414dd47113bff38f523f7",
 			},
 		},
+		{
+			name: "gettxrejectioninfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("gettxrejectioninfo", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetTxRejectionInfoCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettxrejectioninfo","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.GetTxRejectionInfoCmd{
+				Txid: "123",
+			},
+		},
 		{
 			name: "version",
 			newCmd: func() (interface{}, error) {
@@ -18,3 +18,40 @@ type VersionResult struct {
 	Prerelease    string `json:"prerelease"`
 	BuildMetadata string `json:"buildmetadata"`
 }
+
+// DustLimitResult models the dust threshold of a single output type included
+// in the getdustlimits response.
+type DustLimitResult struct {
+	Type       string  `json:"type"`
+	OutputSize int64   `json:"outputsize"`
+	InputSize  int64   `json:"inputsize"`
+	Threshold  float64 `json:"threshold"`
+}
+
+// GetDustLimitsResult models the data returned from the getdustlimits command.
+type GetDustLimitsResult struct {
+	RelayFee      float64           `json:"relayfee"`
+	HardDustLimit float64           `json:"harddustlimit"`
+	SoftDustLimit float64           `json:"softdustlimit"`
+	Outputs       []DustLimitResult `json:"outputs"`
+}
+
+// BumpFeePSBTResult models the data returned from the bumpfeepsbt command.
+type BumpFeePSBTResult struct {
+	PSBT    string  `json:"psbt"`
+	OrigFee float64 `json:"origfee"`
+	Fee     float64 `json:"fee"`
+	VSize   int64   `json:"vsize"`
+	FeeRate float64 `json:"feerate"`
+}
+
+// GetTxRejectionInfoResult models a rejected transaction included in the
+// gettxrejectioninfo response.
+type GetTxRejectionInfoResult struct {
+	Txid         string `json:"txid"`
+	Hash         string `json:"hash"`
+	RejectCode   uint8  `json:"rejectcode"`
+	RejectReason string `json:"rejectreason"`
+	Time         int64  `json:"time"`
+	Height       int32  `json:"height"`
+}
@@ -216,18 +216,19 @@ type UnifiedSoftForks struct {
 // GetBlockChainInfoResult models the data returned from the getblockchaininfo
 // command.
 type GetBlockChainInfoResult struct {
-	Chain                string  `json:"chain"`
-	Blocks               int32   `json:"blocks"`
-	Headers              int32   `json:"headers"`
-	BestBlockHash        string  `json:"bestblockhash"`
-	Difficulty           float64 `json:"difficulty"`
-	MedianTime           int64   `json:"mediantime"`
-	VerificationProgress float64 `json:"verificationprogress,omitempty"`
-	InitialBlockDownload bool    `json:"initialblockdownload,omitempty"`
-	Pruned               bool    `json:"pruned"`
-	PruneHeight          int32   `json:"pruneheight,omitempty"`
-	ChainWork            string  `json:"chainwork,omitempty"`
-	SizeOnDisk           int64   `json:"size_on_disk,omitempty"`
+	Chain                string   `json:"chain"`
+	Blocks               int32    `json:"blocks"`
+	Headers              int32    `json:"headers"`
+	BestBlockHash        string   `json:"bestblockhash"`
+	Difficulty           float64  `json:"difficulty"`
+	MedianTime           int64    `json:"mediantime"`
+	VerificationProgress float64  `json:"verificationprogress,omitempty"`
+	InitialBlockDownload bool     `json:"initialblockdownload,omitempty"`
+	Pruned               bool     `json:"pruned"`
+	PruneHeight          int32    `json:"pruneheight,omitempty"`
+	ChainWork            string   `json:"chainwork,omitempty"`
+	SizeOnDisk           int64    `json:"size_on_disk,omitempty"`
+	ScriptFlags          []string `json:"scriptflags,omitempty"`
 	*SoftForks
 	*UnifiedSoftForks
 }
@@ -357,21 +358,23 @@ type LocalAddressesResult struct {
 // GetNetworkInfoResult models the data returned from the getnetworkinfo
 // command.
 type GetNetworkInfoResult struct {
-	Version         int32                  `json:"version"`
-	SubVersion      string                 `json:"subversion"`
-	ProtocolVersion int32                  `json:"protocolversion"`
-	LocalServices   string                 `json:"localservices"`
-	LocalRelay      bool                   `json:"localrelay"`
-	TimeOffset      int64                  `json:"timeoffset"`
-	Connections     int32                  `json:"connections"`
-	ConnectionsIn   int32                  `json:"connections_in"`
-	ConnectionsOut  int32                  `json:"connections_out"`
-	NetworkActive   bool                   `json:"networkactive"`
-	Networks        []NetworksResult       `json:"networks"`
-	RelayFee        float64                `json:"relayfee"`
-	IncrementalFee  float64                `json:"incrementalfee"`
-	LocalAddresses  []LocalAddressesResult `json:"localaddresses"`
-	Warnings        string                 `json:"warnings"`
+	Version                int32                  `json:"version"`
+	SubVersion             string                 `json:"subversion"`
+	ProtocolVersion        int32                  `json:"protocolversion"`
+	LocalServices          string                 `json:"localservices"`
+	LocalServicesNames     []string               `json:"localservicesnames"`
+	LocalTransportFeatures []string               `json:"localtransportfeatures"`
+	LocalRelay             bool                   `json:"localrelay"`
+	TimeOffset             int64                  `json:"timeoffset"`
+	Connections            int32                  `json:"connections"`
+	ConnectionsIn          int32                  `json:"connections_in"`
+	ConnectionsOut         int32                  `json:"connections_out"`
+	NetworkActive          bool                   `json:"networkactive"`
+	Networks               []NetworksResult       `json:"networks"`
+	RelayFee               float64                `json:"relayfee"`
+	IncrementalFee         float64                `json:"incrementalfee"`
+	LocalAddresses         []LocalAddressesResult `json:"localaddresses"`
+	Warnings               string                 `json:"warnings"`
 }
 
 // GetNodeAddressesResult models the data returned from the getnodeaddresses
@@ -845,3 +848,38 @@ type LoadWalletResult struct {
 type DumpWalletResult struct {
 	Filename string `json:"filename"`
 }
+
+// TestMempoolAcceptFees models the fees of a transaction included in the
+// testmempoolaccept response.
+type TestMempoolAcceptFees struct {
+	// Base is the transaction fee in BTC.
+	Base float64 `json:"base"`
+}
+
+// TestMempoolAcceptResult models the data from the testmempoolaccept command
+// for a single transaction.
+type TestMempoolAcceptResult struct {
+	// Txid is the transaction hash in hex.
+	Txid string `json:"txid"`
+
+	// Wtxid is the transaction witness hash in hex.
+	Wtxid string `json:"wtxid,omitempty"`
+
+	// PackageError is the package validation error, if any.
+	PackageError string `json:"package-error,omitempty"`
+
+	// Allowed specifies whether the transaction would be accepted to the
+	// mempool.
+	Allowed bool `json:"allowed"`
+
+	// Vsize is the virtual transaction size as defined in BIP 141.  It is
+	// only present when the transaction is allowed.
+	Vsize int32 `json:"vsize,omitempty"`
+
+	// Fees is only present when the transaction is allowed.
+	Fees *TestMempoolAcceptFees `json:"fees,omitempty"`
+
+	// RejectReason is the rejection string and only present when the
+	// transaction is not allowed.
+	RejectReason string `json:"reject-reason,omitempty"`
+}
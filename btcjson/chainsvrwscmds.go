@@ -32,6 +32,19 @@ func NewNotifyBlocksCmd() *NotifyBlocksCmd {
 	return &NotifyBlocksCmd{}
 }
 
+// NotifyBlocksFromCmd defines the notifyblocksfrom JSON-RPC command.
+type NotifyBlocksFromCmd struct {
+	Hash string
+}
+
+// NewNotifyBlocksFromCmd returns a new instance which can be used to issue a
+// notifyblocksfrom JSON-RPC command.
+func NewNotifyBlocksFromCmd(hash string) *NotifyBlocksFromCmd {
+	return &NotifyBlocksFromCmd{
+		Hash: hash,
+	}
+}
+
 // StopNotifyBlocksCmd defines the stopnotifyblocks JSON-RPC command.
 type StopNotifyBlocksCmd struct{}
 
@@ -177,6 +190,28 @@ func NewStopNotifySpentCmd(outPoints []OutPoint) *StopNotifySpentCmd {
 	}
 }
 
+// RegisterDescriptorCmd defines the registerdescriptor JSON-RPC command.
+//
+// NOTE: This is a btcd extension and requires a websocket connection.
+type RegisterDescriptorCmd struct {
+	Descriptor string
+	GapLimit   *uint32 `jsonrpcdefault:"20"`
+}
+
+// NewRegisterDescriptorCmd returns a new instance which can be used to issue a
+// registerdescriptor JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+//
+// NOTE: This is a btcd extension and requires a websocket connection.
+func NewRegisterDescriptorCmd(descriptor string, gapLimit *uint32) *RegisterDescriptorCmd {
+	return &RegisterDescriptorCmd{
+		Descriptor: descriptor,
+		GapLimit:   gapLimit,
+	}
+}
+
 // RescanCmd defines the rescan JSON-RPC command.
 //
 // Deprecated: Use RescanBlocksCmd instead.
@@ -228,9 +263,11 @@ func init() {
 	MustRegisterCmd("authenticate", (*AuthenticateCmd)(nil), flags)
 	MustRegisterCmd("loadtxfilter", (*LoadTxFilterCmd)(nil), flags)
 	MustRegisterCmd("notifyblocks", (*NotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("notifyblocksfrom", (*NotifyBlocksFromCmd)(nil), flags)
 	MustRegisterCmd("notifynewtransactions", (*NotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("notifyreceived", (*NotifyReceivedCmd)(nil), flags)
 	MustRegisterCmd("notifyspent", (*NotifySpentCmd)(nil), flags)
+	MustRegisterCmd("registerdescriptor", (*RegisterDescriptorCmd)(nil), flags)
 	MustRegisterCmd("session", (*SessionCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyblocks", (*StopNotifyBlocksCmd)(nil), flags)
 	MustRegisterCmd("stopnotifynewtransactions", (*StopNotifyNewTransactionsCmd)(nil), flags)
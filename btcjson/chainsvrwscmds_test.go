@@ -52,6 +52,19 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"notifyblocks","params":[],"id":1}`,
 			unmarshalled: &btcjson.NotifyBlocksCmd{},
 		},
+		{
+			name: "notifyblocksfrom",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("notifyblocksfrom", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewNotifyBlocksFromCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"notifyblocksfrom","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.NotifyBlocksFromCmd{
+				Hash: "123",
+			},
+		},
 		{
 			name: "stopnotifyblocks",
 			newCmd: func() (interface{}, error) {
@@ -154,6 +167,35 @@ func TestChainSvrWsCmds(t *testing.T) {
 				OutPoints: []btcjson.OutPoint{{Hash: "123", Index: 0}},
 			},
 		},
+		{
+			name: "registerdescriptor",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("registerdescriptor", "wpkh(xpub/0/*)")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewRegisterDescriptorCmd("wpkh(xpub/0/*)", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"registerdescriptor","params":["wpkh(xpub/0/*)"],"id":1}`,
+			unmarshalled: &btcjson.RegisterDescriptorCmd{
+				Descriptor: "wpkh(xpub/0/*)",
+				GapLimit:   btcjson.Uint32(20),
+			},
+		},
+		{
+			name: "registerdescriptor optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("registerdescriptor", "wpkh(xpub/0/*)", 100)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewRegisterDescriptorCmd("wpkh(xpub/0/*)",
+					btcjson.Uint32(100))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"registerdescriptor","params":["wpkh(xpub/0/*)",100],"id":1}`,
+			unmarshalled: &btcjson.RegisterDescriptorCmd{
+				Descriptor: "wpkh(xpub/0/*)",
+				GapLimit:   btcjson.Uint32(100),
+			},
+		},
 		{
 			name: "rescan",
 			newCmd: func() (interface{}, error) {
@@ -75,6 +75,21 @@ const (
 	// from the chain server that inform a client that a transaction that
 	// matches the loaded filter was accepted by the mempool.
 	RelevantTxAcceptedNtfnMethod = "relevanttxaccepted"
+
+	// TxExpiredNtfnMethod is the method used for notifications from the
+	// chain server that a transaction was evicted from the mempool since
+	// it expired.
+	//
+	// NOTE: This is a btcd extension.
+	TxExpiredNtfnMethod = "txexpired"
+
+	// DescriptorExtendedNtfnMethod is the method used for notifications
+	// from the chain server that the addresses monitored for a descriptor
+	// registered with registerdescriptor were extended since one of them
+	// was used.
+	//
+	// NOTE: This is a btcd extension.
+	DescriptorExtendedNtfnMethod = "descriptorextended"
 )
 
 // BlockConnectedNtfn defines the blockconnected JSON-RPC notification.
@@ -285,6 +300,44 @@ func NewRelevantTxAcceptedNtfn(txHex string) *RelevantTxAcceptedNtfn {
 	return &RelevantTxAcceptedNtfn{Transaction: txHex}
 }
 
+// TxExpiredNtfn defines the txexpired JSON-RPC notification.
+//
+// NOTE: This is a btcd extension.
+type TxExpiredNtfn struct {
+	TxID string
+}
+
+// NewTxExpiredNtfn returns a new instance which can be used to issue a
+// txexpired JSON-RPC notification.
+//
+// NOTE: This is a btcd extension.
+func NewTxExpiredNtfn(txHash string) *TxExpiredNtfn {
+	return &TxExpiredNtfn{
+		TxID: txHash,
+	}
+}
+
+// DescriptorExtendedNtfn defines the descriptorextended JSON-RPC notification.
+//
+// NOTE: This is a btcd extension.
+type DescriptorExtendedNtfn struct {
+	Descriptor string
+	NextIndex  uint32
+	Derived    uint32
+}
+
+// NewDescriptorExtendedNtfn returns a new instance which can be used to issue a
+// descriptorextended JSON-RPC notification.
+//
+// NOTE: This is a btcd extension.
+func NewDescriptorExtendedNtfn(descriptor string, nextIndex, derived uint32) *DescriptorExtendedNtfn {
+	return &DescriptorExtendedNtfn{
+		Descriptor: descriptor,
+		NextIndex:  nextIndex,
+		Derived:    derived,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable by websockets and are
 	// notifications.
@@ -301,4 +354,6 @@ func init() {
 	MustRegisterCmd(TxAcceptedNtfnMethod, (*TxAcceptedNtfn)(nil), flags)
 	MustRegisterCmd(TxAcceptedVerboseNtfnMethod, (*TxAcceptedVerboseNtfn)(nil), flags)
 	MustRegisterCmd(RelevantTxAcceptedNtfnMethod, (*RelevantTxAcceptedNtfn)(nil), flags)
+	MustRegisterCmd(TxExpiredNtfnMethod, (*TxExpiredNtfn)(nil), flags)
+	MustRegisterCmd(DescriptorExtendedNtfnMethod, (*DescriptorExtendedNtfn)(nil), flags)
 }
@@ -225,6 +225,34 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				Transaction: "001122",
 			},
 		},
+		{
+			name: "txexpired",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("txexpired", "123")
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewTxExpiredNtfn("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"txexpired","params":["123"],"id":null}`,
+			unmarshalled: &btcjson.TxExpiredNtfn{
+				TxID: "123",
+			},
+		},
+		{
+			name: "descriptorextended",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("descriptorextended", "wpkh(xpub/0/*)", 4, 24)
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewDescriptorExtendedNtfn("wpkh(xpub/0/*)", 4, 24)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"descriptorextended","params":["wpkh(xpub/0/*)",4,24],"id":null}`,
+			unmarshalled: &btcjson.DescriptorExtendedNtfn{
+				Descriptor: "wpkh(xpub/0/*)",
+				NextIndex:  4,
+				Derived:    24,
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
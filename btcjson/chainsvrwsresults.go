@@ -19,3 +19,14 @@ type RescannedBlock struct {
 	Hash         string   `json:"hash"`
 	Transactions []string `json:"transactions"`
 }
+
+// RegisterDescriptorResult models the data from the registerdescriptor
+// command.
+//
+// NOTE: This is a btcd extension.
+type RegisterDescriptorResult struct {
+	Descriptor string `json:"descriptor"`
+	GapLimit   uint32 `json:"gaplimit"`
+	NextIndex  uint32 `json:"nextindex"`
+	Derived    uint32 `json:"derived"`
+}
@@ -0,0 +1,402 @@
+// Copyright (c) 2015 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package btcjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// OpenRPCVersion is the version of the OpenRPC specification the documents
+// generated by this package conform to.
+const OpenRPCVersion = "1.2.6"
+
+// JSONSchema describes the shape of a JSON value using the subset of JSON
+// Schema that is needed to describe the parameters and results of the RPC
+// commands.  An empty schema matches any value.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	OneOf                []*JSONSchema          `json:"oneOf,omitempty"`
+}
+
+// ParamSchema describes a single positional parameter of an RPC method.
+type ParamSchema struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required"`
+	Schema      *JSONSchema `json:"schema"`
+}
+
+// ResultSchema describes the result of an RPC method.
+type ResultSchema struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Schema      *JSONSchema `json:"schema"`
+}
+
+// MethodSchema describes an RPC method in the format used by the methods of an
+// OpenRPC document.  The extension fields carry the usage flags the method was
+// registered with and the name of the Go type implementing the command so that
+// client bindings can be generated with idiomatic names.
+type MethodSchema struct {
+	Name           string         `json:"name"`
+	Summary        string         `json:"summary,omitempty"`
+	ParamStructure string         `json:"paramStructure"`
+	Params         []*ParamSchema `json:"params"`
+	Result         *ResultSchema  `json:"result"`
+	UsageFlags     []string       `json:"x-usage-flags,omitempty"`
+	GoCmdType      string         `json:"x-go-cmd-type"`
+}
+
+// OpenRPCInfo provides metadata about the API described by an OpenRPC
+// document.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCError describes an error code that may be returned by the API.
+type OpenRPCError struct {
+	Code    RPCErrorCode `json:"code"`
+	Message string       `json:"message"`
+}
+
+// OpenRPCComponents houses the reusable definitions of an OpenRPC document.
+type OpenRPCComponents struct {
+	Errors map[string]*OpenRPCError `json:"errors"`
+}
+
+// OpenRPCDocument is a machine-readable description of an RPC server which
+// conforms to the OpenRPC specification.
+type OpenRPCDocument struct {
+	OpenRPC    string            `json:"openrpc"`
+	Info       OpenRPCInfo       `json:"info"`
+	Methods    []*MethodSchema   `json:"methods"`
+	Components OpenRPCComponents `json:"components"`
+}
+
+// rpcErrorSchemas houses the names and descriptions of the application defined
+// error codes which are included in generated OpenRPC documents.  Several of
+// the command specific codes share their value with a more general error, so
+// the names are used as keys.
+var rpcErrorSchemas = []struct {
+	name    string
+	code    RPCErrorCode
+	message string
+}{
+	{"ErrRPCMisc", ErrRPCMisc, "Exception thrown during command handling"},
+	{"ErrRPCForbiddenBySafeMode", ErrRPCForbiddenBySafeMode, "Command not allowed in safe mode"},
+	{"ErrRPCType", ErrRPCType, "Unexpected type was passed as parameter"},
+	{"ErrRPCInvalidAddressOrKey", ErrRPCInvalidAddressOrKey, "Invalid address or key"},
+	{"ErrRPCOutOfMemory", ErrRPCOutOfMemory, "Ran out of memory during operation"},
+	{"ErrRPCInvalidParameter", ErrRPCInvalidParameter, "Invalid, missing or duplicate parameter"},
+	{"ErrRPCDatabase", ErrRPCDatabase, "Database error"},
+	{"ErrRPCDeserialization", ErrRPCDeserialization, "Error parsing or validating structure in raw format"},
+	{"ErrRPCVerify", ErrRPCVerify, "General error during transaction or block submission"},
+	{"ErrRPCVerifyRejected", ErrRPCVerifyRejected, "Transaction or block was rejected by network rules"},
+	{"ErrRPCVerifyAlreadyInChain", ErrRPCVerifyAlreadyInChain, "Transaction already in chain"},
+	{"ErrRPCInWarmup", ErrRPCInWarmup, "Client still warming up"},
+	{"ErrRPCMethodDeprecated", ErrRPCMethodDeprecated, "Method is deprecated"},
+	{"ErrRPCClientNotConnected", ErrRPCClientNotConnected, "Not connected to the network"},
+	{"ErrRPCClientInInitialDownload", ErrRPCClientInInitialDownload, "Still downloading initial blocks"},
+	{"ErrRPCClientNodeAlreadyAdded", ErrRPCClientNodeAlreadyAdded, "Node is already added"},
+	{"ErrRPCClientNodeNotAdded", ErrRPCClientNodeNotAdded, "Node has not been added before"},
+	{"ErrRPCClientNodeNotConnected", ErrRPCClientNodeNotConnected, "Node to disconnect not found in connected nodes"},
+	{"ErrRPCClientInvalidIPOrSubnet", ErrRPCClientInvalidIPOrSubnet, "Invalid IP/Subnet"},
+	{"ErrRPCClientP2PDisabled", ErrRPCClientP2PDisabled, "No valid connection manager instance found"},
+	{"ErrRPCClientMempoolDisabled", ErrRPCClientMempoolDisabled, "No mempool instance found"},
+	{"ErrRPCWallet", ErrRPCWallet, "Unspecified problem with wallet"},
+	{"ErrRPCWalletInsufficientFunds", ErrRPCWalletInsufficientFunds, "Not enough funds in wallet or account"},
+	{"ErrRPCWalletInvalidAccountName", ErrRPCWalletInvalidAccountName, "Invalid label name"},
+	{"ErrRPCWalletKeypoolRanOut", ErrRPCWalletKeypoolRanOut, "Keypool ran out, call keypoolrefill first"},
+	{"ErrRPCWalletUnlockNeeded", ErrRPCWalletUnlockNeeded, "Enter the wallet passphrase with walletpassphrase first"},
+	{"ErrRPCWalletPassphraseIncorrect", ErrRPCWalletPassphraseIncorrect, "The wallet passphrase entered was incorrect"},
+	{"ErrRPCWalletWrongEncState", ErrRPCWalletWrongEncState, "Command given in wrong wallet encryption state"},
+	{"ErrRPCWalletEncryptionFailed", ErrRPCWalletEncryptionFailed, "Failed to encrypt the wallet"},
+	{"ErrRPCWalletAlreadyUnlocked", ErrRPCWalletAlreadyUnlocked, "Wallet is already unlocked"},
+	{"ErrRPCWalletNotFound", ErrRPCWalletNotFound, "Invalid wallet specified"},
+	{"ErrRPCWalletNotSpecified", ErrRPCWalletNotSpecified, "No wallet specified"},
+	{"ErrRPCInvalidRequest", ErrRPCInvalidRequest.Code, ErrRPCInvalidRequest.Message},
+	{"ErrRPCMethodNotFound", ErrRPCMethodNotFound.Code, ErrRPCMethodNotFound.Message},
+	{"ErrRPCInvalidParams", ErrRPCInvalidParams.Code, ErrRPCInvalidParams.Message},
+	{"ErrRPCInternal", ErrRPCInternal.Code, ErrRPCInternal.Message},
+	{"ErrRPCParse", ErrRPCParse.Code, ErrRPCParse.Message},
+}
+
+// schemaBuilder houses the state needed while converting Go types to JSON
+// schemas.
+type schemaBuilder struct {
+	descs map[string]string
+
+	// inProgress tracks the struct types currently being converted in
+	// order to break cycles in self-referencing types.
+	inProgress map[reflect.Type]struct{}
+}
+
+// desc returns the description for the passed key or an empty string when
+// there is none.
+func (b *schemaBuilder) desc(key string) string {
+	return b.descs[key]
+}
+
+// typeSchema returns the JSON schema for the provided Go type.  Struct field
+// descriptions are looked up using the same "<typename>-<lowerfieldname>" keys
+// used when generating help.
+func (b *schemaBuilder) typeSchema(rt reflect.Type) *JSONSchema {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	// Raw JSON can hold anything.
+	if rt == reflect.TypeOf(json.RawMessage(nil)) {
+		return &JSONSchema{}
+	}
+
+	switch kind := rt.Kind(); {
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		return &JSONSchema{Type: "number"}
+
+	case isNumeric(kind):
+		return &JSONSchema{Type: "integer"}
+
+	case kind == reflect.String:
+		return &JSONSchema{Type: "string"}
+
+	case kind == reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+
+	case kind == reflect.Slice && rt.Elem().Kind() == reflect.Uint8:
+		// Byte slices are marshalled as base64 strings.
+		return &JSONSchema{Type: "string"}
+
+	case kind == reflect.Array || kind == reflect.Slice:
+		return &JSONSchema{Type: "array", Items: b.typeSchema(rt.Elem())}
+
+	case kind == reflect.Map:
+		return &JSONSchema{
+			Type:                 "object",
+			AdditionalProperties: b.typeSchema(rt.Elem()),
+		}
+
+	case kind == reflect.Struct:
+		if _, ok := b.inProgress[rt]; ok {
+			return &JSONSchema{Type: "object"}
+		}
+		b.inProgress[rt] = struct{}{}
+		schema := &JSONSchema{
+			Type:       "object",
+			Properties: make(map[string]*JSONSchema),
+		}
+		b.addStructFields(schema, rt)
+		delete(b.inProgress, rt)
+		return schema
+	}
+
+	return &JSONSchema{}
+}
+
+// addStructFields adds the fields of the passed struct type to the properties
+// of the provided object schema following the encoding/json marshalling rules.
+// Embedded structs without a JSON name have their fields promoted.
+func (b *schemaBuilder) addStructFields(schema *JSONSchema, rt reflect.Type) {
+	typeName := strings.ToLower(rt.Name())
+	for i := 0; i < rt.NumField(); i++ {
+		rtf := rt.Field(i)
+		if rtf.PkgPath != "" && !rtf.Anonymous {
+			continue
+		}
+
+		tag := rtf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		tagParts := strings.Split(tag, ",")
+		fieldName := tagParts[0]
+
+		fieldType := rtf.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if rtf.Anonymous && fieldName == "" &&
+			fieldType.Kind() == reflect.Struct {
+
+			b.addStructFields(schema, fieldType)
+			continue
+		}
+		if rtf.PkgPath != "" {
+			continue
+		}
+
+		// The field name is the json name when it's available,
+		// otherwise the field name as encoding/json would use it.  The
+		// description key always uses the lowercase version.
+		descName := fieldName
+		if fieldName == "" {
+			fieldName = rtf.Name
+			descName = strings.ToLower(rtf.Name)
+		}
+
+		fieldSchema := b.typeSchema(rtf.Type)
+		fieldSchema.Description = b.desc(typeName + "-" + descName)
+		schema.Properties[fieldName] = fieldSchema
+
+		omitEmpty := false
+		for _, opt := range tagParts[1:] {
+			if opt == "omitempty" {
+				omitEmpty = true
+			}
+		}
+		if !omitEmpty {
+			schema.Required = append(schema.Required, fieldName)
+		}
+	}
+}
+
+// validateResultTypes ensures each of the passed result types is a pointer to
+// a supported type or nil.
+func validateResultTypes(resultTypes []interface{}) error {
+	for i, resultType := range resultTypes {
+		if resultType == nil {
+			continue
+		}
+
+		rtp := reflect.TypeOf(resultType)
+		if rtp.Kind() != reflect.Ptr {
+			str := fmt.Sprintf("result #%d (%v) is not a pointer",
+				i, rtp.Kind())
+			return makeError(ErrInvalidType, str)
+		}
+
+		elemKind := rtp.Elem().Kind()
+		if !isValidResultType(elemKind) {
+			str := fmt.Sprintf("result #%d (%v) is not an allowed "+
+				"type", i, elemKind)
+			return makeError(ErrInvalidType, str)
+		}
+	}
+
+	return nil
+}
+
+// GenerateMethodSchema generates and returns a machine-readable description of
+// the parameters and results of the provided method.  The method must be
+// associated with a registered type.  The result types follow the same rules
+// as GenerateHelp, and when more than one is provided, the result schema
+// matches any one of them.
+//
+// The provided descriptions map is optional and uses the same keys as
+// GenerateHelp.  Unlike GenerateHelp, missing descriptions are simply omitted
+// from the schema.
+func GenerateMethodSchema(method string, descs map[string]string, resultTypes ...interface{}) (*MethodSchema, error) {
+	// Look up details about the provided method and error out if not
+	// registered.
+	registerLock.RLock()
+	rtp, ok := methodToConcreteType[method]
+	info := methodToInfo[method]
+	registerLock.RUnlock()
+	if !ok {
+		str := fmt.Sprintf("%q is not registered", method)
+		return nil, makeError(ErrUnregisteredMethod, str)
+	}
+	if err := validateResultTypes(resultTypes); err != nil {
+		return nil, err
+	}
+
+	b := &schemaBuilder{
+		descs:      descs,
+		inProgress: make(map[reflect.Type]struct{}),
+	}
+	schema := &MethodSchema{
+		Name:           method,
+		Summary:        b.desc(method + "--synopsis"),
+		ParamStructure: "by-position",
+		GoCmdType:      rtp.Elem().Name(),
+	}
+	if info.flags != 0 {
+		schema.UsageFlags = strings.Split(info.flags.String(), "|")
+	}
+
+	// Generate the schema for each parameter.  Optional parameters are
+	// pointers as enforced by RegisterCmd.
+	rt := rtp.Elem()
+	schema.Params = make([]*ParamSchema, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		rtf := rt.Field(i)
+		paramName := strings.ToLower(rtf.Name)
+		param := &ParamSchema{
+			Name:        paramName,
+			Description: b.desc(method + "-" + paramName),
+			Required:    rtf.Type.Kind() != reflect.Ptr,
+			Schema:      b.typeSchema(rtf.Type),
+		}
+		if defaultVal, ok := info.defaults[i]; ok {
+			param.Schema.Default = defaultVal.Elem().Interface()
+		}
+		schema.Params = append(schema.Params, param)
+	}
+
+	// Generate the schema for each of the result types.  A nil result type
+	// means the method does not return anything.
+	resultSchemas := make([]*JSONSchema, 0, len(resultTypes))
+	for i, resultType := range resultTypes {
+		var resultSchema *JSONSchema
+		if resultType == nil {
+			resultSchema = &JSONSchema{Type: "null"}
+		} else {
+			rtp := reflect.TypeOf(resultType)
+			resultSchema = b.typeSchema(rtp.Elem())
+		}
+		if desc := b.desc(fmt.Sprintf("%s--result%d", method, i)); desc != "" {
+			resultSchema.Description = desc
+		} else if len(resultTypes) > 1 {
+			condKey := fmt.Sprintf("%s--condition%d", method, i)
+			resultSchema.Description = b.desc(condKey)
+		}
+		resultSchemas = append(resultSchemas, resultSchema)
+	}
+	schema.Result = &ResultSchema{Name: method + "Result"}
+	switch len(resultSchemas) {
+	case 0:
+		schema.Result.Schema = &JSONSchema{Type: "null"}
+	case 1:
+		schema.Result.Schema = resultSchemas[0]
+	default:
+		schema.Result.Schema = &JSONSchema{OneOf: resultSchemas}
+	}
+
+	return schema, nil
+}
+
+// NewOpenRPCDocument returns an OpenRPC document describing the passed methods
+// along with all of the error codes defined by this package.  The methods are
+// sorted by name so the document is deterministic.
+func NewOpenRPCDocument(title, version string, methods []*MethodSchema) *OpenRPCDocument {
+	sorted := make([]*MethodSchema, len(methods))
+	copy(sorted, methods)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	errs := make(map[string]*OpenRPCError, len(rpcErrorSchemas))
+	for _, e := range rpcErrorSchemas {
+		errs[e.name] = &OpenRPCError{Code: e.code, Message: e.message}
+	}
+
+	return &OpenRPCDocument{
+		OpenRPC:    OpenRPCVersion,
+		Info:       OpenRPCInfo{Title: title, Version: version},
+		Methods:    sorted,
+		Components: OpenRPCComponents{Errors: errs},
+	}
+}
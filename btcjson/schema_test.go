@@ -0,0 +1,155 @@
+// Copyright (c) 2015 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package btcjson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/dogesuite/doged/btcjson"
+)
+
+// TestGenerateMethodSchema ensures the generated method schema describes the
+// parameters and results of a registered command.
+func TestGenerateMethodSchema(t *testing.T) {
+	t.Parallel()
+
+	descs := map[string]string{
+		"getblock--synopsis":                "Returns a block.",
+		"getblock-hash":                     "The block hash",
+		"getblock--condition0":              "verbosity=0",
+		"getblockheaderverboseresult-hash":  "The hash of the block",
+		"getblockheaderverboseresult-nonce": "The nonce",
+	}
+	schema, err := btcjson.GenerateMethodSchema("getblock", descs,
+		(*string)(nil), (*btcjson.GetBlockHeaderVerboseResult)(nil))
+	if err != nil {
+		t.Fatalf("GenerateMethodSchema: unexpected error: %v", err)
+	}
+
+	if schema.Summary != "Returns a block." {
+		t.Errorf("unexpected summary %q", schema.Summary)
+	}
+	if schema.GoCmdType != "GetBlockCmd" {
+		t.Errorf("unexpected go cmd type %q", schema.GoCmdType)
+	}
+
+	// The hash is required while the verbosity is optional with a default.
+	if len(schema.Params) != 2 {
+		t.Fatalf("unexpected number of params - got %d, want 2",
+			len(schema.Params))
+	}
+	hash, verbosity := schema.Params[0], schema.Params[1]
+	if hash.Name != "hash" || !hash.Required ||
+		hash.Schema.Type != "string" || hash.Description != "The block hash" {
+
+		t.Errorf("unexpected hash param %+v", hash)
+	}
+	if verbosity.Name != "verbosity" || verbosity.Required ||
+		verbosity.Schema.Type != "integer" ||
+		verbosity.Schema.Default != 1 {
+
+		t.Errorf("unexpected verbosity param %+v", verbosity)
+	}
+
+	// Multiple result types are described as alternatives.
+	oneOf := schema.Result.Schema.OneOf
+	if len(oneOf) != 2 {
+		t.Fatalf("unexpected number of results - got %d, want 2",
+			len(oneOf))
+	}
+	if oneOf[0].Type != "string" || oneOf[0].Description != "verbosity=0" {
+		t.Errorf("unexpected first result %+v", oneOf[0])
+	}
+	header := oneOf[1]
+	if header.Type != "object" {
+		t.Fatalf("unexpected second result type %q", header.Type)
+	}
+	if got := header.Properties["nonce"]; got == nil ||
+		got.Type != "integer" || got.Description != "The nonce" {
+
+		t.Errorf("unexpected nonce property %+v", got)
+	}
+	if got := header.Properties["difficulty"]; got == nil ||
+		got.Type != "number" {
+
+		t.Errorf("unexpected difficulty property %+v", got)
+	}
+	for _, name := range header.Required {
+		if name == "nextblockhash" {
+			t.Errorf("omitempty field %q reported as required", name)
+		}
+	}
+
+	// Ensure the schema can be marshalled.
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("unable to marshal schema: %v", err)
+	}
+}
+
+// TestGenerateMethodSchemaErrors ensures the schema generation returns the
+// expected errors for unregistered methods and invalid result types.
+func TestGenerateMethodSchemaErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		method      string
+		resultTypes []interface{}
+		err         btcjson.Error
+	}{
+		{
+			name:   "unregistered command",
+			method: "boguscommand",
+			err:    btcjson.Error{ErrorCode: btcjson.ErrUnregisteredMethod},
+		},
+		{
+			name:        "non-pointer result type",
+			method:      "help",
+			resultTypes: []interface{}{0},
+			err:         btcjson.Error{ErrorCode: btcjson.ErrInvalidType},
+		},
+	}
+
+	for i, test := range tests {
+		_, err := btcjson.GenerateMethodSchema(test.method, nil,
+			test.resultTypes...)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.err) {
+			t.Errorf("Test #%d (%s) wrong error - got %T (%v), "+
+				"want %T", i, test.name, err, err, test.err)
+			continue
+		}
+		gotErrorCode := err.(btcjson.Error).ErrorCode
+		if gotErrorCode != test.err.ErrorCode {
+			t.Errorf("Test #%d (%s) mismatched error code - got "+
+				"%v (%v), want %v", i, test.name, gotErrorCode,
+				err, test.err.ErrorCode)
+		}
+	}
+}
+
+// TestNewOpenRPCDocument ensures the OpenRPC document sorts the methods and
+// includes the error codes.
+func TestNewOpenRPCDocument(t *testing.T) {
+	t.Parallel()
+
+	methods := []*btcjson.MethodSchema{{Name: "stop"}, {Name: "getinfo"}}
+	doc := btcjson.NewOpenRPCDocument("btcd", "1.0.0", methods)
+	if doc.OpenRPC != btcjson.OpenRPCVersion {
+		t.Errorf("unexpected openrpc version %q", doc.OpenRPC)
+	}
+	if doc.Methods[0].Name != "getinfo" || doc.Methods[1].Name != "stop" {
+		t.Errorf("methods are not sorted: %v, %v", doc.Methods[0].Name,
+			doc.Methods[1].Name)
+	}
+	if methods[0].Name != "stop" {
+		t.Errorf("passed methods were modified")
+	}
+	e, ok := doc.Components.Errors["ErrRPCInvalidParameter"]
+	if !ok || e.Code != btcjson.ErrRPCInvalidParameter {
+		t.Errorf("unexpected invalid parameter error %+v", e)
+	}
+}
@@ -250,6 +250,26 @@ type Params struct {
 	// Mempool parameters
 	RelayNonStdTxs bool
 
+	// DustRelayFeeMultiplier is the multiple of the minimum relay fee the
+	// cost of spending an output is weighed against to derive the dust
+	// threshold of its output type.  Outputs whose value doesn't cover
+	// spending them at that fee rate are considered dust.
+	DustRelayFeeMultiplier int64
+
+	// HardDustLimit is the minimum value, in the smallest unit of the
+	// currency, a standard transaction output must carry regardless of the
+	// threshold derived from the relay fee.  A value of zero disables the
+	// limit.
+	HardDustLimit int64
+
+	// SoftDustLimit is the value, in the smallest unit of the currency,
+	// below which each output of a transaction requires an additional fee
+	// of the soft dust limit itself.  Dogecoin charges for such outputs
+	// rather than rejecting them since its fees are low relative to the
+	// value of the outputs being created.  A value of zero disables the
+	// additional fee.
+	SoftDustLimit int64
+
 	// Human-readable part for Bech32 encoded segwit addresses, as defined
 	// in BIP 173.
 	Bech32HRPSegwit string
@@ -389,7 +409,10 @@ var MainNetParams = Params{
 	},
 
 	// Mempool parameters
-	RelayNonStdTxs: false,
+	RelayNonStdTxs:         false,
+	DustRelayFeeMultiplier: 3,
+	HardDustLimit:          100000,  // 0.001 DOGE
+	SoftDustLimit:          1000000, // 0.01 DOGE
 
 	// Human-readable part for Bech32 encoded segwit addresses, as defined in
 	// BIP 173.
@@ -498,7 +521,10 @@ var RegressionNetParams = Params{
 	},
 
 	// Mempool parameters
-	RelayNonStdTxs: true,
+	RelayNonStdTxs:         true,
+	DustRelayFeeMultiplier: 3,
+	HardDustLimit:          0,
+	SoftDustLimit:          0,
 
 	// Human-readable part for Bech32 encoded segwit addresses, as defined in
 	// BIP 173.
@@ -625,7 +651,10 @@ var TestNet3Params = Params{
 	},
 
 	// Mempool parameters
-	RelayNonStdTxs: true,
+	RelayNonStdTxs:         true,
+	DustRelayFeeMultiplier: 3,
+	HardDustLimit:          100000,  // 0.001 DOGE
+	SoftDustLimit:          1000000, // 0.01 DOGE
 
 	// Human-readable part for Bech32 encoded segwit addresses, as defined in
 	// BIP 173.
@@ -738,7 +767,10 @@ var SimNetParams = Params{
 	},
 
 	// Mempool parameters
-	RelayNonStdTxs: true,
+	RelayNonStdTxs:         true,
+	DustRelayFeeMultiplier: 3,
+	HardDustLimit:          0,
+	SoftDustLimit:          0,
 
 	// Human-readable part for Bech32 encoded segwit addresses, as defined in
 	// BIP 173.
@@ -865,7 +897,10 @@ func CustomSignetParams(challenge []byte, dnsSeeds []DNSSeed) Params {
 		},
 
 		// Mempool parameters
-		RelayNonStdTxs: false,
+		RelayNonStdTxs:         false,
+		DustRelayFeeMultiplier: 3,
+		HardDustLimit:          0,
+		SoftDustLimit:          0,
 
 		// Human-readable part for Bech32 encoded segwit addresses, as defined in
 		// BIP 173.
@@ -0,0 +1,440 @@
+// Copyright (c) 2013-2014 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dogesuite/doged/btcjson"
+	flags "github.com/jessevdk/go-flags"
+)
+
+type config struct {
+	Output  string `short:"o" long:"output" description:"File to write the generated bindings to (default: stdout)"`
+	Package string `short:"p" long:"package" description:"Package name of the generated bindings"`
+}
+
+func main() {
+	cfg := config{
+		Package: "rpcbindings",
+	}
+	parser := flags.NewParser(&cfg, flags.Default)
+	parser.Usage = "[OPTIONS] [specfile]\n\nGenerates Go client " +
+		"bindings from the specification served by the RPC server " +
+		"at /spec.  The specification is read from stdin when no " +
+		"file is given."
+	args, err := parser.Parse()
+	if err != nil {
+		if e, ok := err.(*flags.Error); ok && e.Type == flags.ErrHelp {
+			return
+		}
+		parser.WriteHelp(os.Stderr)
+		os.Exit(1)
+	}
+	if len(args) > 1 {
+		parser.WriteHelp(os.Stderr)
+		os.Exit(1)
+	}
+
+	var in io.Reader = os.Stdin
+	if len(args) == 1 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open spec: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var doc btcjson.OpenRPCDocument
+	if err := json.NewDecoder(in).Decode(&doc); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot decode spec: %v\n", err)
+		os.Exit(1)
+	}
+
+	src, err := generate(&doc, cfg.Package)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot generate bindings: %v\n", err)
+		os.Exit(1)
+	}
+
+	if cfg.Output == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(cfg.Output, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot write bindings: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// header is the fixed portion of the generated source which precedes the
+// per-method bindings.
+const header = `// Code generated by genrpcbindings from the %s %s specification. DO NOT EDIT.
+
+package %s
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/dogesuite/doged/rpcclient"
+)
+
+// Client issues requests for every method described by the RPC server
+// specification through an rpcclient.Client.  Results are decoded into types
+// generated from the result schemas of the specification.  Results which can
+// take several shapes are returned as raw JSON.
+type Client struct {
+	client *rpcclient.Client
+}
+
+// New returns bindings which issue requests through the passed client.
+func New(client *rpcclient.Client) *Client {
+	return &Client{client: client}
+}
+
+// call marshals the passed positional parameters, issues a request for the
+// method and unmarshals the result into the passed value unless it is nil.
+// Trailing optional parameters which were not provided are omitted so the
+// server applies its defaults.
+func (c *Client) call(result interface{}, method string, params ...interface{}) error {
+	for len(params) > 0 {
+		last := params[len(params)-1]
+		if last != nil {
+			v := reflect.ValueOf(last)
+			if v.Kind() != reflect.Ptr || !v.IsNil() {
+				break
+			}
+		}
+		params = params[:len(params)-1]
+	}
+
+	rawParams := make([]json.RawMessage, 0, len(params))
+	for _, param := range params {
+		marshalled, err := json.Marshal(param)
+		if err != nil {
+			return err
+		}
+		rawParams = append(rawParams, marshalled)
+	}
+	rawResult, err := c.client.RawRequest(method, rawParams)
+	if err != nil || result == nil {
+		return err
+	}
+	return json.Unmarshal(rawResult, result)
+}
+`
+
+// generate returns the formatted Go source of the bindings for all methods in
+// the passed document which can be issued through HTTP POST requests.
+func generate(doc *btcjson.OpenRPCDocument, pkg string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, header, doc.Info.Title, doc.Info.Version, pkg)
+
+	funcNames := make(map[string]string)
+	types := &resultTypes{names: make(map[string]struct{})}
+	for _, method := range doc.Methods {
+		if !isHTTPMethod(method) {
+			continue
+		}
+
+		funcName := goFuncName(method)
+		if other, ok := funcNames[funcName]; ok {
+			return nil, fmt.Errorf("methods %q and %q both map to %s",
+				other, method.Name, funcName)
+		}
+		funcNames[funcName] = method.Name
+
+		writeMethod(&buf, types, funcName, method)
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// isHTTPMethod returns whether the method can be issued as a standalone
+// request.  Websocket-only methods and notifications are skipped since they
+// require dedicated handling by the client.
+func isHTTPMethod(method *btcjson.MethodSchema) bool {
+	for _, flag := range method.UsageFlags {
+		if flag == btcjson.UFWebsocketOnly.String() ||
+			flag == btcjson.UFNotification.String() {
+
+			return false
+		}
+	}
+	return true
+}
+
+// goFuncName returns the name of the binding for the method.  The name of the
+// Go command type is used when it is available since it has the conventional
+// capitalization.
+func goFuncName(method *btcjson.MethodSchema) string {
+	if name := strings.TrimSuffix(method.GoCmdType, "Cmd"); name != "" &&
+		token.IsIdentifier(name) {
+
+		return name
+	}
+	runes := []rune(method.Name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// goParamName returns a valid Go identifier for the parameter which does not
+// clash with keywords or the receiver.
+func goParamName(name string) string {
+	if !token.IsIdentifier(name) || token.IsKeyword(name) || name == "c" {
+		return name + "Arg"
+	}
+	return name
+}
+
+// goType returns the Go type used for a parameter with the passed schema.
+// Optional primitive parameters are pointers so they can be omitted.  Complex
+// parameters accept any value which marshals to the expected JSON.
+func goType(schema *btcjson.JSONSchema, required bool) string {
+	var typ string
+	switch schema.Type {
+	case "string":
+		typ = "string"
+	case "integer":
+		typ = "int64"
+	case "number":
+		typ = "float64"
+	case "boolean":
+		typ = "bool"
+	default:
+		return "interface{}"
+	}
+	if !required {
+		typ = "*" + typ
+	}
+	return typ
+}
+
+// resultTypes generates the Go types of method results.  Objects with known
+// properties are decoded into named struct types, which are declared in decls
+// in the order they are named.
+type resultTypes struct {
+	decls []string
+	names map[string]struct{}
+
+	// result is the name of the type declared for the result of the
+	// current method as opposed to a part of it.
+	result string
+}
+
+// goType returns the Go type of a result or a part of it with the passed
+// schema.  The passed name is used for the struct type declared for an object,
+// and as the prefix of the names of the struct types declared for its
+// properties.  Values with unknown or several shapes are kept as raw JSON.
+func (r *resultTypes) goType(schema *btcjson.JSONSchema, name, method string) string {
+	if schema == nil {
+		return "json.RawMessage"
+	}
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + r.goType(schema.Items, name, method)
+	case "object":
+		if len(schema.Properties) != 0 {
+			return r.declareStruct(schema, name, method)
+		}
+		if schema.AdditionalProperties != nil {
+			return "map[string]" + r.goType(
+				schema.AdditionalProperties, name, method)
+		}
+	}
+	return "json.RawMessage"
+}
+
+// declareStruct declares a struct type for an object with the passed schema
+// and returns its name, which is based on the passed one but unique among the
+// declared types.
+func (r *resultTypes) declareStruct(schema *btcjson.JSONSchema, name, method string) string {
+	typeName := name
+	for i := 2; ; i++ {
+		if _, ok := r.names[typeName]; !ok {
+			break
+		}
+		typeName = fmt.Sprintf("%s%d", name, i)
+	}
+	r.names[typeName] = struct{}{}
+
+	// Reserve the position of the declaration so it precedes the types
+	// declared for its properties.
+	pos := len(r.decls)
+	r.decls = append(r.decls, "")
+
+	props := make([]string, 0, len(schema.Properties))
+	for prop := range schema.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+	required := make(map[string]struct{}, len(schema.Required))
+	for _, prop := range schema.Required {
+		required[prop] = struct{}{}
+	}
+
+	var buf bytes.Buffer
+	if name == r.result {
+		fmt.Fprintf(&buf, "\n// %s describes the result of the %s RPC.\n",
+			typeName, method)
+	} else {
+		fmt.Fprintf(&buf, "\n// %s is part of the result of the %s "+
+			"RPC.\n", typeName, method)
+	}
+	if schema.Description != "" {
+		fmt.Fprintf(&buf, "//\n")
+		writeComment(&buf, schema.Description)
+	}
+	fmt.Fprintf(&buf, "type %s struct {\n", typeName)
+	fields := make(map[string]struct{}, len(props))
+	for _, prop := range props {
+		propSchema := schema.Properties[prop]
+		field := goFieldName(prop)
+		for i := 2; ; i++ {
+			if _, ok := fields[field]; !ok {
+				break
+			}
+			field = fmt.Sprintf("%s%d", goFieldName(prop), i)
+		}
+		fields[field] = struct{}{}
+
+		tag := prop
+		if _, ok := required[prop]; !ok {
+			tag += ",omitempty"
+		}
+		if propSchema.Description != "" {
+			writeComment(&buf, propSchema.Description)
+		}
+		fmt.Fprintf(&buf, "%s %s `json:%q`\n", field,
+			r.goType(propSchema, typeName+field, method), tag)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	r.decls[pos] = buf.String()
+	return typeName
+}
+
+// goFieldName returns an exported Go identifier for the passed JSON property
+// name.  Characters which are not valid in identifiers separate words, each
+// of which is capitalized.
+func goFieldName(prop string) string {
+	var name []rune
+	upper := true
+	for _, r := range prop {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name = append(name, r)
+	}
+	if len(name) == 0 || !unicode.IsLetter(name[0]) {
+		name = append([]rune("X"), name...)
+	}
+	return string(name)
+}
+
+// writeComment writes the passed text as a Go comment, one comment line per
+// line of text.
+func writeComment(w io.Writer, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(w, "// %s\n", strings.TrimRightFunc(line,
+			unicode.IsSpace))
+	}
+}
+
+// writeMethod writes the binding for the method to the passed writer followed
+// by the types declared for its result.
+func writeMethod(w io.Writer, types *resultTypes, funcName string, method *btcjson.MethodSchema) {
+	var resultSchema *btcjson.JSONSchema
+	if method.Result != nil {
+		resultSchema = method.Result.Schema
+	}
+	var resultType string
+	if resultSchema == nil || resultSchema.Type != "null" {
+		types.decls = types.decls[:0]
+		types.result = funcName + "Result"
+		resultType = types.goType(resultSchema, types.result,
+			method.Name)
+	}
+
+	fmt.Fprintf(w, "\n// %s issues the %s RPC.", funcName, method.Name)
+	if method.Summary != "" {
+		fmt.Fprintf(w, "\n//\n")
+		writeComment(w, method.Summary)
+	} else {
+		fmt.Fprintln(w)
+	}
+	if resultSchema != nil && len(resultSchema.OneOf) != 0 {
+		fmt.Fprintf(w, "//\n// The result is returned as raw JSON "+
+			"since it takes one of several shapes.\n")
+	}
+
+	params := make([]string, 0, len(method.Params))
+	args := make([]string, 0, len(method.Params)+1)
+	args = append(args, fmt.Sprintf("%q", method.Name))
+	for _, param := range method.Params {
+		name := goParamName(param.Name)
+		params = append(params, name+" "+goType(param.Schema,
+			param.Required))
+		args = append(args, name)
+	}
+
+	// Struct results are returned as pointers like the results of the
+	// methods of rpcclient.Client.
+	_, isStruct := types.names[resultType]
+	switch {
+	case resultType == "":
+		fmt.Fprintf(w, "func (c *Client) %s(%s) error {\n", funcName,
+			strings.Join(params, ", "))
+		fmt.Fprintf(w, "\treturn c.call(nil, %s)\n}\n",
+			strings.Join(args, ", "))
+
+	case isStruct:
+		fmt.Fprintf(w, "func (c *Client) %s(%s) (*%s, error) {\n",
+			funcName, strings.Join(params, ", "), resultType)
+		fmt.Fprintf(w, "\tresult := new(%s)\n", resultType)
+		fmt.Fprintf(w, "\tif err := c.call(result, %s); err != nil {\n",
+			strings.Join(args, ", "))
+		fmt.Fprintf(w, "\t\treturn nil, err\n\t}\n")
+		fmt.Fprintf(w, "\treturn result, nil\n}\n")
+
+	default:
+		fmt.Fprintf(w, "func (c *Client) %s(%s) (%s, error) {\n",
+			funcName, strings.Join(params, ", "), resultType)
+		fmt.Fprintf(w, "\tvar result %s\n", resultType)
+		fmt.Fprintf(w, "\terr := c.call(&result, %s)\n",
+			strings.Join(args, ", "))
+		fmt.Fprintf(w, "\treturn result, err\n}\n")
+	}
+
+	for _, decl := range types.decls {
+		io.WriteString(w, decl)
+	}
+}
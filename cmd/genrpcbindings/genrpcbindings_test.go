@@ -0,0 +1,288 @@
+// Copyright (c) 2013-2014 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dogesuite/doged/btcjson"
+	"github.com/dogesuite/doged/rpcclient"
+)
+
+// registeredSpec returns an OpenRPC document describing every command
+// registered with btcjson, which includes the commands served by the RPC
+// server along with websocket-only commands and notifications.  The schemas
+// are generated the same way the RPC server generates them for /spec.
+func registeredSpec(t *testing.T) *btcjson.OpenRPCDocument {
+	t.Helper()
+
+	methods := btcjson.RegisteredCmdMethods()
+	schemas := make([]*btcjson.MethodSchema, 0, len(methods))
+	for _, method := range methods {
+		schema, err := btcjson.GenerateMethodSchema(method, nil)
+		if err != nil {
+			t.Fatalf("unable to generate schema for %s: %v", method,
+				err)
+		}
+		schemas = append(schemas, schema)
+	}
+	return btcjson.NewOpenRPCDocument("btcd JSON-RPC API", "0.0.0",
+		schemas)
+}
+
+// bindingFuncs parses the passed bindings and returns their methods by name.
+func bindingFuncs(t *testing.T, src []byte) map[string]*ast.FuncDecl {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "bindings.go", src, 0)
+	if err != nil {
+		t.Fatalf("unable to parse bindings: %v", err)
+	}
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil {
+			funcs[fn.Name.Name] = fn
+		}
+	}
+	return funcs
+}
+
+// buildBindings ensures the passed bindings compile against the rpcclient
+// package of this module, so they are built in a package inside of it.
+func buildBindings(t *testing.T, src []byte) {
+	t.Helper()
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool is not available")
+	}
+	dir, err := ioutil.TempDir(".", "bindingstest")
+	if err != nil {
+		t.Fatalf("unable to create package directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "bindings.go"), src, 0644)
+	if err != nil {
+		t.Fatalf("unable to write bindings: %v", err)
+	}
+	cmd := exec.Command(goTool, "build", "./"+filepath.ToSlash(dir))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("bindings do not compile: %v\n%s", err, out)
+	}
+}
+
+// TestGenerate ensures the bindings generated for every registered command
+// compile and skip websocket-only commands and notifications.
+func TestGenerate(t *testing.T) {
+	doc := registeredSpec(t)
+	src, err := generate(doc, "rpcbindings")
+	if err != nil {
+		t.Fatalf("unable to generate bindings: %v", err)
+	}
+	funcs := bindingFuncs(t, src)
+
+	var numHTTP int
+	for _, method := range doc.Methods {
+		_, ok := funcs[goFuncName(method)]
+		if isHTTPMethod(method) {
+			numHTTP++
+			if !ok {
+				t.Errorf("missing binding for %s", method.Name)
+			}
+			continue
+		}
+		if ok {
+			t.Errorf("unexpected binding for %s with usage flags %v",
+				method.Name, method.UsageFlags)
+		}
+	}
+	for _, method := range []string{"notifyblocks", "blockconnected"} {
+		flags, err := btcjson.MethodUsageFlags(method)
+		if err != nil {
+			t.Fatalf("unable to get usage flags of %s: %v", method, err)
+		}
+		if flags&(btcjson.UFWebsocketOnly|btcjson.UFNotification) == 0 {
+			t.Fatalf("%s is neither websocket-only nor a notification",
+				method)
+		}
+	}
+	if numHTTP == 0 || numHTTP == len(doc.Methods) {
+		t.Fatalf("unexpected number of bindings %d for %d methods",
+			numHTTP, len(doc.Methods))
+	}
+
+	buildBindings(t, src)
+}
+
+// TestGenerateResults ensures the bindings decode results into types generated
+// from the result schemas.
+func TestGenerateResults(t *testing.T) {
+	resultTypes := map[string][]interface{}{
+		"getblockcount":     {(*int64)(nil)},
+		"getbestblockhash":  {(*string)(nil)},
+		"getblockchaininfo": {(*btcjson.GetBlockChainInfoResult)(nil)},
+		"getpeerinfo":       {(*[]btcjson.GetPeerInfoResult)(nil)},
+		"getrawmempool": {(*[]string)(nil),
+			(*btcjson.GetRawMempoolVerboseResult)(nil)},
+		"decoderawtransaction": {(*btcjson.TxRawDecodeResult)(nil)},
+		"ping":                 nil,
+	}
+	want := map[string]string{
+		"GetBlockCount":        "(int64, error)",
+		"GetBestBlockHash":     "(string, error)",
+		"GetBlockChainInfo":    "(*GetBlockChainInfoResult, error)",
+		"GetPeerInfo":          "([]GetPeerInfoResult, error)",
+		"GetRawMempool":        "(json.RawMessage, error)",
+		"DecodeRawTransaction": "(*DecodeRawTransactionResult, error)",
+		"Ping":                 "error",
+	}
+
+	schemas := make([]*btcjson.MethodSchema, 0, len(resultTypes))
+	for method, types := range resultTypes {
+		schema, err := btcjson.GenerateMethodSchema(method, nil,
+			types...)
+		if err != nil {
+			t.Fatalf("unable to generate schema for %s: %v", method,
+				err)
+		}
+		schemas = append(schemas, schema)
+	}
+	doc := btcjson.NewOpenRPCDocument("btcd JSON-RPC API", "0.0.0",
+		schemas)
+	src, err := generate(doc, "rpcbindings")
+	if err != nil {
+		t.Fatalf("unable to generate bindings: %v", err)
+	}
+
+	funcs := bindingFuncs(t, src)
+	for name, results := range want {
+		fn, ok := funcs[name]
+		if !ok {
+			t.Errorf("missing binding %s", name)
+			continue
+		}
+		var got string
+		switch r := fn.Type.Results.List; len(r) {
+		case 1:
+			got = exprString(r[0].Type)
+		case 2:
+			got = "(" + exprString(r[0].Type) + ", " +
+				exprString(r[1].Type) + ")"
+		}
+		if got != results {
+			t.Errorf("%s: unexpected results %s, want %s", name,
+				got, results)
+		}
+	}
+
+	// Nested objects are decoded into their own struct types.
+	for _, decl := range []string{
+		"type GetBlockChainInfoResultSoftforks struct",
+		"type DecodeRawTransactionResultVout struct",
+		"type DecodeRawTransactionResultVoutScriptPubKey struct",
+		"map[string]GetBlockChainInfoResultBip9Softforks",
+		"`json:\"bip9_softforks\"`",
+	} {
+		if !strings.Contains(string(src), decl) {
+			t.Errorf("bindings do not contain %q", decl)
+		}
+	}
+
+	buildBindings(t, src)
+}
+
+// exprString returns the source of the passed type expression.
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return "*" + exprString(e.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(e.Elt)
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	}
+	return "?"
+}
+
+// TestRPCClientMethods ensures the generated bindings cover the requests
+// rpcclient.Client issues.  Every method of rpcclient.Client with an Async
+// counterpart issues a request for the method named like it, or for the one
+// whose name it extends with the variant it calls, such as GetBlockVerbose.
+// The bindings must include that method unless it is websocket-only.
+func TestRPCClientMethods(t *testing.T) {
+	doc := registeredSpec(t)
+	src, err := generate(doc, "rpcbindings")
+	if err != nil {
+		t.Fatalf("unable to generate bindings: %v", err)
+	}
+	funcs := bindingFuncs(t, src)
+
+	methods := make(map[string]*btcjson.MethodSchema, len(doc.Methods))
+	for _, method := range doc.Methods {
+		methods[goFuncName(method)] = method
+	}
+
+	clientType := reflect.TypeOf((*rpcclient.Client)(nil))
+	var numChecked int
+	for i := 0; i < clientType.NumMethod(); i++ {
+		name := strings.TrimSuffix(clientType.Method(i).Name, "Async")
+		if name == clientType.Method(i).Name || name == "RawRequest" {
+			continue
+		}
+		if _, ok := clientType.MethodByName(name); !ok {
+			continue
+		}
+
+		var funcName string
+		for candidate := range methods {
+			if strings.HasPrefix(name, candidate) &&
+				len(candidate) > len(funcName) {
+
+				funcName = candidate
+			}
+		}
+		if funcName == "" {
+			t.Errorf("rpcclient.Client.%s issues an unknown request",
+				name)
+			continue
+		}
+		_, ok := funcs[funcName]
+		if isHTTP := isHTTPMethod(methods[funcName]); ok != isHTTP {
+			t.Errorf("rpcclient.Client.%s: binding %s exists %v, "+
+				"want %v", name, funcName, ok, isHTTP)
+		}
+		numChecked++
+	}
+	if numChecked == 0 {
+		t.Fatal("no rpcclient methods checked")
+	}
+}
+
+// TestGenerateDuplicateNames ensures methods which map to the same binding name
+// are rejected.
+func TestGenerateDuplicateNames(t *testing.T) {
+	doc := &btcjson.OpenRPCDocument{
+		Methods: []*btcjson.MethodSchema{
+			{Name: "getfoo", GoCmdType: "GetFooCmd"},
+			{Name: "GetFoo"},
+		},
+	}
+	_, err := generate(doc, "rpcbindings")
+	if err == nil || !strings.Contains(err.Error(), "GetFoo") {
+		t.Fatalf("unexpected error for duplicate names: %v", err)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	_ "github.com/dogesuite/doged/database/ffldb"
 	"github.com/dogesuite/doged/mempool"
 	"github.com/dogesuite/doged/peer"
+	"github.com/dogesuite/doged/txscript"
 	"github.com/dogesuite/doged/wire"
 	"github.com/dogesuite/doged/btcutil"
 	"github.com/btcsuite/go-socks/socks"
@@ -62,6 +63,7 @@ const (
 	defaultGenerate              = false
 	defaultMaxOrphanTransactions = 100
 	defaultMaxOrphanTxSize       = 100000
+	defaultMempoolExpiry         = mempool.DefaultMaxTxAge
 	defaultSigCacheMaxSize       = 100000
 	sampleConfigFilename         = "sample-btcd.conf"
 	defaultTxIndex               = false
@@ -114,18 +116,26 @@ type config struct {
 	DataDir              string        `short:"b" long:"datadir" description:"Directory to store data"`
 	DbType               string        `long:"dbtype" description:"Database backend to use for the Block Chain"`
 	DebugLevel           string        `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
+	DiffNode             string        `long:"diffnode" description:"Mirror the outcome of every transaction processed by the mempool to the testmempoolaccept RPC of the reference node at this host:port and log where the two disagree"`
+	DiffNodeCert         string        `long:"diffnodecert" description:"File containing the certificate of the reference node RPC server -- Enables TLS for the diffnode connection"`
+	DiffNodePass         string        `long:"diffnodepass" default-mask:"-" description:"Password for the RPC server of the reference node"`
+	DiffNodeUser         string        `long:"diffnodeuser" description:"Username for the RPC server of the reference node"`
 	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
 	DropCfIndex          bool          `long:"dropcfindex" description:"Deletes the index used for committed filtering (CF) support from the database on start up and then exits."`
 	DropTxIndex          bool          `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits."`
+	Explorer             bool          `long:"explorer" description:"Serve a lightweight block explorer web UI at /explorer/ on the RPC listeners to users with the rpcuser/rpcpass credentials"`
 	ExternalIPs          []string      `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers"`
 	Generate             bool          `long:"generate" description:"Generate (mine) bitcoins using the CPU"`
+	IncrementalRelayFee  float64       `long:"incrementalrelayfee" description:"The minimum fee rate increase in BTC/kB for replacing mempool transactions"`
 	FreeTxRelayLimit     float64       `long:"limitfreerelay" description:"Limit relay of transactions with no transaction fee to the given amount in thousands of bytes per minute"`
 	Listeners            []string      `long:"listen" description:"Add an interface/port to listen for connections (default all interfaces port: 8333, testnet: 18333)"`
 	LogDir               string        `long:"logdir" description:"Directory to log output."`
 	MaxOrphanTxs         int           `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
+	MempoolExpiry        time.Duration `long:"mempoolexpiry" description:"Evict transactions and their descendants from the memory pool if they have not been mined within this amount of time (0 to disable)"`
 	MaxPeers             int           `long:"maxpeers" description:"Max number of inbound and outbound peers"`
 	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set"`
 	MinRelayTxFee        float64       `long:"minrelaytxfee" description:"The minimum transaction fee in BTC/kB to be considered a non-zero fee."`
+	DisableAddrServe     bool          `long:"noaddrserve" description:"Do not respond to getaddr requests from peers -- NOTE: Address serving is automatically disabled if listening is disabled"`
 	DisableBanning       bool          `long:"nobanning" description:"Disable banning of misbehaving peers"`
 	NoCFilters           bool          `long:"nocfilters" description:"Disable committed filtering (CF) support"`
 	DisableCheckpoints   bool          `long:"nocheckpoints" description:"Disable built-in checkpoints.  Don't do this unless you know what you're doing."`
@@ -141,6 +151,7 @@ type config struct {
 	OnionProxy           string        `long:"onion" description:"Connect to tor hidden services via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	OnionProxyPass       string        `long:"onionpass" default-mask:"-" description:"Password for onion proxy server"`
 	OnionProxyUser       string        `long:"onionuser" description:"Username for onion proxy server"`
+	PidFile              string        `long:"pidfile" description:"Write the process id to the specified file while running -- NOTE: A file left behind by a process which did not exit cleanly is replaced"`
 	Profile              string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	Proxy                string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyPass            string        `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
@@ -148,6 +159,7 @@ type config struct {
 	RegressionTest       bool          `long:"regtest" description:"Use the regression test network"`
 	RejectNonStd         bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
 	RejectReplacement    bool          `long:"rejectreplacement" description:"Reject transactions that attempt to replace existing transactions within the mempool through the Replace-By-Fee (RBF) signaling policy."`
+	RelayAnnex           bool          `long:"relayannex" description:"Relay transactions whose taproot inputs carry an annex, which is reserved for future upgrades and non-standard by default"`
 	RelayNonStd          bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RPCCert              string        `long:"rpccert" description:"File containing the certificate file"`
 	RPCKey               string        `long:"rpckey" description:"File containing the certificate key"`
@@ -160,6 +172,7 @@ type config struct {
 	RPCQuirks            bool          `long:"rpcquirks" description:"Mirror some JSON-RPC quirks of Bitcoin Core -- NOTE: Discouraged unless interoperability issues need to be worked around"`
 	RPCPass              string        `short:"P" long:"rpcpass" default-mask:"-" description:"Password for RPC connections"`
 	RPCUser              string        `short:"u" long:"rpcuser" description:"Username for RPC connections"`
+	ScriptFlags          []string      `long:"scriptflags" description:"Force a consensus script verification flag on (+FLAG) or off (-FLAG), eg. +CLEANSTACK or -TAPROOT -- Only allowed on regtest and simnet; can be specified multiple times"`
 	SigCacheMaxSize      uint          `long:"sigcachemaxsize" description:"The maximum number of entries in the signature verification cache"`
 	SimNet               bool          `long:"simnet" description:"Use the simulation test network"`
 	SigNet               bool          `long:"signet" description:"Use the signet test network"`
@@ -178,7 +191,9 @@ type config struct {
 	dial                 func(string, string, time.Duration) (net.Conn, error)
 	addCheckpoints       []chaincfg.Checkpoint
 	miningAddrs          []btcutil.Address
+	incrementalRelayFee  btcutil.Amount
 	minRelayTxFee        btcutil.Amount
+	scriptFlagOverrides  blockchain.ScriptFlagOverrides
 	whitelists           []*net.IPNet
 }
 
@@ -379,6 +394,32 @@ func parseCheckpoints(checkpointStrings []string) ([]chaincfg.Checkpoint, error)
 	return checkpoints, nil
 }
 
+// parseScriptFlagOverrides parses script flag overrides in the '+FLAG' and
+// '-FLAG' formats, where FLAG is the name of a script verification flag.  A
+// name without a sign enables the flag.  An error is returned when the
+// overrides result in an invalid combination of script flags.
+func parseScriptFlagOverrides(overrides []string) (blockchain.ScriptFlagOverrides, error) {
+	var result blockchain.ScriptFlagOverrides
+	for _, override := range overrides {
+		name := strings.TrimLeft(override, "+-")
+		flag, ok := txscript.ScriptFlagFromName(name)
+		if !ok || len(override)-len(name) > 1 {
+			return result, fmt.Errorf("unknown script flag %q", override)
+		}
+		if strings.HasPrefix(override, "-") {
+			result.Disable |= flag
+			result.Enable &^= flag
+		} else {
+			result.Enable |= flag
+			result.Disable &^= flag
+		}
+	}
+	if err := result.Validate(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
 // filesExists reports whether the named file or directory exists.
 func fileExists(name string) bool {
 	if _, err := os.Stat(name); err != nil {
@@ -427,6 +468,7 @@ func loadConfig() (*config, []string, error) {
 		RPCKey:               defaultRPCKeyFile,
 		RPCCert:              defaultRPCCertFile,
 		MinRelayTxFee:        mempool.DefaultMinRelayTxFee.ToBTC(),
+		IncrementalRelayFee:  mempool.DefaultIncrementalRelayFee.ToBTC(),
 		FreeTxRelayLimit:     defaultFreeTxRelayLimit,
 		TrickleInterval:      defaultTrickleInterval,
 		BlockMinSize:         defaultBlockMinSize,
@@ -435,6 +477,7 @@ func loadConfig() (*config, []string, error) {
 		BlockMaxWeight:       defaultBlockMaxWeight,
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MempoolExpiry:        defaultMempoolExpiry,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
 		Generate:             defaultGenerate,
 		TxIndex:              defaultTxIndex,
@@ -649,6 +692,10 @@ func loadConfig() (*config, []string, error) {
 	cfg.LogDir = cleanAndExpandPath(cfg.LogDir)
 	cfg.LogDir = filepath.Join(cfg.LogDir, netName(activeNetParams))
 
+	if cfg.PidFile != "" {
+		cfg.PidFile = cleanAndExpandPath(cfg.PidFile)
+	}
+
 	// Special show command to list supported subsystems and exit.
 	if cfg.DebugLevel == "show" {
 		fmt.Println("Supported subsystems", supportedSubsystems())
@@ -677,6 +724,15 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Ensure the data directory does not hold the chain state of another
+	// network.
+	err = checkDataDirNetwork(cfg.DataDir, cfg.DbType, activeNetParams.Net)
+	if err != nil {
+		err := fmt.Errorf("%s: %v", funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
 	// Validate profile port number
 	if cfg.Profile != "" {
 		profilePort, err := strconv.Atoi(cfg.Profile)
@@ -746,6 +802,12 @@ func loadConfig() (*config, []string, error) {
 		cfg.DisableListen = true
 	}
 
+	// Nodes which don't accept incoming connections don't reveal their
+	// address table by serving getaddr requests.
+	if cfg.DisableListen {
+		cfg.DisableAddrServe = true
+	}
+
 	// Connect means no DNS seeding.
 	if len(cfg.ConnectPeers) > 0 {
 		cfg.DisableDNSSeed = true
@@ -790,6 +852,42 @@ func loadConfig() (*config, []string, error) {
 		btcdLog.Infof("RPC service is disabled")
 	}
 
+	// The block explorer is served by the RPC server.
+	if cfg.Explorer && (cfg.DisableRPC || cfg.RPCUser == "" ||
+		cfg.RPCPass == "") {
+
+		str := "%s: The explorer option requires the RPC server to be " +
+			"enabled with --rpcuser and --rpcpass"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The differential transaction acceptance tester requires an explicit
+	// port since the RPC ports of the reference implementation differ.
+	if cfg.DiffNode != "" {
+		if _, _, err := net.SplitHostPort(cfg.DiffNode); err != nil {
+			str := "%s: The diffnode option must be of the form " +
+				"host:port: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.DiffNodeUser == "" || cfg.DiffNodePass == "" {
+			str := "%s: The diffnode option requires --diffnodeuser " +
+				"and --diffnodepass"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.DiffNodeCert != "" {
+			cfg.DiffNodeCert = cleanAndExpandPath(cfg.DiffNodeCert)
+		}
+	}
+
 	// Default RPC to listen on localhost only.
 	if !cfg.DisableRPC && len(cfg.RPCListeners) == 0 {
 		addrs, err := net.LookupHost("localhost")
@@ -822,6 +920,16 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Validate the incrementalrelayfee.
+	cfg.incrementalRelayFee, err = btcutil.NewAmount(cfg.IncrementalRelayFee)
+	if err != nil {
+		str := "%s: invalid incrementalrelayfee: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the max block size to a sane value.
 	if cfg.BlockMaxSize < blockMaxSizeMin || cfg.BlockMaxSize >
 		blockMaxSizeMax {
@@ -858,6 +966,16 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The mempool expiry may not be negative.
+	if cfg.MempoolExpiry < 0 {
+		str := "%s: The mempoolexpiry option may not be less than 0 " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.MempoolExpiry)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the block priority and minimum block sizes to max block size.
 	cfg.BlockPrioritySize = minUint32(cfg.BlockPrioritySize, cfg.BlockMaxSize)
 	cfg.BlockMinSize = minUint32(cfg.BlockMinSize, cfg.BlockMaxSize)
@@ -1022,6 +1140,26 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Script flag overrides change the consensus rules, so they are only
+	// allowed on the test networks which don't need to be in agreement
+	// with other nodes.
+	if len(cfg.ScriptFlags) > 0 && !(cfg.RegressionTest || cfg.SimNet) {
+		str := "%s: The scriptflags option is only allowed on the " +
+			"regression and simulation test networks"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	cfg.scriptFlagOverrides, err = parseScriptFlagOverrides(cfg.ScriptFlags)
+	if err != nil {
+		str := "%s: Error parsing script flags: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Tor stream isolation requires either proxy or onion proxy to be set.
 	if cfg.TorIsolation && cfg.Proxy == "" && cfg.OnionProxy == "" {
 		str := "%s: Tor stream isolation requires either proxy or " +
@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"runtime"
 	"testing"
+
+	"github.com/dogesuite/doged/txscript"
 )
 
 var (
@@ -70,3 +72,49 @@ func TestCreateDefaultConfigFile(t *testing.T) {
 		t.Error("Could not find rpcpass in generated default config file.")
 	}
 }
+
+// TestParseScriptFlagOverrides ensures script flag overrides are parsed into
+// the expected flags and invalid overrides are rejected.
+func TestParseScriptFlagOverrides(t *testing.T) {
+	overrides, err := parseScriptFlagOverrides([]string{"+CLEANSTACK",
+		"-taproot", "NULLDUMMY", "-NULLDUMMY", "-P2SH", "+P2SH"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantEnable := txscript.ScriptVerifyCleanStack | txscript.ScriptBip16
+	wantDisable := txscript.ScriptVerifyTaproot | txscript.ScriptStrictMultiSig
+	if overrides.Enable != wantEnable || overrides.Disable != wantDisable {
+		t.Fatalf("unexpected overrides - got +%v -%v, want +%v -%v",
+			overrides.Enable.Names(), overrides.Disable.Names(),
+			wantEnable.Names(), wantDisable.Names())
+	}
+
+	for _, override := range []string{"+BOGUS", "--TAPROOT", "+-P2SH", ""} {
+		if _, err := parseScriptFlagOverrides([]string{override}); err == nil {
+			t.Errorf("override %q: expected error", override)
+		}
+	}
+
+	// Overrides resulting in flag combinations the script engine refuses
+	// to verify scripts with are rejected.
+	invalid := [][]string{
+		{"-P2SH"},
+		{"-P2SH", "+CLEANSTACK"},
+		{"-P2SH", "+CLEANSTACK", "-WITNESS"},
+	}
+	for _, overrides := range invalid {
+		if _, err := parseScriptFlagOverrides(overrides); err == nil {
+			t.Errorf("overrides %q: expected error", overrides)
+		}
+	}
+	valid := [][]string{
+		{"+WITNESS"},
+		{"+CLEANSTACK"},
+		{"-P2SH", "-WITNESS", "-CLEANSTACK"},
+	}
+	for _, overrides := range valid {
+		if _, err := parseScriptFlagOverrides(overrides); err != nil {
+			t.Errorf("overrides %q: unexpected error: %v", overrides, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dogesuite/doged/wire"
+)
+
+const (
+	// dataDirLockFilename is the name of the file in the data directory of
+	// a network which is locked for as long as a process uses the data
+	// directory.
+	dataDirLockFilename = ".lock"
+
+	// ffldbFirstBlockFilename is the name of the first flat file the ffldb
+	// backend stores blocks in.  Every block record in the file is
+	// prefixed with the network the database was created for.
+	ffldbFirstBlockFilename = "000000000.fdb"
+)
+
+// lockDataDir acquires an exclusive lock on the passed data directory, creating
+// it when needed, so that no other process can use the same data directory at
+// the same time.  The lock is held until the returned file is closed or the
+// process exits.
+func lockDataDir(dataDir string) (*os.File, error) {
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, err
+	}
+
+	lockPath := filepath.Join(dataDir, dataDirLockFilename)
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to lock data directory %s: %v -- "+
+			"is another instance already running for this network?",
+			dataDir, err)
+	}
+
+	// Record the process which holds the lock to help operators identify
+	// it.  Failing to do so is not fatal since the lock itself is what
+	// prevents concurrent use.
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return f, nil
+}
+
+// blockDbNetwork returns the network the block database at the passed path was
+// created for.  The network can only be determined for ffldb databases which
+// contain at least one block, so false is returned for all others.
+func blockDbNetwork(dbPath string) (wire.BitcoinNet, bool) {
+	f, err := os.Open(filepath.Join(dbPath, ffldbFirstBlockFilename))
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	var serializedNet [4]byte
+	if _, err := io.ReadFull(f, serializedNet[:]); err != nil {
+		return 0, false
+	}
+	return wire.BitcoinNet(binary.LittleEndian.Uint32(serializedNet[:])), true
+}
+
+// checkDataDirNetwork returns an error when the block database with the passed
+// type in the data directory was created for a network other than the passed
+// one.  This prevents a chain state from being shared by multiple networks due
+// to data directories being copied or configured by hand.
+func checkDataDirNetwork(dataDir, dbType string, net wire.BitcoinNet) error {
+	dbPath := filepath.Join(dataDir, blockDbNamePrefix+"_"+dbType)
+	dbNet, ok := blockDbNetwork(dbPath)
+	if !ok || dbNet == net {
+		return nil
+	}
+	return fmt.Errorf("the block database in %s belongs to the %v network "+
+		"and can not be used for %v -- each network requires its own "+
+		"data directory", dbPath, dbNet, net)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/dogesuite/doged/wire"
+)
+
+// TestLockDataDir ensures a data directory can only be locked once at a time.
+func TestLockDataDir(t *testing.T) {
+	switch runtime.GOOS {
+	case "darwin", "dragonfly", "freebsd", "linux", "netbsd", "openbsd",
+		"windows":
+	default:
+		t.Skip("file locking is not supported")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "btcd")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dataDir := filepath.Join(tmpDir, "mainnet")
+	lock, err := lockDataDir(dataDir)
+	if err != nil {
+		t.Fatalf("Unable to lock data directory: %v", err)
+	}
+	if _, err := lockDataDir(dataDir); err == nil {
+		t.Fatal("Locked data directory which is already locked")
+	}
+
+	// The data directory can be locked again once released.
+	lock.Close()
+	lock, err = lockDataDir(dataDir)
+	if err != nil {
+		t.Fatalf("Unable to lock released data directory: %v", err)
+	}
+	lock.Close()
+}
+
+// TestCheckDataDirNetwork ensures a data directory containing the block
+// database of another network is rejected.
+func TestCheckDataDirNetwork(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "btcd")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A data directory without a block database is always accepted.
+	err = checkDataDirNetwork(tmpDir, "ffldb", wire.MainNet)
+	if err != nil {
+		t.Fatalf("Unexpected error for empty data directory: %v", err)
+	}
+
+	// Create a block file for testnet.
+	dbPath := filepath.Join(tmpDir, blockDbNamePrefix+"_ffldb")
+	if err := os.MkdirAll(dbPath, 0700); err != nil {
+		t.Fatalf("Failed creating database directory: %v", err)
+	}
+	var record [8]byte
+	binary.LittleEndian.PutUint32(record[:], uint32(wire.TestNet3))
+	err = ioutil.WriteFile(filepath.Join(dbPath, ffldbFirstBlockFilename),
+		record[:], 0600)
+	if err != nil {
+		t.Fatalf("Failed writing block file: %v", err)
+	}
+
+	err = checkDataDirNetwork(tmpDir, "ffldb", wire.TestNet3)
+	if err != nil {
+		t.Fatalf("Unexpected error for matching network: %v", err)
+	}
+	err = checkDataDirNetwork(tmpDir, "ffldb", wire.MainNet)
+	if err == nil {
+		t.Fatal("Accepted data directory of another network")
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dogesuite/doged/btcec/v2/schnorr"
+	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/btcutil/hdkeychain"
+	"github.com/dogesuite/doged/chaincfg"
+	"github.com/dogesuite/doged/txscript"
+)
+
+const (
+	// descriptorInputCharset houses the characters which may appear in an
+	// output descriptor in the order defined by the checksum algorithm.
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+	// descriptorChecksumCharset houses the characters used to encode the
+	// checksum of an output descriptor.
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// descriptorChecksumLen is the length of the checksum of an output
+	// descriptor.
+	descriptorChecksumLen = 8
+)
+
+// descriptorPolyMod updates the passed checksum state of an output descriptor
+// with the passed value.
+func descriptorPolyMod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// descriptorChecksum returns the checksum of the passed output descriptor
+// without a checksum as defined by BIP 380.
+func descriptorChecksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos == -1 {
+			return "", fmt.Errorf("invalid character %q in descriptor",
+				ch)
+		}
+
+		// Emit a symbol for the position inside the group for every
+		// character and a symbol for the group of every three
+		// characters.
+		c = descriptorPolyMod(c, pos&31)
+		cls = cls*3 + pos>>5
+		clsCount++
+		if clsCount == 3 {
+			c = descriptorPolyMod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = descriptorPolyMod(c, cls)
+	}
+
+	// Shift further to determine the checksum.
+	for i := 0; i < descriptorChecksumLen; i++ {
+		c = descriptorPolyMod(c, 0)
+	}
+	c ^= 1
+
+	var checksum [descriptorChecksumLen]byte
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*(7-i)))&31]
+	}
+	return string(checksum[:]), nil
+}
+
+// descriptorType identifies the output script type of an output descriptor.
+type descriptorType int
+
+const (
+	// descriptorPKH describes pay-to-pubkey-hash outputs, pkh(KEY).
+	descriptorPKH descriptorType = iota
+
+	// descriptorWPKH describes pay-to-witness-pubkey-hash outputs,
+	// wpkh(KEY).
+	descriptorWPKH
+
+	// descriptorSHWPKH describes pay-to-witness-pubkey-hash outputs nested
+	// in pay-to-script-hash outputs, sh(wpkh(KEY)).
+	descriptorSHWPKH
+
+	// descriptorTR describes pay-to-taproot outputs without a script tree,
+	// tr(KEY).
+	descriptorTR
+)
+
+// descriptorTypes maps the script expressions of the supported output
+// descriptor types to the type.
+var descriptorTypes = []struct {
+	prefix string
+	suffix string
+	typ    descriptorType
+}{
+	{"pkh(", ")", descriptorPKH},
+	{"wpkh(", ")", descriptorWPKH},
+	{"sh(wpkh(", "))", descriptorSHWPKH},
+	{"tr(", ")", descriptorTR},
+}
+
+// outputDescriptor is a ranged output descriptor which derives its addresses
+// from an extended public key.
+type outputDescriptor struct {
+	// desc is the descriptor including its checksum.
+	desc string
+
+	typ descriptorType
+
+	// key is the extended public key the wildcard of the descriptor
+	// derives the keys of its addresses from.
+	key *hdkeychain.ExtendedKey
+
+	params *chaincfg.Params
+}
+
+// parseDescriptor parses the passed ranged output descriptor.  The checksum is
+// optional, but it must be correct when given.  Only descriptors of the forms
+// pkh(KEY), wpkh(KEY), sh(wpkh(KEY)) and tr(KEY) are supported, where KEY is an
+// extended public key for the passed network with an optional key origin
+// followed by unhardened derivation steps ending in a wildcard, for example
+// wpkh([d34db33f/84'/0'/0']xpub.../0/*).
+func parseDescriptor(desc string, params *chaincfg.Params) (*outputDescriptor, error) {
+	desc = strings.TrimSpace(desc)
+	if i := strings.IndexByte(desc, '#'); i != -1 {
+		checksum := desc[i+1:]
+		desc = desc[:i]
+		want, err := descriptorChecksum(desc)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != want {
+			return nil, fmt.Errorf("invalid descriptor checksum %q, "+
+				"expected %q", checksum, want)
+		}
+	}
+	checksum, err := descriptorChecksum(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range descriptorTypes {
+		if !strings.HasPrefix(desc, t.prefix) ||
+			!strings.HasSuffix(desc, t.suffix) {
+
+			continue
+		}
+		keyExpr := desc[len(t.prefix) : len(desc)-len(t.suffix)]
+		key, err := parseDescriptorKey(keyExpr, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return &outputDescriptor{
+			desc:   desc + "#" + checksum,
+			typ:    t.typ,
+			key:    key,
+			params: params,
+		}, nil
+	}
+
+	return nil, errors.New("unsupported descriptor, only pkh, wpkh, " +
+		"sh(wpkh) and tr descriptors without script trees are supported")
+}
+
+// parseDescriptorKey parses the passed ranged key expression of an output
+// descriptor and returns the extended public key its wildcard derives from.
+func parseDescriptorKey(expr string, params *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
+	// The key origin only describes how the key was derived from the
+	// master key, so it is merely validated.
+	if strings.HasPrefix(expr, "[") {
+		end := strings.IndexByte(expr, ']')
+		if end == -1 {
+			return nil, errors.New("key origin is not closed")
+		}
+		origin := strings.Split(expr[1:end], "/")
+		fingerprint, err := hex.DecodeString(origin[0])
+		if err != nil || len(fingerprint) != 4 {
+			return nil, fmt.Errorf("invalid key origin fingerprint "+
+				"%q", origin[0])
+		}
+		for _, step := range origin[1:] {
+			step = strings.TrimRight(step, "'h")
+			if _, err := strconv.ParseUint(step, 10, 31); err != nil {
+				return nil, fmt.Errorf("invalid key origin "+
+					"derivation step %q", step)
+			}
+		}
+		expr = expr[end+1:]
+	}
+
+	if strings.ContainsAny(expr, ",{}()") {
+		return nil, errors.New("script expressions are not supported")
+	}
+
+	steps := strings.Split(expr, "/")
+	key, err := hdkeychain.NewKeyFromString(steps[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended key: %v", err)
+	}
+	if key.IsPrivate() {
+		return nil, errors.New("private keys are not accepted")
+	}
+	if !key.IsForNet(params) {
+		return nil, fmt.Errorf("extended key is not for %s", params.Name)
+	}
+
+	// Only public derivation is possible from an extended public key and
+	// the descriptor must derive a range of keys.
+	steps = steps[1:]
+	if len(steps) == 0 || !strings.HasPrefix(steps[len(steps)-1], "*") {
+		return nil, errors.New("descriptor is not ranged")
+	}
+	switch steps[len(steps)-1] {
+	case "*":
+	case "*'", "*h":
+		return nil, errors.New("hardened derivation requires private keys")
+	default:
+		return nil, fmt.Errorf("invalid derivation step %q",
+			steps[len(steps)-1])
+	}
+	for _, step := range steps[:len(steps)-1] {
+		index, err := strconv.ParseUint(step, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation step %q", step)
+		}
+		key, err = key.Derive(uint32(index))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// String returns the descriptor including its checksum.
+func (d *outputDescriptor) String() string {
+	return d.desc
+}
+
+// deriveAddress returns the address of the descriptor at the passed index.
+// An error is returned in the extremely unlikely case that there is no key
+// at the index, in which case the index must be skipped.
+func (d *outputDescriptor) deriveAddress(index uint32) (btcutil.Address, error) {
+	child, err := d.key.Derive(index)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := child.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+
+	keyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+	switch d.typ {
+	case descriptorPKH:
+		return btcutil.NewAddressPubKeyHash(keyHash, d.params)
+
+	case descriptorWPKH:
+		return btcutil.NewAddressWitnessPubKeyHash(keyHash, d.params)
+
+	case descriptorSHWPKH:
+		redeemScript := append([]byte{txscript.OP_0,
+			txscript.OP_DATA_20}, keyHash...)
+		return btcutil.NewAddressScriptHash(redeemScript, d.params)
+
+	case descriptorTR:
+		outputKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+		return btcutil.NewAddressTaproot(
+			schnorr.SerializePubKey(outputKey), d.params)
+	}
+
+	return nil, fmt.Errorf("unknown descriptor type %d", d.typ)
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dogesuite/doged/btcjson"
+	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/btcutil/hdkeychain"
+	"github.com/dogesuite/doged/chaincfg"
+	"github.com/dogesuite/doged/txscript"
+)
+
+// testAccountKey returns the extended public key of a fixed account for the
+// passed network.
+func testAccountKey(t *testing.T, params *chaincfg.Params) *hdkeychain.ExtendedKey {
+	t.Helper()
+
+	seed := bytes.Repeat([]byte{0x01}, hdkeychain.RecommendedSeedLen)
+	key, err := hdkeychain.NewMaster(seed, params)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	for _, index := range []uint32{84, 0, 0} {
+		key, err = key.Derive(hdkeychain.HardenedKeyStart + index)
+		if err != nil {
+			t.Fatalf("unable to derive key: %v", err)
+		}
+	}
+	key, err = key.Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter key: %v", err)
+	}
+	return key
+}
+
+// TestDescriptorChecksum ensures descriptor checksums are computed as defined
+// by BIP 380.
+func TestDescriptorChecksum(t *testing.T) {
+	checksum, err := descriptorChecksum("raw(deadbeef)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if checksum != "89f8spxm" {
+		t.Fatalf("unexpected checksum - got %s, want 89f8spxm", checksum)
+	}
+
+	if _, err := descriptorChecksum("raw(deadbeef)é"); err == nil {
+		t.Fatalf("expected error for invalid character")
+	}
+}
+
+// TestParseDescriptor ensures ranged descriptors are parsed and derive the
+// expected addresses while unsupported descriptors are rejected.
+func TestParseDescriptor(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	account := testAccountKey(t, params)
+	xpub := account.String()
+
+	// Derive the expected key at index 7 of the external chain.
+	external, err := account.Derive(0)
+	if err != nil {
+		t.Fatalf("unable to derive key: %v", err)
+	}
+	child, err := external.Derive(7)
+	if err != nil {
+		t.Fatalf("unable to derive key: %v", err)
+	}
+	pubKey, err := child.ECPubKey()
+	if err != nil {
+		t.Fatalf("unable to get public key: %v", err)
+	}
+	keyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+
+	tests := []struct {
+		desc  string
+		class txscript.ScriptClass
+	}{
+		{"pkh(" + xpub + "/0/*)", txscript.PubKeyHashTy},
+		{"wpkh([d34db33f/84'/0h/0']" + xpub + "/0/*)",
+			txscript.WitnessV0PubKeyHashTy},
+		{"sh(wpkh(" + xpub + "/0/*))", txscript.ScriptHashTy},
+		{"tr(" + xpub + "/0/*)", txscript.WitnessV1TaprootTy},
+	}
+	for _, test := range tests {
+		d, err := parseDescriptor(test.desc, params)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.desc, err)
+			continue
+		}
+
+		// The descriptor round trips with its checksum.
+		if !strings.HasPrefix(d.String(), test.desc+"#") {
+			t.Errorf("%s: unexpected string %s", test.desc, d)
+			continue
+		}
+		if _, err := parseDescriptor(d.String(), params); err != nil {
+			t.Errorf("%s: unable to parse with checksum: %v",
+				test.desc, err)
+			continue
+		}
+
+		addr, err := d.deriveAddress(7)
+		if err != nil {
+			t.Errorf("%s: unable to derive address: %v", test.desc, err)
+			continue
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Errorf("%s: unable to create script: %v", test.desc, err)
+			continue
+		}
+		if class := txscript.GetScriptClass(pkScript); class != test.class {
+			t.Errorf("%s: unexpected script class %v", test.desc, class)
+			continue
+		}
+
+		// Key hash based addresses commit to the expected key.
+		switch addr := addr.(type) {
+		case *btcutil.AddressPubKeyHash:
+			if !bytes.Equal(addr.ScriptAddress(), keyHash) {
+				t.Errorf("%s: unexpected key hash", test.desc)
+			}
+		case *btcutil.AddressWitnessPubKeyHash:
+			if !bytes.Equal(addr.ScriptAddress(), keyHash) {
+				t.Errorf("%s: unexpected key hash", test.desc)
+			}
+		}
+	}
+
+	// The checksum is verified when given.
+	invalid := []string{
+		"wpkh(" + xpub + "/0/*)#qqqqqqqq",
+		"combo(" + xpub + "/0/*)",
+		"wpkh(" + xpub + "/0)",
+		"wpkh(" + xpub + "/0/*')",
+		"wpkh(" + xpub + "/0'/*)",
+		"wpkh([d34db3/0']" + xpub + "/0/*)",
+		"tr(" + xpub + "/0/*,{pk(" + xpub + "/1/*)})",
+		"wpkh(" + testAccountKey(t, &chaincfg.TestNet3Params).String() +
+			"/0/*)",
+	}
+	for _, desc := range invalid {
+		if _, err := parseDescriptor(desc, params); err == nil {
+			t.Errorf("%s: expected error", desc)
+		}
+	}
+
+	// Private keys are never accepted.
+	seed := bytes.Repeat([]byte{0x01}, hdkeychain.RecommendedSeedLen)
+	master, err := hdkeychain.NewMaster(seed, params)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	if _, err := parseDescriptor("wpkh("+master.String()+"/0/*)",
+		params); err == nil {
+
+		t.Errorf("expected error for private key")
+	}
+}
+
+// TestWSClientFilterDescriptor ensures the addresses of descriptors registered
+// with a websocket client filter are extended as they are used.
+func TestWSClientFilterDescriptor(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	xpub := testAccountKey(t, params).String()
+	d, err := parseDescriptor("wpkh("+xpub+"/0/*)", params)
+	if err != nil {
+		t.Fatalf("unable to parse descriptor: %v", err)
+	}
+	deriveAddress := func(index uint32) btcutil.Address {
+		addr, err := d.deriveAddress(index)
+		if err != nil {
+			t.Fatalf("unable to derive address: %v", err)
+		}
+		return addr
+	}
+
+	filter := newWSClientFilter(nil, nil, params)
+	fd := filter.addDescriptor(d, 5)
+	if fd.derived != 5 || fd.nextIndex != 0 {
+		t.Fatalf("unexpected state after registration - derived %d, "+
+			"next index %d", fd.derived, fd.nextIndex)
+	}
+	if !filter.existsAddress(deriveAddress(4)) ||
+		filter.existsAddress(deriveAddress(5)) {
+
+		t.Fatalf("unexpected addresses in filter")
+	}
+
+	// Using an address extends the derived addresses to maintain the gap
+	// limit after it.
+	filter.markAddressUsed(deriveAddress(3))
+	if fd.derived != 9 || fd.nextIndex != 4 {
+		t.Fatalf("unexpected state after use - derived %d, next index "+
+			"%d", fd.derived, fd.nextIndex)
+	}
+	if !filter.existsAddress(deriveAddress(8)) {
+		t.Fatalf("extended address is not in filter")
+	}
+
+	// The extension is reported once.
+	ntfns := filter.takeExtendedDescriptors()
+	wantNtfn := btcjson.NewDescriptorExtendedNtfn(d.String(), 4, 9)
+	if len(ntfns) != 1 || !reflect.DeepEqual(ntfns[0], wantNtfn) {
+		t.Fatalf("unexpected extension notifications %v", ntfns)
+	}
+	if ntfns := filter.takeExtendedDescriptors(); len(ntfns) != 0 {
+		t.Fatalf("extension was reported again")
+	}
+
+	// Using an earlier address doesn't change anything.
+	filter.markAddressUsed(deriveAddress(1))
+	if fd.derived != 9 || fd.nextIndex != 4 {
+		t.Fatalf("unexpected state after reuse - derived %d, next "+
+			"index %d", fd.derived, fd.nextIndex)
+	}
+
+	if ntfns := filter.takeExtendedDescriptors(); len(ntfns) != 0 {
+		t.Fatalf("unexpected extension notifications %v", ntfns)
+	}
+
+	// Registering the descriptor again updates its gap limit.
+	if filter.addDescriptor(d, 10) != fd || fd.derived != 14 {
+		t.Fatalf("unexpected state after re-registration - derived %d",
+			fd.derived)
+	}
+
+	// Descriptors carried over to a reloaded filter keep their next unused
+	// index.
+	reloaded := newWSClientFilter(nil, nil, params)
+	reloaded.addDescriptorsFrom(filter)
+	reloadedFd := reloaded.descriptors[d.String()]
+	if reloadedFd == nil || reloadedFd.nextIndex != 4 ||
+		reloadedFd.derived != 14 || reloadedFd.gapLimit != 10 {
+
+		t.Fatalf("unexpected state after reload %+v", reloadedFd)
+	}
+	if !reloaded.existsAddress(deriveAddress(13)) {
+		t.Fatalf("carried over address is not in filter")
+	}
+}
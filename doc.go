@@ -57,6 +57,15 @@ Application Options:
                               set the log level for individual subsystems --
                               Use show to list available subsystems (default:
                               info)
+      --diffnode=             Mirror the outcome of every transaction processed
+                              by the mempool to the testmempoolaccept RPC of
+                              the reference node at this host:port and log
+                              where the two disagree
+      --diffnodecert=         File containing the certificate of the reference
+                              node RPC server -- Enables TLS for the diffnode
+                              connection
+      --diffnodepass=         Password for the RPC server of the reference node
+      --diffnodeuser=         Username for the RPC server of the reference node
       --dropaddrindex         Deletes the address-based transaction index from
                               the database on start up and then exits.
       --dropcfindex           Deletes the index used for committed filtering
@@ -64,9 +73,14 @@ Application Options:
                               then exits.
       --droptxindex           Deletes the hash-based transaction index from the
                               database on start up and then exits.
+      --explorer              Serve a lightweight block explorer web UI at
+                              /explorer/ on the RPC listeners to users with the
+                              rpcuser/rpcpass credentials
       --externalip=           Add an ip to the list of local addresses we claim
                               to listen on to peers
       --generate              Generate (mine) bitcoins using the CPU
+      --incrementalrelayfee=  The minimum fee rate increase in BTC/kB for
+                              replacing mempool transactions (default: 1e-05)
       --limitfreerelay=       Limit relay of transactions with no transaction
                               fee to the given amount in thousands of bytes per
                               minute (default: 15)
@@ -78,12 +92,19 @@ Application Options:
                               memory (default: 100)
       --maxpeers=             Max number of inbound and outbound peers
                               (default: 125)
+      --mempoolexpiry=        Evict transactions and their descendants from the
+                              memory pool if they have not been mined within
+                              this amount of time (0 to disable) (default:
+                              336h0m0s)
       --miningaddr=           Add the specified payment address to the list of
                               addresses to use for generated blocks -- At least
                               one address is required if the generate option is
                               set
       --minrelaytxfee=        The minimum transaction fee in BTC/kB to be
                               considered a non-zero fee. (default: 1e-05)
+      --noaddrserve           Do not respond to getaddr requests from peers --
+                              NOTE: Address serving is automatically disabled
+                              if listening is disabled
       --nobanning             Disable banning of misbehaving peers
       --nocfilters            Disable committed filtering (CF) support
       --nocheckpoints         Disable built-in checkpoints.  Don't do this
@@ -108,6 +129,9 @@ Application Options:
                               (eg. 127.0.0.1:9050)
       --onionpass=            Password for onion proxy server
       --onionuser=            Username for onion proxy server
+      --pidfile=              Write the process id to the specified file while
+                              running -- NOTE: A file left behind by a process
+                              which did not exit cleanly is replaced
       --profile=              Enable HTTP profiling on given port -- NOTE port
                               must be between 1024 and 65536
       --proxy=                Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)
@@ -116,6 +140,9 @@ Application Options:
       --regtest               Use the regression test network
       --rejectnonstd          Reject non-standard transactions regardless of
                               the default settings for the active network.
+      --relayannex            Relay transactions whose taproot inputs carry an
+                              annex, which is reserved for future upgrades and
+                              non-standard by default
       --relaynonstd           Relay non-standard transactions regardless of the
                               default settings for the active network.
       --rpccert=              File containing the certificate file
@@ -135,6 +162,10 @@ Application Options:
                               need to be worked around
   -P, --rpcpass=              Password for RPC connections
   -u, --rpcuser=              Username for RPC connections
+      --scriptflags=          Force a consensus script verification flag on
+                              (+FLAG) or off (-FLAG), eg. +CLEANSTACK or
+                              -TAPROOT -- Only allowed on regtest and simnet;
+                              can be specified multiple times
       --sigcachemaxsize=      The maximum number of entries in the signature
                               verification cache (default: 100000)
       --simnet                Use the simulation test network
@@ -156,5 +187,17 @@ Application Options:
 Help Options:
   -h, --help           Show this help message
 
+Offline Utilities
+
+The util subcommand decodes raw data without starting the node or requiring
+access to its databases.  The data is read from the provided file or standard
+input and is hex encoded by default (base64 for PSBTs) unless --binary is
+specified.  The result is printed as JSON in the same format returned by the
+equivalent RPCs:
+
+  btcd util decodeblock [--binary] [--testnet|--regtest|--simnet|--signet] [FILE]
+  btcd util decodetx [--binary] [--testnet|--regtest|--simnet|--signet] [FILE]
+  btcd util decodescript [--binary] [--testnet|--regtest|--simnet|--signet] [FILE]
+  btcd util decodepsbt [--binary] [--testnet|--regtest|--simnet|--signet] [FILE]
 */
 package main
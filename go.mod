@@ -7,6 +7,7 @@ require (
 	github.com/dogesuite/btclog v0.0.0-20170628155309-84c8d2346e9f
 	github.com/dogesuite/doged/btcec/v2 v2.1.3
 	github.com/dogesuite/doged/btcutil v1.1.0
+	github.com/dogesuite/doged/btcutil/psbt v1.1.4
 	github.com/dogesuite/doged/chaincfg/chainhash v1.0.1
 	github.com/dogesuite/go-socks v0.0.0-20170105172521-4720035b7bfd
 	github.com/dogesuite/websocket v0.0.0-20150119174127-31079b680792
@@ -30,6 +31,8 @@ require (
 
 replace github.com/dogesuite/doged/btcutil => ./btcutil
 
+replace github.com/dogesuite/doged/btcutil/psbt => ./btcutil/psbt
+
 replace github.com/dogesuite/doged/btcec/v2 => ./btcec
 
 replace github.com/dogesuite/doged/chaincfg/chainhash => ./chaincfg/chainhash
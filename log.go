@@ -68,6 +68,7 @@ var (
 	scrpLog = backendLog.Logger("SCRP")
 	srvrLog = backendLog.Logger("SRVR")
 	syncLog = backendLog.Logger("SYNC")
+	tdifLog = backendLog.Logger("TDIF")
 	txmpLog = backendLog.Logger("TXMP")
 )
 
@@ -102,6 +103,7 @@ var subsystemLoggers = map[string]btclog.Logger{
 	"SCRP": scrpLog,
 	"SRVR": srvrLog,
 	"SYNC": syncLog,
+	"TDIF": tdifLog,
 	"TXMP": txmpLog,
 }
 
@@ -0,0 +1,337 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/dogesuite/doged/blockchain"
+	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/btcutil/psbt"
+	"github.com/dogesuite/doged/chaincfg/chainhash"
+	"github.com/dogesuite/doged/mining"
+	"github.com/dogesuite/doged/txscript"
+	"github.com/dogesuite/doged/wire"
+)
+
+// FeeBump describes an unsigned transaction which raises the fee rate of a
+// transaction in the memory pool along with the fees involved.
+type FeeBump struct {
+	// Packet is the unsigned replacement or child transaction.  The inputs
+	// include the previous outputs known to the memory pool.  Non-witness
+	// inputs which spend confirmed outputs lack the previous transaction
+	// they must commit to, as reported by MissingUtxoInputs, which has to
+	// be added before the packet can be handed to a signer.
+	Packet *psbt.Packet
+
+	// OrigFee is the fee paid by the transaction whose fee rate is raised.
+	OrigFee int64
+
+	// Fee is the fee paid by the new transaction.
+	Fee int64
+
+	// VSize is the estimated virtual size of the new transaction once it is
+	// signed.
+	VSize int64
+
+	// FeeRate is the resulting fee rate in satoshi per 1000 virtual bytes.
+	// For replacements it is the fee rate of the replacement while for
+	// children it is the combined fee rate of the parent and child.
+	FeeRate int64
+}
+
+// MissingUtxoInputs returns the indexes of the inputs of the packet which
+// include neither the previous output they spend nor the full previous
+// transaction, and therefore can't be signed.
+func (b *FeeBump) MissingUtxoInputs() []int {
+	var missing []int
+	for i, input := range b.Packet.Inputs {
+		if input.WitnessUtxo == nil && input.NonWitnessUtxo == nil {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// feeForRate returns the fee a transaction with the passed virtual size needs
+// to pay for the passed fee rate in satoshi per 1000 virtual bytes, rounded up.
+func feeForRate(vsize int64, feeRate btcutil.Amount) int64 {
+	return (vsize*int64(feeRate) + 999) / 1000
+}
+
+// minOutputValue returns the smallest value a fee bump leaves in an output
+// paying to the passed public key script, which must neither be dust nor
+// require the additional fee for outputs below the soft dust limit.
+func (mp *TxPool) minOutputValue(pkScript []byte) int64 {
+	minValue := MinNonDustValue(pkScript, mp.cfg.Policy.MinRelayTxFee,
+		mp.cfg.ChainParams)
+	if minValue < mp.cfg.ChainParams.SoftDustLimit {
+		minValue = mp.cfg.ChainParams.SoftDustLimit
+	}
+	return minValue
+}
+
+// newFeeBumpPacket returns a packet for the passed unsigned transaction whose
+// inputs are populated with the previous outputs known to the memory pool.
+// Witness inputs, as indicated by the passed flags, reference the spent output
+// directly while all inputs which spend unconfirmed outputs also include the
+// full previous transaction.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) newFeeBumpPacket(tx *wire.MsgTx, utxoView *blockchain.UtxoViewpoint,
+	witness []bool) (*psbt.Packet, error) {
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	for i, txIn := range tx.TxIn {
+		prevOut := txIn.PreviousOutPoint
+		if witness[i] {
+			entry := utxoView.LookupEntry(prevOut)
+			if entry == nil || entry.IsSpent() {
+				return nil, fmt.Errorf("output %v is not available",
+					prevOut)
+			}
+			packet.Inputs[i].WitnessUtxo = wire.NewTxOut(
+				entry.Amount(), entry.PkScript())
+		}
+		if txDesc, ok := mp.pool[prevOut.Hash]; ok {
+			packet.Inputs[i].NonWitnessUtxo = txDesc.Tx.MsgTx()
+		}
+	}
+	return packet, nil
+}
+
+// BumpFeeRBF returns an unsigned replacement for the transaction with the passed
+// hash which pays at least the passed fee rate in satoshi per 1000 virtual
+// bytes.  The replacement spends the same inputs and pays the same outputs,
+// except for the change output at the passed index which funds the additional
+// fee.  The change output is dropped when it would become dust or fall below
+// the soft dust limit of the network.  A fee rate of zero requests the minimum
+// fee the replacement rules allow.
+//
+// An error is returned when the transaction is not in the memory pool, does
+// not signal replaceability or the resulting replacement would violate the
+// replacement rules.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) BumpFeeRBF(txHash *chainhash.Hash, feeRate btcutil.Amount,
+	changeIndex int) (*FeeBump, error) {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	txDesc, ok := mp.pool[*txHash]
+	if !ok {
+		return nil, fmt.Errorf("transaction %v is not in the pool", txHash)
+	}
+	if mp.cfg.Policy.RejectReplacement {
+		return nil, fmt.Errorf("transaction replacement is disabled " +
+			"by policy")
+	}
+	origTx := txDesc.Tx
+	if !mp.signalsReplacement(origTx, nil) {
+		return nil, fmt.Errorf("transaction %v does not signal "+
+			"replaceability", txHash)
+	}
+	origMsgTx := origTx.MsgTx()
+	if changeIndex < 0 || changeIndex >= len(origMsgTx.TxOut) {
+		return nil, fmt.Errorf("change output %d does not exist",
+			changeIndex)
+	}
+
+	// The replacement evicts the transaction along with all of its
+	// descendants from the pool.
+	conflicts := mp.txConflicts(origTx)
+	if len(conflicts) > MaxReplacementEvictions {
+		return nil, fmt.Errorf("replacing transaction %v evicts more "+
+			"transactions than permitted: max is %v, evicts %v",
+			txHash, MaxReplacementEvictions, len(conflicts))
+	}
+
+	// Build the unsigned replacement which spends the same inputs with the
+	// same sequence numbers so it signals replaceability as well.
+	replacement := wire.NewMsgTx(origMsgTx.Version)
+	replacement.LockTime = origMsgTx.LockTime
+	witness := make([]bool, len(origMsgTx.TxIn))
+	for i, txIn := range origMsgTx.TxIn {
+		replacement.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: txIn.PreviousOutPoint,
+			Sequence:         txIn.Sequence,
+		})
+		witness[i] = len(txIn.Witness) > 0
+	}
+	for _, txOut := range origMsgTx.TxOut {
+		replacement.AddTxOut(wire.NewTxOut(txOut.Value, txOut.PkScript))
+	}
+
+	// The outputs other than the change are kept as is, so the additional
+	// fee for those below the soft dust limit has to be paid again.
+	otherOutputs := make([]*wire.TxOut, 0, len(origMsgTx.TxOut)-1)
+	otherOutputs = append(otherOutputs, origMsgTx.TxOut[:changeIndex]...)
+	otherOutputs = append(otherOutputs, origMsgTx.TxOut[changeIndex+1:]...)
+	dustFee := calcSoftDustFee(otherOutputs, mp.cfg.ChainParams)
+
+	// requiredFee returns the minimum fee a replacement with the passed
+	// virtual size must pay.  It has to pay for the conflicts it evicts as
+	// well as its own bandwidth and must have a higher fee rate than every
+	// conflict.
+	minRelayTxFee := mp.cfg.Policy.MinRelayTxFee
+	incrementalRelayFee := mp.IncrementalRelayFee()
+	requiredFee := func(vsize int64) int64 {
+		var conflictsFee int64
+		required := feeForRate(vsize, feeRate)
+		for hash := range conflicts {
+			conflictDesc := mp.pool[hash]
+			conflictsFee += conflictDesc.Fee
+			minFee := feeForRate(vsize, btcutil.Amount(
+				conflictDesc.FeePerKB+1))
+			if minFee > required {
+				required = minFee
+			}
+		}
+		minFee := conflictsFee + calcMinRequiredTxRelayFee(vsize,
+			incrementalRelayFee)
+		if minFee > required {
+			required = minFee
+		}
+		if dustFee > 0 {
+			minFee = calcMinRequiredTxRelayFee(vsize,
+				minRelayTxFee) + dustFee
+			if minFee > required {
+				required = minFee
+			}
+		}
+		return required
+	}
+
+	// The signed replacement has the size of the original transaction
+	// since it spends the same inputs with the same scripts.
+	vsize := GetTxVirtualSize(origTx)
+	fee := requiredFee(vsize)
+	change := replacement.TxOut[changeIndex]
+	change.Value -= fee - txDesc.Fee
+	if change.Value < mp.minOutputValue(change.PkScript) {
+		// Drop the change output entirely when it would become dust or
+		// require the additional fee for outputs below the soft dust
+		// limit, which leaves its full value to the fee.
+		if len(replacement.TxOut) == 1 {
+			return nil, fmt.Errorf("change output %d of %v is "+
+				"insufficient to pay the fee of %v", changeIndex,
+				btcutil.Amount(origMsgTx.TxOut[changeIndex].Value),
+				btcutil.Amount(fee))
+		}
+		vsize -= int64(change.SerializeSize())
+		fee = txDesc.Fee + origMsgTx.TxOut[changeIndex].Value
+		if fee < requiredFee(vsize) {
+			return nil, fmt.Errorf("change output %d of %v is "+
+				"insufficient to pay the fee of %v", changeIndex,
+				btcutil.Amount(origMsgTx.TxOut[changeIndex].Value),
+				btcutil.Amount(requiredFee(vsize)))
+		}
+		replacement.TxOut = append(replacement.TxOut[:changeIndex],
+			replacement.TxOut[changeIndex+1:]...)
+	}
+
+	utxoView, err := mp.fetchInputUtxos(origTx)
+	if err != nil {
+		return nil, err
+	}
+	packet, err := mp.newFeeBumpPacket(replacement, utxoView, witness)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FeeBump{
+		Packet:  packet,
+		OrigFee: txDesc.Fee,
+		Fee:     fee,
+		VSize:   vsize,
+		FeeRate: fee * 1000 / vsize,
+	}, nil
+}
+
+// BumpFeeCPFP returns an unsigned child transaction which spends the output at
+// the passed index of the transaction with the passed hash back to the same
+// script.  The child pays a fee such that the combined fee rate of the parent
+// and the child is at least the passed fee rate in satoshi per 1000 virtual
+// bytes, or at least the minimum relay fee for the child itself when the fee
+// rate is zero.  The child signals replaceability so it can be bumped again.
+//
+// The spent output should be controlled by the caller, such as the change of
+// the parent, since the child can only be signed by its owner.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) BumpFeeCPFP(txHash *chainhash.Hash, feeRate btcutil.Amount,
+	outputIndex int) (*FeeBump, error) {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	txDesc, ok := mp.pool[*txHash]
+	if !ok {
+		return nil, fmt.Errorf("transaction %v is not in the pool", txHash)
+	}
+	parent := txDesc.Tx.MsgTx()
+	if outputIndex < 0 || outputIndex >= len(parent.TxOut) {
+		return nil, fmt.Errorf("output %d does not exist", outputIndex)
+	}
+	prevOut := wire.OutPoint{Hash: *txHash, Index: uint32(outputIndex)}
+	if spender, ok := mp.outpoints[prevOut]; ok {
+		return nil, fmt.Errorf("output %v is already spent by %v",
+			prevOut, spender.Hash())
+	}
+	spent := parent.TxOut[outputIndex]
+	if txscript.IsUnspendable(spent.PkScript) {
+		return nil, fmt.Errorf("output %v is unspendable", prevOut)
+	}
+
+	child := wire.NewMsgTx(wire.TxVersion)
+	child.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: prevOut,
+		Sequence:         MaxRBFSequence,
+	})
+	output := wire.NewTxOut(spent.Value, spent.PkScript)
+	child.AddTxOut(output)
+
+	// Estimate the size of the signed child from the typical size of an
+	// input spending the output.  Witness inputs additionally require the
+	// segwit marker and flag.
+	witness := txscript.IsWitnessProgram(spent.PkScript)
+	vsize := int64(child.SerializeSize() - wire.NewTxIn(&prevOut, nil,
+		nil).SerializeSize() + TypicalInputSize(spent.PkScript))
+	if witness {
+		vsize++
+	}
+
+	minRelayTxFee := mp.cfg.Policy.MinRelayTxFee
+	fee := feeForRate(GetTxVirtualSize(txDesc.Tx)+vsize, feeRate) - txDesc.Fee
+	if minFee := calcMinRequiredTxRelayFee(vsize, minRelayTxFee); fee < minFee {
+		fee = minFee
+	}
+	output.Value -= fee
+	if output.Value < mp.minOutputValue(output.PkScript) {
+		return nil, fmt.Errorf("output %v of %v is insufficient to pay "+
+			"the fee of %v", prevOut, btcutil.Amount(spent.Value),
+			btcutil.Amount(fee))
+	}
+
+	utxoView := blockchain.NewUtxoViewpoint()
+	utxoView.AddTxOut(txDesc.Tx, uint32(outputIndex), mining.UnminedHeight)
+	packet, err := mp.newFeeBumpPacket(child, utxoView, []bool{witness})
+	if err != nil {
+		return nil, err
+	}
+
+	packageVSize := GetTxVirtualSize(txDesc.Tx) + vsize
+	return &FeeBump{
+		Packet:  packet,
+		OrigFee: txDesc.Fee,
+		Fee:     fee,
+		VSize:   vsize,
+		FeeRate: (txDesc.Fee + fee) * 1000 / packageVSize,
+	}, nil
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"testing"
+
+	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/chaincfg"
+	"github.com/dogesuite/doged/txscript"
+)
+
+// signFeeBump signs the unsigned transaction of the passed fee bump with the
+// key of the harness, which all test outputs pay to.
+func signFeeBump(ctx *testContext, bump *FeeBump) *btcutil.Tx {
+	ctx.t.Helper()
+
+	tx := bump.Packet.UnsignedTx.Copy()
+	for i := range tx.TxIn {
+		sigScript, err := txscript.SignatureScript(tx, i,
+			ctx.harness.payScript, txscript.SigHashAll,
+			ctx.harness.signKey, true)
+		if err != nil {
+			ctx.t.Fatalf("unable to sign transaction: %v", err)
+		}
+		tx.TxIn[i].SignatureScript = sigScript
+	}
+	return btcutil.NewTx(tx)
+}
+
+// TestBumpFeeRBF ensures the replacements built to raise the fee rate of a
+// transaction are accepted by the mempool in place of the original.
+func TestBumpFeeRBF(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+
+	coinbase := ctx.addCoinbaseTx(1)
+	outs := []spendableOutput{txOutToSpendableOut(coinbase, 0)}
+	tx := ctx.addSignedTx(outs, 2, 1000, true, false)
+
+	const feeRate = 20000
+	bump, err := harness.txPool.BumpFeeRBF(tx.Hash(), feeRate, 1)
+	if err != nil {
+		t.Fatalf("BumpFeeRBF: unexpected error: %v", err)
+	}
+	if bump.OrigFee != 1000 {
+		t.Fatalf("unexpected original fee %d", bump.OrigFee)
+	}
+	if bump.FeeRate < feeRate {
+		t.Fatalf("fee rate %d is below the target of %d", bump.FeeRate,
+			feeRate)
+	}
+	if bump.Packet.Inputs[0].NonWitnessUtxo != nil {
+		t.Fatalf("confirmed input includes the previous transaction")
+	}
+
+	// The confirmed legacy input lacks any previous output data until the
+	// previous transaction is added.
+	missing := bump.MissingUtxoInputs()
+	if len(missing) != 1 || missing[0] != 0 {
+		t.Fatalf("unexpected inputs missing utxo data %v", missing)
+	}
+	bump.Packet.Inputs[0].NonWitnessUtxo = coinbase.MsgTx()
+	if missing := bump.MissingUtxoInputs(); len(missing) != 0 {
+		t.Fatalf("unexpected inputs missing utxo data %v", missing)
+	}
+
+	// Only the change output funds the fee.
+	orig := tx.MsgTx().TxOut
+	replacement := bump.Packet.UnsignedTx.TxOut
+	if replacement[0].Value != orig[0].Value ||
+		orig[1].Value-replacement[1].Value != bump.Fee-bump.OrigFee {
+
+		t.Fatalf("unexpected replacement outputs")
+	}
+
+	signed := signFeeBump(ctx, bump)
+	if GetTxVirtualSize(signed) > bump.VSize {
+		t.Fatalf("replacement size %d exceeds the estimate of %d",
+			GetTxVirtualSize(signed), bump.VSize)
+	}
+	_, err = harness.txPool.ProcessTransaction(signed, false, false, 0)
+	if err != nil {
+		t.Fatalf("replacement was rejected: %v", err)
+	}
+	testPoolMembership(ctx, tx, false, false)
+	testPoolMembership(ctx, signed, false, true)
+
+	// A change output which can't cover the fee is rejected.
+	_, err = harness.txPool.BumpFeeRBF(signed.Hash(),
+		btcutil.SatoshiPerBitcoin*1000, 0)
+	if err == nil {
+		t.Fatalf("BumpFeeRBF: expected error for insufficient change")
+	}
+
+	// Transactions which don't signal replaceability can't be bumped.
+	coinbase = ctx.addCoinbaseTx(1)
+	outs = []spendableOutput{txOutToSpendableOut(coinbase, 0)}
+	tx = ctx.addSignedTx(outs, 1, 1000, false, false)
+	if _, err := harness.txPool.BumpFeeRBF(tx.Hash(), feeRate, 0); err == nil {
+		t.Fatalf("BumpFeeRBF: expected error for non-replaceable " +
+			"transaction")
+	}
+}
+
+// TestBumpFeeCPFP ensures the children built to raise the fee rate of a
+// transaction are accepted by the mempool and raise the combined fee rate to
+// the target.
+func TestBumpFeeCPFP(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+
+	coinbase := ctx.addCoinbaseTx(1)
+	outs := []spendableOutput{txOutToSpendableOut(coinbase, 0)}
+	parent := ctx.addSignedTx(outs, 2, 1000, false, false)
+
+	const feeRate = 20000
+	bump, err := harness.txPool.BumpFeeCPFP(parent.Hash(), feeRate, 1)
+	if err != nil {
+		t.Fatalf("BumpFeeCPFP: unexpected error: %v", err)
+	}
+	input := bump.Packet.Inputs[0]
+	if input.NonWitnessUtxo == nil ||
+		input.NonWitnessUtxo.TxHash() != *parent.Hash() {
+
+		t.Fatalf("child input doesn't include the parent transaction")
+	}
+
+	child := signFeeBump(ctx, bump)
+	childSize := GetTxVirtualSize(child)
+	if childSize > bump.VSize {
+		t.Fatalf("child size %d exceeds the estimate of %d", childSize,
+			bump.VSize)
+	}
+	packageFeeRate := (bump.OrigFee + bump.Fee) * 1000 /
+		(GetTxVirtualSize(parent) + childSize)
+	if packageFeeRate < feeRate {
+		t.Fatalf("package fee rate %d is below the target of %d",
+			packageFeeRate, feeRate)
+	}
+	_, err = harness.txPool.ProcessTransaction(child, false, false, 0)
+	if err != nil {
+		t.Fatalf("child was rejected: %v", err)
+	}
+	testPoolMembership(ctx, child, false, true)
+
+	// The output is now spent by the child.
+	if _, err := harness.txPool.BumpFeeCPFP(parent.Hash(), feeRate, 1); err == nil {
+		t.Fatalf("BumpFeeCPFP: expected error for spent output")
+	}
+}
@@ -48,6 +48,15 @@ const (
 	// can be evicted from the mempool when accepting a transaction
 	// replacement.
 	MaxReplacementEvictions = 100
+
+	// DefaultMaxTxAge is the default maximum amount of time a transaction
+	// is allowed to stay in the memory pool without being mined before it
+	// expires and is evicted along with all of its descendants.
+	DefaultMaxTxAge = time.Hour * 336
+
+	// DefaultExpireScanInterval is the default amount of time in between
+	// scans of the memory pool for expired transactions by ExpiryHandler.
+	DefaultExpireScanInterval = time.Minute * 5
 )
 
 // Tag represents an identifier to use for tagging orphan transactions.  The
@@ -103,6 +112,27 @@ type Config struct {
 	// FeeEstimatator provides a feeEstimator. If it is not nil, the mempool
 	// records all new transactions it observes into the feeEstimator.
 	FeeEstimator *FeeEstimator
+
+	// TxProcessed, if not nil, is invoked with every transaction which is
+	// accepted to or rejected from the main pool while processing
+	// transactions and orphans, along with the reason it was rejected or
+	// nil when it was accepted.  Transactions added to the orphan pool are
+	// not reported.  It is invoked with the mempool lock held, so it must
+	// not block or call back into the mempool.
+	TxProcessed func(tx *btcutil.Tx, err error)
+
+	// RejectFilter, if not nil, records transactions which are rejected
+	// for being invalid under the consensus rules and is used to refuse
+	// them without validating them again.
+	RejectFilter *RejectFilter
+
+	// ConsensusScriptFlags defines the function to use to access the
+	// script verification flags enforced by the consensus rules for the
+	// next block.  Transactions whose scripts fail to verify with the
+	// standard verification flags are only recorded in the reject filter
+	// when they also fail to verify with these flags.  This can be nil, in
+	// which case such transactions are never recorded.
+	ConsensusScriptFlags func() (txscript.ScriptFlags, error)
 }
 
 // Policy houses the policy (configuration parameters) which is used to
@@ -122,6 +152,12 @@ type Policy struct {
 	// Otherwise, all non-standard transactions will be rejected.
 	AcceptNonStd bool
 
+	// AcceptAnnex defines whether to accept transactions with taproot
+	// inputs which carry an annex.  The annex is reserved for future
+	// upgrades, so such transactions are rejected as non-standard unless
+	// this is set or AcceptNonStd is set.
+	AcceptAnnex bool
+
 	// FreeTxRelayLimit defines the given amount in thousands of bytes
 	// per minute that transactions with no fee are rate limited to.
 	FreeTxRelayLimit float64
@@ -144,10 +180,22 @@ type Policy struct {
 	// considered a non-zero fee.
 	MinRelayTxFee btcutil.Amount
 
+	// IncrementalRelayFee defines the minimum fee rate in BTC/kB a
+	// replacement transaction must pay for its own size on top of the fees
+	// of the transactions it replaces.
+	IncrementalRelayFee btcutil.Amount
+
 	// RejectReplacement, if true, rejects accepting replacement
 	// transactions using the Replace-By-Fee (RBF) signaling policy into
 	// the mempool.
 	RejectReplacement bool
+
+	// MaxTxAge is the maximum amount of time a transaction is allowed to
+	// stay in the mempool before it expires.  Expired transactions are
+	// evicted along with any transactions that spend their outputs during
+	// the next call to ExpireTransactions.  A value of zero disables
+	// expiry.
+	MaxTxAge time.Duration
 }
 
 // TxDesc is a descriptor containing a transaction in the mempool along with
@@ -190,6 +238,9 @@ type TxPool struct {
 	// the scan will only run when an orphan is added to the pool as opposed
 	// to on an unconditional timer.
 	nextExpireScan time.Time
+
+	notificationsLock sync.RWMutex
+	notifications     []NotificationCallback
 }
 
 // Ensure the TxPool type implements the mining.TxSource interface.
@@ -508,6 +559,88 @@ func (mp *TxPool) RemoveTransaction(tx *btcutil.Tx, removeRedeemers bool) {
 	mp.mtx.Unlock()
 }
 
+// expireTransactions removes all transactions which were added to the pool
+// before the passed cutoff time along with any transactions which spend their
+// outputs, since those would otherwise become orphans.  It returns the
+// descriptors of all removed transactions.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) expireTransactions(cutoff time.Time) []*TxDesc {
+	var expired []*TxDesc
+	for _, txDesc := range mp.pool {
+		// Skip transactions which are not old enough to expire or which
+		// were already removed as a descendant of an expired one.
+		if !txDesc.Added.Before(cutoff) {
+			continue
+		}
+		if _, exists := mp.pool[*txDesc.Tx.Hash()]; !exists {
+			continue
+		}
+
+		expired = append(expired, txDesc)
+		for _, descendant := range mp.txDescendants(txDesc.Tx, nil) {
+			if desc, exists := mp.pool[*descendant.Hash()]; exists {
+				expired = append(expired, desc)
+			}
+		}
+		mp.removeTransaction(txDesc.Tx, true)
+	}
+
+	return expired
+}
+
+// ExpireTransactions evicts all transactions which have been in the memory
+// pool for longer than the maximum transaction age defined by the policy,
+// along with all of their descendants.  An NTTxExpired notification is sent
+// for every evicted transaction.  It returns the number of evicted
+// transactions.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) ExpireTransactions() int {
+	maxTxAge := mp.cfg.Policy.MaxTxAge
+	if maxTxAge <= 0 {
+		return 0
+	}
+
+	mp.mtx.Lock()
+	expired := mp.expireTransactions(time.Now().Add(-maxTxAge))
+	numRemaining := len(mp.pool)
+	mp.mtx.Unlock()
+
+	if numExpired := len(expired); numExpired > 0 {
+		log.Debugf("Expired %d %s (remaining: %d)", numExpired,
+			pickNoun(numExpired, "transaction", "transactions"),
+			numRemaining)
+	}
+
+	// Notify subscribers without holding the lock so they are free to
+	// call back into the memory pool.
+	for _, txDesc := range expired {
+		mp.sendNotification(NTTxExpired, txDesc)
+	}
+
+	return len(expired)
+}
+
+// ExpiryHandler evicts expired transactions by calling ExpireTransactions every
+// passed interval until the passed quit channel is closed.  This ensures
+// transactions expire even while no blocks are connected, such as while the
+// chain is stalled.  It must be run as a goroutine.
+func (mp *TxPool) ExpiryHandler(interval time.Duration, quit <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			mp.ExpireTransactions()
+
+		case <-quit:
+			return
+		}
+	}
+}
+
 // RemoveDoubleSpends removes all transactions which spend outputs spent by the
 // passed transaction from the memory pool.  Removing those transactions then
 // leads to removing all transactions which rely on them, recursively.  This is
@@ -890,8 +1023,8 @@ func (mp *TxPool) validateReplacement(tx *btcutil.Tx,
 
 	// It should also have an absolute fee greater than all of the
 	// transactions it intends to replace and pay for its own bandwidth,
-	// which is determined by our minimum relay fee.
-	minFee := calcMinRequiredTxRelayFee(txSize, mp.cfg.Policy.MinRelayTxFee)
+	// which is determined by the incremental relay fee.
+	minFee := calcMinRequiredTxRelayFee(txSize, mp.IncrementalRelayFee())
 	if txFee < conflictsFee+minFee {
 		str := fmt.Sprintf("replacement transaction %v has an "+
 			"insufficient absolute fee: needs %v, has %v",
@@ -960,13 +1093,25 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 		return nil, nil, txRuleError(wire.RejectDuplicate, str)
 	}
 
+	// Don't validate new transactions again which were already found to
+	// be invalid under the consensus rules.  Transactions from
+	// disconnected blocks are exempted since they have been valid before.
+	if isNew && mp.cfg.RejectFilter != nil {
+		rejected := mp.cfg.RejectFilter.HaveWitnessHash(tx.WitnessHash())
+		if rejected != nil {
+			str := fmt.Sprintf("transaction %v was previously "+
+				"rejected: %s", txHash, rejected.Reason)
+			return nil, nil, txRuleError(rejected.RejectCode, str)
+		}
+	}
+
 	// Perform preliminary sanity checks on the transaction.  This makes
 	// use of blockchain which contains the invariant rules for what
 	// transactions are allowed into blocks.
 	err := blockchain.CheckTransactionSanity(tx)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, nil, chainRuleError(cerr)
+			return nil, nil, mp.rejectInvalid(tx, chainRuleError(cerr))
 		}
 		return nil, nil, err
 	}
@@ -975,7 +1120,8 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 	if blockchain.IsCoinBase(tx) {
 		str := fmt.Sprintf("transaction %v is an individual coinbase",
 			txHash)
-		return nil, nil, txRuleError(wire.RejectInvalid, str)
+		return nil, nil, mp.rejectInvalid(tx,
+			txRuleError(wire.RejectInvalid, str))
 	}
 
 	// Get the current height of the main chain.  A standalone transaction
@@ -991,7 +1137,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 	if !mp.cfg.Policy.AcceptNonStd {
 		err = CheckTransactionStandard(tx, nextBlockHeight,
 			medianTimePast, mp.cfg.Policy.MinRelayTxFee,
-			mp.cfg.Policy.MaxTxVersion)
+			mp.cfg.Policy.MaxTxVersion, mp.cfg.ChainParams)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
 			// it can be retained.  When not possible, fall back to
@@ -1087,16 +1233,24 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 	txFee, err := blockchain.CheckTransactionInputs(tx, nextBlockHeight,
 		utxoView, mp.cfg.ChainParams)
 	if err != nil {
-		if cerr, ok := err.(blockchain.RuleError); ok {
+		cerr, ok := err.(blockchain.RuleError)
+		if !ok {
+			return nil, nil, err
+		}
+
+		// Spending immature coinbase outputs becomes valid over time,
+		// while the values of the spent outputs never change.
+		if cerr.ErrorCode == blockchain.ErrImmatureSpend {
 			return nil, nil, chainRuleError(cerr)
 		}
-		return nil, nil, err
+		return nil, nil, mp.rejectInvalid(tx, chainRuleError(cerr))
 	}
 
 	// Don't allow transactions with non-standard inputs if the network
 	// parameters forbid their acceptance.
 	if !mp.cfg.Policy.AcceptNonStd {
-		err := checkInputsStandard(tx, utxoView)
+		err := checkInputsStandard(tx, utxoView,
+			mp.cfg.Policy.AcceptAnnex)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
 			// it can be retained.  When not possible, fall back to
@@ -1155,6 +1309,18 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
 	}
 
+	// Outputs below the soft dust limit of the network are relayed, but
+	// only when the transaction pays an additional fee for each of them on
+	// top of the minimum relay fee, which free transactions can't avoid.
+	dustFee := calcSoftDustFee(tx.MsgTx().TxOut, mp.cfg.ChainParams)
+	if dustFee > 0 && txFee < minFee+dustFee {
+		str := fmt.Sprintf("transaction %v has %d fees which is under "+
+			"the required amount of %d for outputs below the soft "+
+			"dust limit of %d", txHash, txFee, minFee+dustFee,
+			mp.cfg.ChainParams.SoftDustLimit)
+		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+
 	// Require that free transactions have sufficient priority to be mined
 	// in the next block.  Transactions which are being added back to the
 	// memory pool from blocks that have been disconnected during a reorg
@@ -1210,10 +1376,27 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 		txscript.StandardVerifyFlags, mp.cfg.SigCache,
 		mp.cfg.HashCache)
 	if err != nil {
-		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, nil, chainRuleError(cerr)
+		cerr, ok := err.(blockchain.RuleError)
+		if !ok {
+			return nil, nil, err
 		}
-		return nil, nil, err
+
+		// The standard verification flags are stricter than the
+		// consensus rules, so the transaction is only invalid when its
+		// scripts also fail to verify with the consensus flags.
+		if mp.cfg.ConsensusScriptFlags != nil {
+			flags, err := mp.cfg.ConsensusScriptFlags()
+			if err != nil {
+				return nil, nil, err
+			}
+			err = blockchain.ValidateTransactionScripts(tx, utxoView,
+				flags, mp.cfg.SigCache, mp.cfg.HashCache)
+			if _, ok := err.(blockchain.RuleError); ok {
+				return nil, nil, mp.rejectInvalid(tx,
+					chainRuleError(cerr))
+			}
+		}
+		return nil, nil, chainRuleError(cerr)
 	}
 
 	// Now that we've deemed the transaction as valid, we can add it to the
@@ -1258,6 +1441,28 @@ func (mp *TxPool) MaybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit bool)
 	return hashes, txD, err
 }
 
+// rejectInvalid records the passed transaction in the reject filter, if any,
+// as invalid under the consensus rules for the reason described by the passed
+// error and returns the error.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) rejectInvalid(tx *btcutil.Tx, err error) error {
+	if mp.cfg.RejectFilter != nil {
+		mp.cfg.RejectFilter.Add(tx, err, mp.cfg.BestHeight())
+	}
+	return err
+}
+
+// notifyTxProcessed invokes the TxProcessed callback, if any, with the passed
+// transaction and the reason it was rejected.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) notifyTxProcessed(tx *btcutil.Tx, err error) {
+	if mp.cfg.TxProcessed != nil {
+		mp.cfg.TxProcessed(tx, err)
+	}
+}
+
 // processOrphans is the internal function which implements the public
 // ProcessOrphans.  See the comment for ProcessOrphans for more details.
 //
@@ -1297,6 +1502,8 @@ func (mp *TxPool) processOrphans(acceptedTx *btcutil.Tx) []*TxDesc {
 				missing, txD, err := mp.maybeAcceptTransaction(
 					tx, true, true, false)
 				if err != nil {
+					mp.notifyTxProcessed(tx, err)
+
 					// The orphan is now invalid, so there
 					// is no way any other orphans which
 					// redeem any of its outputs can be
@@ -1318,6 +1525,7 @@ func (mp *TxPool) processOrphans(acceptedTx *btcutil.Tx) []*TxDesc {
 				// the orphan pool, and add it to the list of
 				// transactions to process so any orphans that
 				// depend on it are handled too.
+				mp.notifyTxProcessed(tx, nil)
 				acceptedTxns = append(acceptedTxns, txD)
 				mp.removeOrphan(tx, false)
 				processList.PushBack(tx)
@@ -1381,10 +1589,13 @@ func (mp *TxPool) ProcessTransaction(tx *btcutil.Tx, allowOrphan, rateLimit bool
 	missingParents, txD, err := mp.maybeAcceptTransaction(tx, true, rateLimit,
 		true)
 	if err != nil {
+		mp.notifyTxProcessed(tx, err)
 		return nil, err
 	}
 
 	if len(missingParents) == 0 {
+		mp.notifyTxProcessed(tx, nil)
+
 		// Accept any orphan transactions that depend on this
 		// transaction (they may no longer be orphans if all inputs
 		// are now available) and repeat for those accepted
@@ -1415,7 +1626,9 @@ func (mp *TxPool) ProcessTransaction(tx *btcutil.Tx, allowOrphan, rateLimit bool
 		str := fmt.Sprintf("orphan transaction %v references "+
 			"outputs of unknown or fully-spent "+
 			"transaction %v", tx.Hash(), missingParents[0])
-		return nil, txRuleError(wire.RejectDuplicate, str)
+		err := txRuleError(wire.RejectDuplicate, str)
+		mp.notifyTxProcessed(tx, err)
+		return nil, err
 	}
 
 	// Potentially add the orphan transaction to the orphan pool.
@@ -1545,6 +1758,15 @@ func (mp *TxPool) LastUpdated() time.Time {
 	return time.Unix(atomic.LoadInt64(&mp.lastUpdated), 0)
 }
 
+// IncrementalRelayFee returns the fee rate in satoshi/kB a replacement
+// transaction must pay for its own size on top of the fees of the transactions
+// it replaces.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) IncrementalRelayFee() btcutil.Amount {
+	return mp.cfg.Policy.IncrementalRelayFee
+}
+
 // New returns a new memory pool for validating and storing standalone
 // transactions until they are mined into a block.
 func New(cfg *Config) *TxPool {
@@ -323,6 +323,7 @@ func newPoolHarness(chainParams *chaincfg.Params) (*poolHarness, []spendableOutp
 				MaxOrphanTxSize:      1000,
 				MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 				MinRelayTxFee:        1000, // 1 Satoshi per byte
+				IncrementalRelayFee:  1000,
 				MaxTxVersion:         1,
 			},
 			ChainParams:      chainParams,
@@ -1645,7 +1646,7 @@ func TestRBF(t *testing.T) {
 			// A transaction cannot replace another if it doesn't
 			// have an absolute greater than the transactions its
 			// replacing _plus_ the replacement transaction's
-			// minimum relay fee.
+			// incremental relay fee.
 			name: "insufficient absolute fee",
 			setup: func(ctx *testContext) (*btcutil.Tx, []*btcutil.Tx) {
 				coinbase := ctx.addCoinbaseTx(1)
@@ -1672,6 +1673,35 @@ func TestRBF(t *testing.T) {
 			},
 			err: "insufficient absolute fee",
 		},
+		{
+			// The fee a replacement transaction must pay for its
+			// own size is determined by the incremental relay fee
+			// rather than the minimum relay fee.
+			name: "insufficient incremental relay fee",
+			setup: func(ctx *testContext) (*btcutil.Tx, []*btcutil.Tx) {
+				ctx.harness.txPool.cfg.Policy.IncrementalRelayFee = 100000
+
+				coinbase := ctx.addCoinbaseTx(1)
+
+				coinbaseOut := txOutToSpendableOut(coinbase, 0)
+				outs := []spendableOutput{coinbaseOut}
+				ctx.addSignedTx(outs, 1, defaultFee, true, false)
+
+				// The replacement pays enough on top of the
+				// original to cover the minimum relay fee for
+				// its size, but not the incremental relay fee.
+				tx, err := ctx.harness.CreateSignedTx(
+					outs, 1, defaultFee+1000, false,
+				)
+				if err != nil {
+					ctx.t.Fatalf("unable to create "+
+						"transaction: %v", err)
+				}
+
+				return tx, nil
+			},
+			err: "insufficient absolute fee",
+		},
 		{
 			// A transaction cannot replace another if it introduces
 			// a new unconfirmed input that was not already in any
@@ -1851,3 +1881,353 @@ func TestRBF(t *testing.T) {
 		}
 	}
 }
+
+// TestExpireTransactions ensures transactions which have been in the pool for
+// longer than the maximum allowed age are evicted along with their descendants
+// and that an expiry notification is sent for each of them.
+func TestExpireTransactions(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	var expired []*TxDesc
+	harness.txPool.Subscribe(func(n *Notification) {
+		if n.Type != NTTxExpired {
+			t.Fatalf("unexpected notification type %v", n.Type)
+		}
+		expired = append(expired, n.Data.(*TxDesc))
+	})
+
+	// Expiry is disabled when no maximum age is configured.
+	if n := harness.txPool.ExpireTransactions(); n != 0 {
+		t.Fatalf("expired %d transactions with expiry disabled", n)
+	}
+	harness.txPool.cfg.Policy.MaxTxAge = time.Hour
+
+	// Create a parent transaction along with a child that spends it and an
+	// unrelated transaction.
+	coinbase := tc.addCoinbaseTx(2)
+	parent := tc.addSignedTx([]spendableOutput{
+		txOutToSpendableOut(coinbase, 0),
+	}, 1, 1000, false, false)
+	child := tc.addSignedTx([]spendableOutput{
+		txOutToSpendableOut(parent, 0),
+	}, 1, 1000, false, false)
+	unrelated := tc.addSignedTx([]spendableOutput{
+		txOutToSpendableOut(coinbase, 1),
+	}, 1, 1000, false, false)
+
+	// Nothing has been in the pool long enough to expire yet.
+	if n := harness.txPool.ExpireTransactions(); n != 0 {
+		t.Fatalf("expired %d transactions before their time", n)
+	}
+
+	// Age the parent past the maximum age.  Both it and its child must be
+	// evicted while the unrelated transaction is kept.
+	harness.txPool.pool[*parent.Hash()].Added = time.Now().Add(-2 * time.Hour)
+	if n := harness.txPool.ExpireTransactions(); n != 2 {
+		t.Fatalf("unexpected number of expired transactions - got %d, "+
+			"want 2", n)
+	}
+	testPoolMembership(tc, parent, false, false)
+	testPoolMembership(tc, child, false, false)
+	testPoolMembership(tc, unrelated, false, true)
+
+	if len(expired) != 2 {
+		t.Fatalf("unexpected number of notifications - got %d, want 2",
+			len(expired))
+	}
+	notified := make(map[chainhash.Hash]struct{})
+	for _, txD := range expired {
+		notified[*txD.Tx.Hash()] = struct{}{}
+	}
+	for _, tx := range []*btcutil.Tx{parent, child} {
+		if _, ok := notified[*tx.Hash()]; !ok {
+			t.Fatalf("missing expiry notification for %v", tx.Hash())
+		}
+	}
+}
+
+// TestSoftDustFee ensures transactions with outputs below the soft dust limit
+// of the network are only accepted when they pay the additional fee for each of
+// those outputs.
+func TestSoftDustFee(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	coinbase := tc.addCoinbaseTx(1)
+	input := txOutToSpendableOut(coinbase, 0)
+	dustValue := harness.chainParams.SoftDustLimit - 1
+
+	// createTx creates a transaction paying an output below the soft dust
+	// limit along with change such that it pays the passed fee.
+	createTx := func(fee int64) *btcutil.Tx {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: input.outPoint,
+			Sequence:         wire.MaxTxInSequenceNum,
+		})
+		tx.AddTxOut(wire.NewTxOut(dustValue, harness.payScript))
+		tx.AddTxOut(wire.NewTxOut(int64(input.amount)-dustValue-fee,
+			harness.payScript))
+		sigScript, err := txscript.SignatureScript(tx, 0,
+			harness.payScript, txscript.SigHashAll, harness.signKey,
+			true)
+		if err != nil {
+			t.Fatalf("unable to sign transaction: %v", err)
+		}
+		tx.TxIn[0].SignatureScript = sigScript
+		return btcutil.NewTx(tx)
+	}
+
+	// Paying the minimum relay fee alone is insufficient.
+	tx := createTx(1000)
+	_, err = harness.txPool.ProcessTransaction(tx, true, false, 0)
+	if err == nil {
+		t.Fatalf("accepted transaction without the soft dust fee")
+	}
+	code, found := extractRejectCode(err)
+	if !found || code != wire.RejectInsufficientFee {
+		t.Fatalf("unexpected reject code - got %v, want %v", code,
+			wire.RejectInsufficientFee)
+	}
+	testPoolMembership(tc, tx, false, false)
+
+	// The transaction is accepted once it pays the soft dust limit for the
+	// output on top of the minimum relay fee.
+	tx = createTx(1000 + harness.chainParams.SoftDustLimit)
+	_, err = harness.txPool.ProcessTransaction(tx, true, false, 0)
+	if err != nil {
+		t.Fatalf("unable to process transaction: %v", err)
+	}
+	testPoolMembership(tc, tx, false, true)
+}
+
+// TestTxProcessedCallback ensures the TxProcessed callback is invoked for all
+// accepted and rejected transactions, including linked orphans, but not for
+// transactions added to the orphan pool.
+func TestTxProcessedCallback(t *testing.T) {
+	t.Parallel()
+
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	processed := make(map[chainhash.Hash]error)
+	harness.txPool.cfg.TxProcessed = func(tx *btcutil.Tx, err error) {
+		processed[*tx.Hash()] = err
+	}
+
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	// Orphans aren't reported until they are linked.
+	_, err = harness.txPool.ProcessTransaction(chainedTxns[1], true, false, 0)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid orphan %v",
+			err)
+	}
+	if len(processed) != 0 {
+		t.Fatalf("orphan was reported as processed")
+	}
+	_, err = harness.txPool.ProcessTransaction(chainedTxns[0], false, false, 0)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid "+
+			"transaction %v", err)
+	}
+	for _, tx := range chainedTxns {
+		err, ok := processed[*tx.Hash()]
+		if !ok || err != nil {
+			t.Fatalf("transaction %v was not reported as accepted",
+				tx.Hash())
+		}
+	}
+
+	// Rejections are reported along with their reason.
+	_, err = harness.txPool.ProcessTransaction(chainedTxns[0], false, false, 0)
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted duplicate transaction")
+	}
+	if processed[*chainedTxns[0].Hash()] != err {
+		t.Fatalf("rejection was not reported - got %v, want %v",
+			processed[*chainedTxns[0].Hash()], err)
+	}
+	testPoolMembership(tc, chainedTxns[0], false, true)
+}
+
+// TestRejectFilter ensures transactions which are invalid under the consensus
+// rules are recorded in the reject filter and refused without validating them
+// again, while transactions which may become valid later are not recorded.
+func TestRejectFilter(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	filter := NewRejectFilter(DefaultMaxRejectedTxs)
+	harness.txPool.cfg.RejectFilter = filter
+	harness.txPool.cfg.ConsensusScriptFlags = func() (txscript.ScriptFlags, error) {
+		return txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures, nil
+	}
+
+	// Create a transaction with an invalid signature by changing an output
+	// after signing it.
+	coinbase := tc.addCoinbaseTx(1)
+	tx, err := harness.CreateSignedTx(
+		[]spendableOutput{txOutToSpendableOut(coinbase, 0)}, 1, 1000,
+		false,
+	)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	tx.MsgTx().TxOut[0].Value--
+	tx = btcutil.NewTx(tx.MsgTx())
+
+	_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted invalid signature")
+	}
+	rejected := filter.Lookup(tx.Hash())
+	if len(rejected) != 1 || rejected[0].RejectCode != wire.RejectInvalid {
+		t.Fatalf("invalid transaction was not recorded: %v", rejected)
+	}
+
+	// The transaction is refused without validating it again.
+	_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+	if err == nil || !strings.Contains(err.Error(), "previously rejected") {
+		t.Fatalf("ProcessTransaction: unexpected error for previously "+
+			"rejected transaction: %v", err)
+	}
+	if filter.Count() != 1 {
+		t.Fatalf("unexpected number of rejected transactions %d",
+			filter.Count())
+	}
+
+	// Script failures are not recorded when the consensus verification
+	// flags are unknown, since they may only violate policy.
+	harness.txPool.cfg.ConsensusScriptFlags = nil
+	tx.MsgTx().TxOut[0].Value--
+	tx = btcutil.NewTx(tx.MsgTx())
+	_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted invalid signature")
+	}
+	if rejected := filter.Lookup(tx.Hash()); len(rejected) != 0 {
+		t.Fatalf("script failure was recorded without consensus flags")
+	}
+
+	// Spending an immature coinbase output is not recorded since it
+	// becomes valid over time.
+	immature, err := harness.CreateCoinbaseTx(harness.chain.BestHeight(), 1)
+	if err != nil {
+		t.Fatalf("unable to create coinbase: %v", err)
+	}
+	harness.chain.utxos.AddTxOuts(immature, harness.chain.BestHeight())
+	tx, err = harness.CreateSignedTx(
+		[]spendableOutput{txOutToSpendableOut(immature, 0)}, 1, 1000,
+		false,
+	)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted immature spend")
+	}
+	if rejected := filter.Lookup(tx.Hash()); len(rejected) != 0 {
+		t.Fatalf("immature spend was recorded")
+	}
+
+	// Transactions without outputs are never valid.
+	msgTx := tx.MsgTx().Copy()
+	msgTx.TxOut = nil
+	tx = btcutil.NewTx(msgTx)
+	_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted transaction without outputs")
+	}
+	if rejected := filter.Lookup(tx.Hash()); len(rejected) != 1 {
+		t.Fatalf("transaction without outputs was not recorded")
+	}
+}
+
+// TestExpiryHandler ensures the expiry handler periodically evicts expired
+// transactions along with their descendants without any blocks being
+// connected.
+func TestExpiryHandler(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+	harness.txPool.cfg.Policy.MaxTxAge = time.Hour
+
+	coinbase := tc.addCoinbaseTx(1)
+	parent := tc.addSignedTx([]spendableOutput{
+		txOutToSpendableOut(coinbase, 0),
+	}, 1, 1000, false, false)
+	child := tc.addSignedTx([]spendableOutput{
+		txOutToSpendableOut(parent, 0),
+	}, 1, 1000, false, false)
+
+	expired := make(chan *TxDesc, 2)
+	harness.txPool.Subscribe(func(n *Notification) {
+		if n.Type == NTTxExpired {
+			expired <- n.Data.(*TxDesc)
+		}
+	})
+
+	// Age the parent past the maximum age.
+	harness.txPool.mtx.Lock()
+	harness.txPool.pool[*parent.Hash()].Added = time.Now().Add(-2 * time.Hour)
+	harness.txPool.mtx.Unlock()
+
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		harness.txPool.ExpiryHandler(10*time.Millisecond, quit)
+		close(done)
+	}()
+
+	notified := make(map[chainhash.Hash]struct{})
+	for i := 0; i < 2; i++ {
+		select {
+		case txD := <-expired:
+			notified[*txD.Tx.Hash()] = struct{}{}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for expiry notification")
+		}
+	}
+	for _, tx := range []*btcutil.Tx{parent, child} {
+		if _, ok := notified[*tx.Hash()]; !ok {
+			t.Fatalf("missing expiry notification for %v", tx.Hash())
+		}
+	}
+	testPoolMembership(tc, parent, false, false)
+	testPoolMembership(tc, child, false, false)
+
+	close(quit)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expiry handler did not stop")
+	}
+}
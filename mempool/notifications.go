@@ -0,0 +1,74 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"fmt"
+)
+
+// NotificationType represents the type of a notification message.
+type NotificationType int
+
+// NotificationCallback is used for a caller to provide a callback for
+// notifications about various memory pool events.
+type NotificationCallback func(*Notification)
+
+// Constants for the type of a notification message.
+const (
+	// NTTxExpired indicates the associated transaction was evicted from
+	// the memory pool because it, or one of the unconfirmed transactions
+	// it depends on, stayed in the pool for longer than the maximum
+	// allowed age.
+	NTTxExpired NotificationType = iota
+)
+
+// notificationTypeStrings is a map of notification types back to their constant
+// names for pretty printing.
+var notificationTypeStrings = map[NotificationType]string{
+	NTTxExpired: "NTTxExpired",
+}
+
+// String returns the NotificationType in human-readable form.
+func (n NotificationType) String() string {
+	if s, ok := notificationTypeStrings[n]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown Notification Type (%d)", int(n))
+}
+
+// Notification defines notification that is sent to the caller via the
+// callbacks registered with Subscribe and consists of a notification type as
+// well as associated data that depends on the type as follows:
+//   - NTTxExpired: *TxDesc
+type Notification struct {
+	Type NotificationType
+	Data interface{}
+}
+
+// Subscribe to memory pool notifications. Registers a callback to be executed
+// when various events take place. See the documentation on Notification and
+// NotificationType for details on the types and contents of notifications.
+//
+// Callbacks are never invoked with the mempool lock held, so they may safely
+// call back into the memory pool.
+func (mp *TxPool) Subscribe(callback NotificationCallback) {
+	mp.notificationsLock.Lock()
+	mp.notifications = append(mp.notifications, callback)
+	mp.notificationsLock.Unlock()
+}
+
+// sendNotification sends a notification with the passed type and data to all
+// subscribed callbacks.
+//
+// This function MUST NOT be called with the mempool lock held.
+func (mp *TxPool) sendNotification(typ NotificationType, data interface{}) {
+	// Generate and send the notification.
+	n := Notification{Type: typ, Data: data}
+	mp.notificationsLock.RLock()
+	for _, callback := range mp.notifications {
+		callback(&n)
+	}
+	mp.notificationsLock.RUnlock()
+}
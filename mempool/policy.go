@@ -10,6 +10,7 @@ import (
 
 	"github.com/dogesuite/doged/blockchain"
 	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/chaincfg"
 	"github.com/dogesuite/doged/txscript"
 	"github.com/dogesuite/doged/wire"
 )
@@ -49,6 +50,11 @@ const (
 	// for larger transactions.  This value is in Satoshi/1000 bytes.
 	DefaultMinRelayTxFee = btcutil.Amount(1000)
 
+	// DefaultIncrementalRelayFee is the default minimum fee rate in
+	// satoshi/1000 bytes a replacement transaction must pay for its own size
+	// on top of the fees of the transactions it replaces.
+	DefaultIncrementalRelayFee = btcutil.Amount(1000)
+
 	// maxStandardMultiSigKeys is the maximum number of public keys allowed
 	// in a multi-signature transaction output script for it to be
 	// considered standard.
@@ -79,6 +85,20 @@ func calcMinRequiredTxRelayFee(serializedSize int64, minRelayTxFee btcutil.Amoun
 	return minFee
 }
 
+// policyScriptClass returns the class of the passed public key script for the
+// purpose of standardness checks.  Unlike txscript.GetScriptClass, witness
+// programs with a version reserved for future soft forks are classified as
+// txscript.WitnessUnknownTy rather than txscript.NonStandardTy.
+func policyScriptClass(pkScript []byte) txscript.ScriptClass {
+	class := txscript.GetScriptClass(pkScript)
+	if class == txscript.NonStandardTy &&
+		txscript.IsWitnessUnknownScript(pkScript) {
+
+		return txscript.WitnessUnknownTy
+	}
+	return class
+}
+
 // checkInputsStandard performs a series of checks on a transaction's inputs
 // to ensure they are "standard".  A standard transaction input within the
 // context of this function is one whose referenced public key script is of a
@@ -89,7 +109,14 @@ func calcMinRequiredTxRelayFee(serializedSize int64, minRelayTxFee btcutil.Amoun
 // not perform those checks because the script engine already does this more
 // accurately and concisely via the txscript.ScriptVerifyCleanStack and
 // txscript.ScriptVerifySigPushOnly flags.
-func checkInputsStandard(tx *btcutil.Tx, utxoView *blockchain.UtxoViewpoint) error {
+//
+// Spends of witness programs with a version reserved for future soft forks
+// are valid by consensus, but they are rejected here so those versions remain
+// available for upgrades.  Likewise, taproot inputs which carry an annex are
+// rejected unless acceptAnnex is set since the annex has no meaning yet.
+func checkInputsStandard(tx *btcutil.Tx, utxoView *blockchain.UtxoViewpoint,
+	acceptAnnex bool) error {
+
 	// NOTE: The reference implementation also does a coinbase check here,
 	// but coinbases have already been rejected prior to calling this
 	// function so no need to recheck.
@@ -100,7 +127,7 @@ func checkInputsStandard(tx *btcutil.Tx, utxoView *blockchain.UtxoViewpoint) err
 		// function.
 		entry := utxoView.LookupEntry(txIn.PreviousOutPoint)
 		originPkScript := entry.PkScript()
-		switch txscript.GetScriptClass(originPkScript) {
+		switch policyScriptClass(originPkScript) {
 		case txscript.ScriptHashTy:
 			numSigOps := txscript.GetPreciseSigOpCount(
 				txIn.SignatureScript, originPkScript, true)
@@ -112,6 +139,22 @@ func checkInputsStandard(tx *btcutil.Tx, utxoView *blockchain.UtxoViewpoint) err
 				return txRuleError(wire.RejectNonstandard, str)
 			}
 
+		case txscript.WitnessV1TaprootTy:
+			if !acceptAnnex && txscript.IsAnnexedWitness(txIn.Witness) {
+				str := fmt.Sprintf("transaction input #%d has a "+
+					"taproot annex which is reserved for "+
+					"future upgrades", i)
+				return txRuleError(wire.RejectNonstandard, str)
+			}
+
+		case txscript.WitnessUnknownTy:
+			version, _, _ := txscript.ExtractWitnessProgramInfo(
+				originPkScript)
+			str := fmt.Sprintf("transaction input #%d spends a "+
+				"witness version %d program which is reserved "+
+				"for future upgrades", i, version)
+			return txRuleError(wire.RejectNonstandard, str)
+
 		case txscript.NonStandardTy:
 			str := fmt.Sprintf("transaction input #%d has a "+
 				"non-standard script form", i)
@@ -126,7 +169,9 @@ func checkInputsStandard(tx *btcutil.Tx, utxoView *blockchain.UtxoViewpoint) err
 // script (public key script) to ensure it is a "standard" public key script.
 // A standard public key script is one that is a recognized form, and for
 // multi-signature scripts, only contains from 1 to maxStandardMultiSigKeys
-// public keys.
+// public keys.  Witness programs with a version reserved for future soft forks
+// are standard so wallets can pay to them ahead of an upgrade, even though
+// spending them is not.
 func checkPkScriptStandard(pkScript []byte, scriptClass txscript.ScriptClass) error {
 	switch scriptClass {
 	case txscript.MultiSigTy:
@@ -172,94 +217,137 @@ func checkPkScriptStandard(pkScript []byte, scriptClass txscript.ScriptClass) er
 	return nil
 }
 
+// TypicalInputSize returns the size, in virtual bytes, of a typical input
+// which spends an output with the passed public key script.  The size is
+// derived from the script type of the output so that the dust threshold
+// reflects what it realistically costs to aggregate the output into a
+// transaction later on.
+//
+// Pay-to-pubkey-hash bytes breakdown:
+//
+//	Input with compressed pubkey (148 bytes):
+//	 36 prev outpoint, 1 script len, 107 script [1 OP_DATA_72, 72 sig,
+//	 1 OP_DATA_33, 33 compressed pubkey], 4 sequence
+//
+// Pay-to-pubkey bytes breakdown:
+//
+//	Input (114 bytes):
+//	 36 prev outpoint, 1 script len, 73 script [1 OP_DATA_72,
+//	 72 sig], 4 sequence
+//
+// Pay-to-witness-pubkey-hash bytes breakdown:
+//
+//	Input (67 bytes as the 107 witness stack is discounted):
+//	 36 prev outpoint, 1 script len, 0 script (not sigScript), 107
+//	 witness stack bytes [1 element length, 33 compressed pubkey,
+//	 element length 72 sig], 4 sequence
+//
+// Pay-to-taproot bytes breakdown:
+//
+//	Key path input (57 bytes as the 66 witness stack is discounted):
+//	 36 prev outpoint, 1 script len, 0 script (not sigScript), 66
+//	 witness stack bytes [1 element count, 1 element length, 64
+//	 schnorr sig], 4 sequence
+//
+// Every input shares a 41 byte preamble required to reference the output
+// being spent and the sequence number of the input.  Scripts whose spending
+// input size can't be anticipated, such as pay-to-script-hash, use the
+// pay-to-pubkey-hash size, or its witness discounted equivalent for witness
+// programs, since those are the most common.
+func TypicalInputSize(pkScript []byte) int {
+	const inputPreambleSize = 41
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyTy:
+		return inputPreambleSize + 73
+
+	case txscript.WitnessV1TaprootTy:
+		return inputPreambleSize + 66/blockchain.WitnessScaleFactor
+	}
+
+	if txscript.IsWitnessProgram(pkScript) {
+		return inputPreambleSize + 107/blockchain.WitnessScaleFactor
+	}
+	return inputPreambleSize + 107
+}
+
 // GetDustThreshold calculates the dust limit for a *wire.TxOut by taking the
-// size of a typical spending transaction and multiplying it by 3 to account
-// for the minimum dust relay fee of 3000sat/kvb.
-func GetDustThreshold(txOut *wire.TxOut) int64 {
+// size of a typical spending transaction and multiplying it by the dust relay
+// fee multiplier of the passed network, which is 3 on Bitcoin to account for
+// its minimum dust relay fee of 3000sat/kvb.
+func GetDustThreshold(txOut *wire.TxOut, chainParams *chaincfg.Params) int64 {
 	// The total serialized size consists of the output and the associated
-	// input script to redeem it.  Since there is no input script
-	// to redeem it yet, use the minimum size of a typical input script.
+	// input script to redeem it.  Since there is no input script to redeem
+	// it yet, use the size of a typical input for the script type as
+	// detailed by TypicalInputSize.
 	//
-	// Pay-to-pubkey-hash bytes breakdown:
+	// Pay-to-pubkey-hash output bytes breakdown:
 	//
 	//  Output to hash (34 bytes):
 	//   8 value, 1 script len, 25 script [1 OP_DUP, 1 OP_HASH_160,
 	//   1 OP_DATA_20, 20 hash, 1 OP_EQUALVERIFY, 1 OP_CHECKSIG]
 	//
-	//  Input with compressed pubkey (148 bytes):
-	//   36 prev outpoint, 1 script len, 107 script [1 OP_DATA_72, 72 sig,
-	//   1 OP_DATA_33, 33 compressed pubkey], 4 sequence
-	//
-	//  Input with uncompressed pubkey (180 bytes):
-	//   36 prev outpoint, 1 script len, 139 script [1 OP_DATA_72, 72 sig,
-	//   1 OP_DATA_65, 65 compressed pubkey], 4 sequence
-	//
-	// Pay-to-pubkey bytes breakdown:
-	//
-	//  Output to compressed pubkey (44 bytes):
-	//   8 value, 1 script len, 35 script [1 OP_DATA_33,
-	//   33 compressed pubkey, 1 OP_CHECKSIG]
-	//
-	//  Output to uncompressed pubkey (76 bytes):
-	//   8 value, 1 script len, 67 script [1 OP_DATA_65, 65 pubkey,
-	//   1 OP_CHECKSIG]
-	//
-	//  Input (114 bytes):
-	//   36 prev outpoint, 1 script len, 73 script [1 OP_DATA_72,
-	//   72 sig], 4 sequence
-	//
-	// Pay-to-witness-pubkey-hash bytes breakdown:
+	// Pay-to-witness-pubkey-hash output bytes breakdown:
 	//
 	//  Output to witness key hash (31 bytes);
 	//   8 value, 1 script len, 22 script [1 OP_0, 1 OP_DATA_20,
 	//   20 bytes hash160]
 	//
-	//  Input (67 bytes as the 107 witness stack is discounted):
-	//   36 prev outpoint, 1 script len, 0 script (not sigScript), 107
-	//   witness stack bytes [1 element length, 33 compressed pubkey,
-	//   element length 72 sig], 4 sequence
-	//
-	//
-	// Theoretically this could examine the script type of the output script
-	// and use a different size for the typical input script size for
-	// pay-to-pubkey vs pay-to-pubkey-hash inputs per the above breakdowns,
-	// but the only combination which is less than the value chosen is
-	// a pay-to-pubkey script with a compressed pubkey, which is not very
-	// common.
-	//
-	// The most common scripts are pay-to-pubkey-hash, and as per the above
-	// breakdown, the minimum size of a p2pkh input script is 148 bytes.  So
-	// that figure is used. If the output being spent is a witness program,
-	// then we apply the witness discount to the size of the signature.
+	// Pay-to-taproot output bytes breakdown:
 	//
-	// The segwit analogue to p2pkh is a p2wkh output. This is the smallest
-	// output possible using the new segwit features. The 107 bytes of
-	// witness data is discounted by a factor of 4, leading to a computed
-	// value of 67 bytes of witness data.
-	//
-	// Both cases share a 41 byte preamble required to reference the input
-	// being spent and the sequence number of the input.
-	totalSize := txOut.SerializeSize() + 41
-	if txscript.IsWitnessProgram(txOut.PkScript) {
-		totalSize += (107 / blockchain.WitnessScaleFactor)
-	} else {
-		totalSize += 107
+	//  Output to taproot key (43 bytes);
+	//   8 value, 1 script len, 34 script [1 OP_1, 1 OP_DATA_32,
+	//   32 bytes output key]
+	totalSize := txOut.SerializeSize() + TypicalInputSize(txOut.PkScript)
+
+	return chainParams.DustRelayFeeMultiplier * int64(totalSize)
+}
+
+// MinNonDustValue returns the smallest value an output paying to the passed
+// public key script can carry without being considered dust by IsDust for the
+// passed minimum transaction relay fee and network.  Unspendable scripts are always dust, so -1 is
+// returned for them.
+func MinNonDustValue(pkScript []byte, minRelayTxFee btcutil.Amount,
+	chainParams *chaincfg.Params) int64 {
+
+	if txscript.IsUnspendable(pkScript) {
+		return -1
 	}
 
-	return 3 * int64(totalSize)
+	// IsDust considers an output dust when value*1000/threshold is less
+	// than the relay fee, so the smallest non-dust value is the ceiling of
+	// relayFee*threshold/1000.
+	threshold := GetDustThreshold(&wire.TxOut{PkScript: pkScript},
+		chainParams)
+	minValue := (int64(minRelayTxFee)*threshold + 999) / 1000
+	if minValue < chainParams.HardDustLimit {
+		minValue = chainParams.HardDustLimit
+	}
+	return minValue
 }
 
 // IsDust returns whether or not the passed transaction output amount is
-// considered dust or not based on the passed minimum transaction relay fee.
-// Dust is defined in terms of the minimum transaction relay fee.  In
+// considered dust or not based on the passed minimum transaction relay fee and
+// network.  Dust is defined in terms of the minimum transaction relay fee.  In
 // particular, if the cost to the network to spend coins is more than 1/3 of the
-// minimum transaction relay fee, it is considered dust.
-func IsDust(txOut *wire.TxOut, minRelayTxFee btcutil.Amount) bool {
+// minimum transaction relay fee, given the Bitcoin dust relay fee multiplier of
+// 3, it is considered dust.  Outputs below the hard dust limit of the network
+// are dust as well.
+func IsDust(txOut *wire.TxOut, minRelayTxFee btcutil.Amount,
+	chainParams *chaincfg.Params) bool {
+
 	// Unspendable outputs are considered dust.
 	if txscript.IsUnspendable(txOut.PkScript) {
 		return true
 	}
 
+	if txOut.Value < chainParams.HardDustLimit {
+		return true
+	}
+	threshold := GetDustThreshold(txOut, chainParams)
+	if threshold == 0 {
+		return false
+	}
+
 	// The output is considered dust if the cost to the network to spend the
 	// coins is more than 1/3 of the minimum free transaction relay fee.
 	// minFreeTxRelayFee is in Satoshi/KB, so multiply by 1000 to
@@ -272,7 +360,20 @@ func IsDust(txOut *wire.TxOut, minRelayTxFee btcutil.Amount) bool {
 	//
 	// The following is equivalent to (value/totalSize) * (1/3) * 1000
 	// without needing to do floating point math.
-	return txOut.Value*1000/GetDustThreshold(txOut) < int64(minRelayTxFee)
+	return txOut.Value*1000/threshold < int64(minRelayTxFee)
+}
+
+// calcSoftDustFee returns the additional fee the passed outputs must pay for
+// being below the soft dust limit of the passed network, which is the soft
+// dust limit itself for each such output as Dogecoin Core charges it.
+func calcSoftDustFee(txOuts []*wire.TxOut, chainParams *chaincfg.Params) int64 {
+	var fee int64
+	for _, txOut := range txOuts {
+		if txOut.Value < chainParams.SoftDustLimit {
+			fee += chainParams.SoftDustLimit
+		}
+	}
+	return fee
 }
 
 // CheckTransactionStandard performs a series of checks on a transaction to
@@ -284,7 +385,7 @@ func IsDust(txOut *wire.TxOut, minRelayTxFee btcutil.Amount) bool {
 // so small it costs more to process them than they are worth).
 func CheckTransactionStandard(tx *btcutil.Tx, height int32,
 	medianTimePast time.Time, minRelayTxFee btcutil.Amount,
-	maxTxVersion int32) error {
+	maxTxVersion int32, chainParams *chaincfg.Params) error {
 
 	// The transaction must be a currently supported version.
 	msgTx := tx.MsgTx()
@@ -339,7 +440,7 @@ func CheckTransactionStandard(tx *btcutil.Tx, height int32,
 	// be "dust" (except when the script is a null data script).
 	numNullDataOutputs := 0
 	for i, txOut := range msgTx.TxOut {
-		scriptClass := txscript.GetScriptClass(txOut.PkScript)
+		scriptClass := policyScriptClass(txOut.PkScript)
 		err := checkPkScriptStandard(txOut.PkScript, scriptClass)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
@@ -358,7 +459,7 @@ func CheckTransactionStandard(tx *btcutil.Tx, height int32,
 		// "dust".
 		if scriptClass == txscript.NullDataTy {
 			numNullDataOutputs++
-		} else if IsDust(txOut, minRelayTxFee) {
+		} else if IsDust(txOut, minRelayTxFee, chainParams) {
 			str := fmt.Sprintf("transaction output %d: payment "+
 				"of %d is dust", i, txOut.Value)
 			return txRuleError(wire.RejectDust, str)
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dogesuite/doged/blockchain"
 	"github.com/dogesuite/doged/btcec/v2"
 	"github.com/dogesuite/doged/btcutil"
 	"github.com/dogesuite/doged/chaincfg"
@@ -183,6 +184,12 @@ func TestCheckPkScriptStandard(t *testing.T) {
 				AddData(pubKeys[0]).AddData(pubKeys[1]),
 			false,
 		},
+		{
+			"future witness version",
+			txscript.NewScriptBuilder().AddOp(txscript.OP_2).
+				AddData(bytes.Repeat([]byte{0x01}, 32)),
+			true,
+		},
 	}
 
 	for _, test := range tests {
@@ -192,7 +199,7 @@ func TestCheckPkScriptStandard(t *testing.T) {
 				"failed: %v", test.name, err)
 			continue
 		}
-		scriptClass := txscript.GetScriptClass(script)
+		scriptClass := policyScriptClass(script)
 		got := checkPkScriptStandard(script, scriptClass)
 		if (test.isStandard && got != nil) ||
 			(!test.isStandard && got == nil) {
@@ -204,6 +211,107 @@ func TestCheckPkScriptStandard(t *testing.T) {
 	}
 }
 
+// TestCheckInputsStandard ensures spends of future witness versions and taproot
+// inputs with an annex are rejected as non-standard while other taproot spends
+// are accepted.
+func TestCheckInputsStandard(t *testing.T) {
+	program := bytes.Repeat([]byte{0x01}, 32)
+	taprootScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_1).AddData(program).Script()
+	if err != nil {
+		t.Fatalf("unable to build taproot script: %v", err)
+	}
+	futureScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_3).AddData(program).Script()
+	if err != nil {
+		t.Fatalf("unable to build witness v3 script: %v", err)
+	}
+
+	// Create a transaction paying to a taproot output and an output with a
+	// future witness version and add it to a utxo view.
+	fundingTx := wire.NewMsgTx(wire.TxVersion)
+	fundingTx.AddTxIn(&wire.TxIn{})
+	fundingTx.AddTxOut(wire.NewTxOut(100000, taprootScript))
+	fundingTx.AddTxOut(wire.NewTxOut(100000, futureScript))
+	utxoView := blockchain.NewUtxoViewpoint()
+	utxoView.AddTxOuts(btcutil.NewTx(fundingTx), 100)
+	fundingHash := fundingTx.TxHash()
+
+	sig := bytes.Repeat([]byte{0x02}, 64)
+	annex := []byte{txscript.TaprootAnnexTag, 0x00}
+	tests := []struct {
+		name        string
+		index       uint32
+		witness     wire.TxWitness
+		acceptAnnex bool
+		isStandard  bool
+	}{
+		{
+			name:       "taproot key spend",
+			index:      0,
+			witness:    wire.TxWitness{sig},
+			isStandard: true,
+		},
+		{
+			name:       "taproot key spend with annex",
+			index:      0,
+			witness:    wire.TxWitness{sig, annex},
+			isStandard: false,
+		},
+		{
+			name:        "taproot key spend with accepted annex",
+			index:       0,
+			witness:     wire.TxWitness{sig, annex},
+			acceptAnnex: true,
+			isStandard:  true,
+		},
+		{
+			name:       "future witness version spend",
+			index:      1,
+			witness:    wire.TxWitness{sig},
+			isStandard: false,
+		},
+		{
+			name:        "future witness version spend accepting annex",
+			index:       1,
+			witness:     wire.TxWitness{sig},
+			acceptAnnex: true,
+			isStandard:  false,
+		},
+	}
+
+	for _, test := range tests {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{
+				Hash:  fundingHash,
+				Index: test.index,
+			},
+			Witness: test.witness,
+		})
+		tx.AddTxOut(wire.NewTxOut(90000, taprootScript))
+
+		err := checkInputsStandard(btcutil.NewTx(tx), utxoView,
+			test.acceptAnnex)
+		if test.isStandard && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !test.isStandard {
+			if err == nil {
+				t.Errorf("%s: expected error", test.name)
+				continue
+			}
+			code, found := extractRejectCode(err)
+			if !found || code != wire.RejectNonstandard {
+				t.Errorf("%s: unexpected reject code - got "+
+					"%v, want %v", test.name, code,
+					wire.RejectNonstandard)
+			}
+		}
+	}
+}
+
 // TestDust tests the IsDust API.
 func TestDust(t *testing.T) {
 	pkScript := []byte{0x76, 0xa9, 0x21, 0x03, 0x2f, 0x7e, 0x43,
@@ -268,7 +376,8 @@ func TestDust(t *testing.T) {
 		},
 	}
 	for _, test := range tests {
-		res := IsDust(&test.txOut, test.relayFee)
+		res := IsDust(&test.txOut, test.relayFee,
+			&chaincfg.RegressionNetParams)
 		if res != test.isDust {
 			t.Fatalf("Dust test '%s' failed: want %v got %v",
 				test.name, test.isDust, res)
@@ -277,6 +386,67 @@ func TestDust(t *testing.T) {
 	}
 }
 
+// TestMinNonDustValue ensures the smallest non-dust value reflects the typical
+// spending input size of each output type and the dust limit of the network.
+func TestMinNonDustValue(t *testing.T) {
+	hash20 := bytes.Repeat([]byte{0x01}, 20)
+	hash32 := bytes.Repeat([]byte{0x01}, 32)
+	pubKey := append([]byte{0x02}, hash32...)
+
+	p2pk := append(append([]byte{txscript.OP_DATA_33}, pubKey...),
+		txscript.OP_CHECKSIG)
+	p2pkh := append(append([]byte{txscript.OP_DUP, txscript.OP_HASH160,
+		txscript.OP_DATA_20}, hash20...), txscript.OP_EQUALVERIFY,
+		txscript.OP_CHECKSIG)
+	p2sh := append(append([]byte{txscript.OP_HASH160,
+		txscript.OP_DATA_20}, hash20...), txscript.OP_EQUAL)
+	p2wpkh := append([]byte{txscript.OP_0, txscript.OP_DATA_20}, hash20...)
+	p2wsh := append([]byte{txscript.OP_0, txscript.OP_DATA_32}, hash32...)
+	p2tr := append([]byte{txscript.OP_1, txscript.OP_DATA_32}, hash32...)
+
+	tests := []struct {
+		name     string
+		pkScript []byte
+		params   *chaincfg.Params
+		want     int64
+	}{
+		{"p2pk", p2pk, &chaincfg.RegressionNetParams, 474},
+		{"p2pkh", p2pkh, &chaincfg.RegressionNetParams, 546},
+		{"p2sh", p2sh, &chaincfg.RegressionNetParams, 540},
+		{"p2wpkh", p2wpkh, &chaincfg.RegressionNetParams, 294},
+		{"p2wsh", p2wsh, &chaincfg.RegressionNetParams, 330},
+		{"p2tr", p2tr, &chaincfg.RegressionNetParams, 300},
+		{"p2pkh with network hard dust limit", p2pkh,
+			&chaincfg.MainNetParams, chaincfg.MainNetParams.HardDustLimit},
+		{"unspendable", []byte{txscript.OP_RETURN},
+			&chaincfg.RegressionNetParams, -1},
+	}
+
+	for _, test := range tests {
+		got := MinNonDustValue(test.pkScript, DefaultMinRelayTxFee,
+			test.params)
+		if got != test.want {
+			t.Errorf("%s: unexpected value - got %d, want %d",
+				test.name, got, test.want)
+			continue
+		}
+		if got <= 0 || test.params.HardDustLimit != 0 {
+			continue
+		}
+
+		// The value must be exactly on the boundary used by IsDust.
+		txOut := wire.TxOut{Value: got, PkScript: test.pkScript}
+		if IsDust(&txOut, DefaultMinRelayTxFee, test.params) {
+			t.Errorf("%s: value %d is dust", test.name, got)
+		}
+		txOut.Value--
+		if !IsDust(&txOut, DefaultMinRelayTxFee, test.params) {
+			t.Errorf("%s: value %d is not dust", test.name,
+				txOut.Value)
+		}
+	}
+}
+
 // TestCheckTransactionStandard tests the CheckTransactionStandard API.
 func TestCheckTransactionStandard(t *testing.T) {
 	// Create some dummy, but otherwise standard, data for transactions.
@@ -450,6 +620,35 @@ func TestCheckTransactionStandard(t *testing.T) {
 			isStandard: false,
 			code:       wire.RejectDust,
 		},
+		{
+			name: "Output below network hard dust limit",
+			tx: wire.MsgTx{
+				Version: 1,
+				TxIn:    []*wire.TxIn{&dummyTxIn},
+				TxOut: []*wire.TxOut{{
+					Value:    chaincfg.MainNetParams.HardDustLimit - 1,
+					PkScript: dummyPkScript,
+				}},
+				LockTime: 0,
+			},
+			height:     300000,
+			isStandard: false,
+			code:       wire.RejectDust,
+		},
+		{
+			name: "Output below network soft dust limit (standard)",
+			tx: wire.MsgTx{
+				Version: 1,
+				TxIn:    []*wire.TxIn{&dummyTxIn},
+				TxOut: []*wire.TxOut{{
+					Value:    chaincfg.MainNetParams.SoftDustLimit - 1,
+					PkScript: dummyPkScript,
+				}},
+				LockTime: 0,
+			},
+			height:     300000,
+			isStandard: true,
+		},
 		{
 			name: "One nulldata output with 0 amount (standard)",
 			tx: wire.MsgTx{
@@ -470,7 +669,8 @@ func TestCheckTransactionStandard(t *testing.T) {
 	for _, test := range tests {
 		// Ensure standardness is as expected.
 		err := CheckTransactionStandard(btcutil.NewTx(&test.tx),
-			test.height, pastMedianTime, DefaultMinRelayTxFee, 1)
+			test.height, pastMedianTime, DefaultMinRelayTxFee, 1,
+			&chaincfg.MainNetParams)
 		if err == nil && test.isStandard {
 			// Test passes since function returned standard for a
 			// transaction which is intended to be standard.
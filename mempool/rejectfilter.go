@@ -0,0 +1,315 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/chaincfg/chainhash"
+	"github.com/dogesuite/doged/wire"
+)
+
+const (
+	// DefaultMaxRejectedTxs is the default maximum number of rejected
+	// transactions the reject filter remembers.
+	DefaultMaxRejectedTxs = 10000
+
+	// maxRejectReasonLen is the maximum length of a reject reason which is
+	// restored from a saved reject filter.
+	maxRejectReasonLen = 1024
+
+	// rejectFilterSaveVersion is the version of the serialized state of a
+	// reject filter.
+	rejectFilterSaveVersion = 1
+)
+
+var (
+	// RejectFilterDatabaseKey is the key that we use to store the reject
+	// filter in the database.
+	RejectFilterDatabaseKey = []byte("rejectfilter")
+)
+
+// RejectedTx describes a transaction which was rejected for being invalid
+// under the consensus rules.
+type RejectedTx struct {
+	// Hash is the hash of the transaction.
+	Hash chainhash.Hash
+
+	// WitnessHash is the hash of the transaction including its witness.
+	// Only the transaction with this witness is known to be invalid, since
+	// anybody can replace the witness of a valid transaction without
+	// changing its hash.
+	WitnessHash chainhash.Hash
+
+	// RejectCode and Reason describe why the transaction was rejected.
+	RejectCode wire.RejectCode
+	Reason     string
+
+	// Time is when the transaction was rejected.
+	Time time.Time
+
+	// Height is the height of the best chain when the transaction was
+	// rejected.
+	Height int32
+}
+
+// serialize writes the rejected transaction to the passed writer.
+func (r *RejectedTx) serialize(w io.Writer) error {
+	if _, err := w.Write(r.Hash[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(r.WitnessHash[:]); err != nil {
+		return err
+	}
+	err := binary.Write(w, binary.BigEndian, uint8(r.RejectCode))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.BigEndian, r.Time.Unix())
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.BigEndian, r.Height)
+	if err != nil {
+		return err
+	}
+	return wire.WriteVarString(w, 0, r.Reason)
+}
+
+// deserializeRejectedTx reads a rejected transaction written by serialize
+// from the passed reader.
+func deserializeRejectedTx(r io.Reader) (*RejectedTx, error) {
+	var rejected RejectedTx
+	if _, err := io.ReadFull(r, rejected.Hash[:]); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, rejected.WitnessHash[:]); err != nil {
+		return nil, err
+	}
+	var code uint8
+	if err := binary.Read(r, binary.BigEndian, &code); err != nil {
+		return nil, err
+	}
+	rejected.RejectCode = wire.RejectCode(code)
+	var unixTime int64
+	if err := binary.Read(r, binary.BigEndian, &unixTime); err != nil {
+		return nil, err
+	}
+	rejected.Time = time.Unix(unixTime, 0)
+	if err := binary.Read(r, binary.BigEndian, &rejected.Height); err != nil {
+		return nil, err
+	}
+	reason, err := wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(reason) > maxRejectReasonLen {
+		return nil, fmt.Errorf("reject reason of %d bytes exceeds the "+
+			"maximum of %d", len(reason), maxRejectReasonLen)
+	}
+	rejected.Reason = reason
+	return &rejected, nil
+}
+
+// RejectFilter remembers a bounded number of the most recent transactions
+// which were rejected for being invalid under the consensus rules.  Its state
+// can be saved and restored across restarts, so the mempool can refuse these
+// transactions without validating them again and the reason they were
+// rejected can be looked up long after the fact.
+//
+// Transactions rejected due to policy, such as insufficient fees, or the
+// state of the chain or mempool are never recorded since they may be accepted
+// at a later time.
+type RejectFilter struct {
+	mtx        sync.RWMutex
+	maxEntries int
+
+	// entries houses the rejected transactions keyed by their witness
+	// hash, while hashes indexes their witness hashes by their hash.
+	entries map[chainhash.Hash]*RejectedTx
+	hashes  map[chainhash.Hash][]chainhash.Hash
+
+	// order houses the witness hashes of the rejected transactions, from
+	// the oldest to the most recent, to evict the oldest first.
+	order []chainhash.Hash
+}
+
+// NewRejectFilter returns a new empty reject filter which remembers up to the
+// passed number of rejected transactions.
+func NewRejectFilter(maxEntries int) *RejectFilter {
+	return &RejectFilter{
+		maxEntries: maxEntries,
+		entries:    make(map[chainhash.Hash]*RejectedTx),
+		hashes:     make(map[chainhash.Hash][]chainhash.Hash),
+	}
+}
+
+// add records the passed rejected transaction, evicting the oldest one when
+// the filter is full.  It does nothing when the transaction is already
+// recorded.
+//
+// This function MUST be called with the filter lock held (for writes).
+func (f *RejectFilter) add(rejected *RejectedTx) {
+	if f.maxEntries <= 0 {
+		return
+	}
+	if _, ok := f.entries[rejected.WitnessHash]; ok {
+		return
+	}
+
+	for len(f.order) >= f.maxEntries {
+		f.evict(f.order[0])
+		f.order = f.order[1:]
+	}
+
+	f.entries[rejected.WitnessHash] = rejected
+	f.hashes[rejected.Hash] = append(f.hashes[rejected.Hash],
+		rejected.WitnessHash)
+	f.order = append(f.order, rejected.WitnessHash)
+}
+
+// evict removes the rejected transaction with the passed witness hash from the
+// entries and the hash index, but not from the eviction order.
+//
+// This function MUST be called with the filter lock held (for writes).
+func (f *RejectFilter) evict(witnessHash chainhash.Hash) {
+	rejected, ok := f.entries[witnessHash]
+	if !ok {
+		return
+	}
+	delete(f.entries, witnessHash)
+
+	witnessHashes := f.hashes[rejected.Hash]
+	for i := range witnessHashes {
+		if witnessHashes[i] == witnessHash {
+			witnessHashes = append(witnessHashes[:i],
+				witnessHashes[i+1:]...)
+			break
+		}
+	}
+	if len(witnessHashes) == 0 {
+		delete(f.hashes, rejected.Hash)
+	} else {
+		f.hashes[rejected.Hash] = witnessHashes
+	}
+}
+
+// Add records the passed transaction as rejected for the reason described by
+// the passed error at the passed best chain height.
+//
+// This function is safe for concurrent access.
+func (f *RejectFilter) Add(tx *btcutil.Tx, err error, height int32) {
+	code, reason := ErrToRejectErr(err)
+
+	f.mtx.Lock()
+	f.add(&RejectedTx{
+		Hash:        *tx.Hash(),
+		WitnessHash: *tx.WitnessHash(),
+		RejectCode:  code,
+		Reason:      reason,
+		Time:        time.Unix(time.Now().Unix(), 0),
+		Height:      height,
+	})
+	f.mtx.Unlock()
+}
+
+// HaveWitnessHash returns the rejected transaction with the passed witness
+// hash, or nil when the filter doesn't know of it.
+//
+// This function is safe for concurrent access.
+func (f *RejectFilter) HaveWitnessHash(witnessHash *chainhash.Hash) *RejectedTx {
+	f.mtx.RLock()
+	rejected := f.entries[*witnessHash]
+	f.mtx.RUnlock()
+
+	return rejected
+}
+
+// Lookup returns the rejected transactions with the passed hash, which is
+// either the hash or the witness hash of the transaction, ordered from the
+// oldest to the most recent rejection.  Multiple transactions are returned
+// when transactions which only differ in their witnesses were rejected.
+//
+// This function is safe for concurrent access.
+func (f *RejectFilter) Lookup(hash *chainhash.Hash) []*RejectedTx {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	if rejected, ok := f.entries[*hash]; ok {
+		return []*RejectedTx{rejected}
+	}
+
+	witnessHashes := f.hashes[*hash]
+	rejected := make([]*RejectedTx, 0, len(witnessHashes))
+	for _, witnessHash := range witnessHashes {
+		rejected = append(rejected, f.entries[witnessHash])
+	}
+	return rejected
+}
+
+// Count returns the number of rejected transactions in the filter.
+//
+// This function is safe for concurrent access.
+func (f *RejectFilter) Count() int {
+	f.mtx.RLock()
+	count := len(f.entries)
+	f.mtx.RUnlock()
+
+	return count
+}
+
+// Save returns the serialized state of the filter which can be restored with
+// RestoreRejectFilter.
+//
+// This function is safe for concurrent access.
+func (f *RejectFilter) Save() []byte {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(rejectFilterSaveVersion))
+	binary.Write(&buf, binary.BigEndian, uint32(len(f.order)))
+	for _, witnessHash := range f.order {
+		f.entries[witnessHash].serialize(&buf)
+	}
+	return buf.Bytes()
+}
+
+// RestoreRejectFilter returns a reject filter which remembers up to the passed
+// number of rejected transactions from the serialized state previously
+// returned by Save.  When the state houses more transactions, only the most
+// recent ones are kept.
+func RestoreRejectFilter(data []byte, maxEntries int) (*RejectFilter, error) {
+	r := bytes.NewReader(data)
+
+	var version uint32
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return nil, err
+	}
+	if version != rejectFilterSaveVersion {
+		return nil, fmt.Errorf("incorrect version: expected %d found %d",
+			rejectFilterSaveVersion, version)
+	}
+
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	f := NewRejectFilter(maxEntries)
+	for i := uint32(0); i < count; i++ {
+		rejected, err := deserializeRejectedTx(r)
+		if err != nil {
+			return nil, err
+		}
+		f.add(rejected)
+	}
+	return f, nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/wire"
+)
+
+// rejectFilterTestTx returns a transaction with a witness whose output value
+// and witness are determined by the passed values.
+func rejectFilterTestTx(value int64, witness byte) *btcutil.Tx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: 1},
+		Witness:          wire.TxWitness{{witness}},
+	})
+	tx.AddTxOut(&wire.TxOut{Value: value})
+	return btcutil.NewTx(tx)
+}
+
+// TestRejectFilterLookup ensures rejected transactions can be looked up by
+// their hash and witness hash and the oldest ones are evicted first.
+func TestRejectFilterLookup(t *testing.T) {
+	t.Parallel()
+
+	filter := NewRejectFilter(3)
+	rejectErr := txRuleError(wire.RejectInvalid, "invalid")
+
+	// Transactions which only differ in their witness share a hash.
+	tx1 := rejectFilterTestTx(1, 1)
+	tx1Malleated := rejectFilterTestTx(1, 2)
+	filter.Add(tx1, rejectErr, 100)
+	filter.Add(tx1Malleated, rejectErr, 101)
+	filter.Add(tx1Malleated, rejectErr, 102)
+	if filter.Count() != 2 {
+		t.Fatalf("unexpected count %d", filter.Count())
+	}
+	rejected := filter.Lookup(tx1.Hash())
+	if len(rejected) != 2 || rejected[0].WitnessHash != *tx1.WitnessHash() ||
+		rejected[1].WitnessHash != *tx1Malleated.WitnessHash() {
+
+		t.Fatalf("unexpected rejections for hash: %v", rejected)
+	}
+	rejected = filter.Lookup(tx1Malleated.WitnessHash())
+	if len(rejected) != 1 || rejected[0].Height != 101 ||
+		rejected[0].RejectCode != wire.RejectInvalid ||
+		rejected[0].Reason != "invalid" {
+
+		t.Fatalf("unexpected rejections for witness hash: %v", rejected)
+	}
+
+	// Adding more transactions than the filter holds evicts the oldest.
+	tx2 := rejectFilterTestTx(2, 1)
+	tx3 := rejectFilterTestTx(3, 1)
+	filter.Add(tx2, rejectErr, 103)
+	filter.Add(tx3, rejectErr, 104)
+	if filter.HaveWitnessHash(tx1.WitnessHash()) != nil {
+		t.Fatalf("oldest transaction was not evicted")
+	}
+	rejected = filter.Lookup(tx1.Hash())
+	if len(rejected) != 1 || rejected[0].WitnessHash != *tx1Malleated.WitnessHash() {
+		t.Fatalf("unexpected rejections after eviction: %v", rejected)
+	}
+	if filter.Count() != 3 {
+		t.Fatalf("unexpected count %d", filter.Count())
+	}
+}
+
+// TestRejectFilterSaveRestore ensures the state of a reject filter is restored
+// from its serialized form.
+func TestRejectFilterSaveRestore(t *testing.T) {
+	t.Parallel()
+
+	filter := NewRejectFilter(DefaultMaxRejectedTxs)
+	rejectErr := txRuleError(wire.RejectInvalid, "invalid")
+	var txns []*btcutil.Tx
+	for i := int64(0); i < 3; i++ {
+		tx := rejectFilterTestTx(i, 1)
+		filter.Add(tx, rejectErr, int32(i))
+		txns = append(txns, tx)
+	}
+
+	restored, err := RestoreRejectFilter(filter.Save(),
+		DefaultMaxRejectedTxs)
+	if err != nil {
+		t.Fatalf("RestoreRejectFilter: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(restored, filter) {
+		t.Fatalf("restored filter doesn't match the saved filter")
+	}
+
+	// Only the most recent transactions are kept when the restored filter
+	// holds fewer transactions.
+	restored, err = RestoreRejectFilter(filter.Save(), 2)
+	if err != nil {
+		t.Fatalf("RestoreRejectFilter: unexpected error: %v", err)
+	}
+	if restored.Count() != 2 ||
+		restored.HaveWitnessHash(txns[0].WitnessHash()) != nil {
+
+		t.Fatalf("restored filter kept the oldest transaction")
+	}
+
+	// Truncated or unknown states are rejected.
+	data := filter.Save()
+	if _, err := RestoreRejectFilter(data[:len(data)-1], 2); err == nil {
+		t.Fatalf("RestoreRejectFilter: expected error for truncated state")
+	}
+	data[3] = 0xff
+	if _, err := RestoreRejectFilter(data, 2); err == nil {
+		t.Fatalf("RestoreRejectFilter: expected error for unknown version")
+	}
+}
@@ -1431,6 +1431,12 @@ func (sm *SyncManager) handleBlockchainNotification(notification *blockchain.Not
 			sm.peerNotifier.AnnounceNewTransactions(acceptedTxs)
 		}
 
+		// Evict any transactions which have been sitting in the
+		// transaction pool for too long without being mined now that
+		// the pool has been updated for the new block.  The server
+		// also does so periodically while no blocks are connected.
+		sm.txMemPool.ExpireTransactions()
+
 		// Register block with the fee estimator, if it exists.
 		if sm.feeEstimator != nil {
 			err := sm.feeEstimator.RegisterBlock(block)
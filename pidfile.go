@@ -0,0 +1,127 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// pidFile is a file which houses the id of the running process.  The file is
+// locked for as long as the process runs, which distinguishes it from a stale
+// pid file left behind by a process which didn't exit cleanly.
+type pidFile struct {
+	path string
+	file *os.File
+
+	// stalePid is the process id the pid file housed when it was stale, or
+	// empty when there was no pid file.
+	stalePid string
+}
+
+// lockPidFile opens and locks the pid file at the passed path, creating it when
+// needed.
+func lockPidFile(path string) (*os.File, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := lockFile(f); err != nil {
+			f.Close()
+
+			// Identify the running process when its id can be
+			// read, which is not the case on platforms with
+			// mandatory locking.
+			if pid, err := ioutil.ReadFile(path); err == nil &&
+				len(bytes.TrimSpace(pid)) != 0 {
+
+				return nil, fmt.Errorf("pid file %s is in use by "+
+					"running process %s", path,
+					bytes.TrimSpace(pid))
+			}
+			return nil, fmt.Errorf("pid file %s is in use by another "+
+				"running process: %v", path, err)
+		}
+
+		// The process which held the lock may have removed the file
+		// before releasing it, in which case the file at the path must
+		// be locked instead.
+		opened, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		current, err := os.Stat(path)
+		if err == nil && os.SameFile(opened, current) {
+			return f, nil
+		}
+		f.Close()
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+}
+
+// createPidFile writes the id of the current process to the file at the passed
+// path, creating its directory when needed, and locks it until the returned pid
+// file is closed or the process exits.  A stale pid file is replaced, while an
+// error is returned when the file is locked by another running process.
+func createPidFile(path string) (*pidFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	f, err := lockPidFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stalePid, err := ioutil.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	pid := []byte(strconv.Itoa(os.Getpid()) + "\n")
+	if err := f.Truncate(0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.WriteAt(pid, 0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &pidFile{
+		path:     path,
+		file:     f,
+		stalePid: string(bytes.TrimSpace(stalePid)),
+	}, nil
+}
+
+// Close removes the pid file and releases its lock.
+func (p *pidFile) Close() error {
+	// Remove the file while it is still locked so another process can't
+	// lock it in between.  Windows doesn't allow removing open files
+	// though, so it is removed once closed there.
+	if runtime.GOOS != "windows" {
+		err := os.Remove(p.path)
+		p.file.Close()
+		return err
+	}
+
+	p.file.Close()
+	return os.Remove(p.path)
+}
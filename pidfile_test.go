@@ -0,0 +1,71 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// TestPidFile ensures a pid file houses the id of the process, can only be
+// created once at a time, replaces stale pid files and is removed when closed.
+func TestPidFile(t *testing.T) {
+	switch runtime.GOOS {
+	case "darwin", "dragonfly", "freebsd", "linux", "netbsd", "openbsd",
+		"windows":
+	default:
+		t.Skip("file locking is not supported")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "btcd")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Leave a stale pid file behind which must be replaced.
+	path := filepath.Join(tmpDir, "run", "btcd.pid")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("Failed creating pid file directory: %v", err)
+	}
+	if err := ioutil.WriteFile(path, []byte("123456789\n"), 0644); err != nil {
+		t.Fatalf("Failed writing stale pid file: %v", err)
+	}
+
+	pidFile, err := createPidFile(path)
+	if err != nil {
+		t.Fatalf("Unable to create pid file: %v", err)
+	}
+	if pidFile.stalePid != "123456789" {
+		t.Fatalf("Unexpected stale pid %q", pidFile.stalePid)
+	}
+	if _, err := createPidFile(path); err == nil {
+		t.Fatal("Created pid file which is in use")
+	}
+
+	// The pid file can't be read while it is locked on Windows.
+	if runtime.GOOS != "windows" {
+		pid, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Unable to read pid file: %v", err)
+		}
+		want := strconv.Itoa(os.Getpid()) + "\n"
+		if string(pid) != want {
+			t.Fatalf("Unexpected pid file contents - got %q, want %q",
+				pid, want)
+		}
+	}
+
+	if err := pidFile.Close(); err != nil {
+		t.Fatalf("Unable to close pid file: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Pid file was not removed: %v", err)
+	}
+}
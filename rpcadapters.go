@@ -7,6 +7,7 @@ package main
 import (
 	"sync/atomic"
 
+	"github.com/dogesuite/doged/addrmgr"
 	"github.com/dogesuite/doged/blockchain"
 	"github.com/dogesuite/doged/chaincfg/chainhash"
 	"github.com/dogesuite/doged/mempool"
@@ -232,6 +233,23 @@ func (cm *rpcConnManager) NodeAddresses() []*wire.NetAddressV2 {
 	return cm.server.addrManager.AddressCache()
 }
 
+// LocalAddresses returns the local addresses advertised to peers along with
+// their scores.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) LocalAddresses() []addrmgr.LocalAddress {
+	return cm.server.addrManager.LocalAddresses()
+}
+
+// LocalServices returns the services advertised to peers.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) LocalServices() wire.ServiceFlag {
+	return cm.server.services
+}
+
 // rpcSyncMgr provides a block manager for use with the RPC server and
 // implements the rpcserverSyncManager interface.
 type rpcSyncMgr struct {
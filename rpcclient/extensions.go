@@ -18,6 +18,61 @@ import (
 	"github.com/dogesuite/doged/btcutil"
 )
 
+// FutureBumpFeePSBTResult is a future promise to deliver the result of a
+// BumpFeePSBTAsync RPC invocation (or an applicable error).
+type FutureBumpFeePSBTResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// unsigned packet raising the fee rate of the transaction along with the fees
+// involved.
+func (r FutureBumpFeePSBTResult) Receive() (*btcjson.BumpFeePSBTResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a bumpfeepsbt result object.
+	var bumpResult btcjson.BumpFeePSBTResult
+	err = json.Unmarshal(res, &bumpResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bumpResult, nil
+}
+
+// BumpFeePSBTAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See BumpFeePSBT for the blocking version and more details.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) BumpFeePSBTAsync(txHash *chainhash.Hash, method string,
+	feeRate float64, outputIndex *int) FutureBumpFeePSBTResult {
+
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+
+	cmd := btcjson.NewBumpFeePSBTCmd(hash, &method, &feeRate, outputIndex)
+	return c.SendCmd(cmd)
+}
+
+// BumpFeePSBT returns an unsigned packet which raises the fee rate of the
+// passed transaction in the memory pool of the server to the passed fee rate
+// in BTC/kvB.  The method is either "rbf" to replace the transaction, funding
+// the fee from the change output at the passed index, or "cpfp" to spend the
+// output at the passed index in a child transaction.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) BumpFeePSBT(txHash *chainhash.Hash, method string,
+	feeRate float64, outputIndex *int) (*btcjson.BumpFeePSBTResult, error) {
+
+	return c.BumpFeePSBTAsync(txHash, method, feeRate, outputIndex).Receive()
+}
+
 // FutureDebugLevelResult is a future promise to deliver the result of a
 // DebugLevelAsync RPC invocation (or an applicable error).
 type FutureDebugLevelResult chan *Response
@@ -235,6 +290,48 @@ func (c *Client) GetCurrentNet() (wire.BitcoinNet, error) {
 	return c.GetCurrentNetAsync().Receive()
 }
 
+// FutureGetDustLimitsResult is a future promise to deliver the result of a
+// GetDustLimitsAsync RPC invocation (or an applicable error).
+type FutureGetDustLimitsResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the dust
+// thresholds of the common output types.
+func (r FutureGetDustLimitsResult) Receive() (*btcjson.GetDustLimitsResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getdustlimits result object.
+	var dustLimits btcjson.GetDustLimitsResult
+	err = json.Unmarshal(res, &dustLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dustLimits, nil
+}
+
+// GetDustLimitsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetDustLimits for the blocking version and more details.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GetDustLimitsAsync() FutureGetDustLimitsResult {
+	cmd := btcjson.NewGetDustLimitsCmd()
+	return c.SendCmd(cmd)
+}
+
+// GetDustLimits returns the smallest output values which the server does not
+// consider dust for the common output types.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GetDustLimits() (*btcjson.GetDustLimitsResult, error) {
+	return c.GetDustLimitsAsync().Receive()
+}
+
 // FutureGetHeadersResult is a future promise to deliver the result of a
 // getheaders RPC invocation (or an applicable error).
 //
@@ -305,6 +402,55 @@ func (c *Client) GetHeaders(blockLocators []chainhash.Hash, hashStop *chainhash.
 	return c.GetHeadersAsync(blockLocators, hashStop).Receive()
 }
 
+// FutureGetTxRejectionInfoResult is a future promise to deliver the result of
+// a GetTxRejectionInfoAsync RPC invocation (or an applicable error).
+type FutureGetTxRejectionInfoResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// rejected transactions with the requested hash.
+func (r FutureGetTxRejectionInfoResult) Receive() ([]btcjson.GetTxRejectionInfoResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of gettxrejectioninfo result objects.
+	var rejected []btcjson.GetTxRejectionInfoResult
+	err = json.Unmarshal(res, &rejected)
+	if err != nil {
+		return nil, err
+	}
+
+	return rejected, nil
+}
+
+// GetTxRejectionInfoAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetTxRejectionInfo for the blocking version and more details.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GetTxRejectionInfoAsync(txHash *chainhash.Hash) FutureGetTxRejectionInfoResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+
+	cmd := btcjson.NewGetTxRejectionInfoCmd(hash)
+	return c.SendCmd(cmd)
+}
+
+// GetTxRejectionInfo returns why the transaction with the passed hash, with or
+// without its witness, was rejected by the server for being invalid under the
+// consensus rules.  Several transactions are returned when transactions which
+// only differ in their witnesses were rejected.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GetTxRejectionInfo(txHash *chainhash.Hash) ([]btcjson.GetTxRejectionInfoResult, error) {
+	return c.GetTxRejectionInfoAsync(txHash).Receive()
+}
+
 // FutureExportWatchingWalletResult is a future promise to deliver the result of
 // an ExportWatchingWalletAsync RPC invocation (or an applicable error).
 type FutureExportWatchingWalletResult chan *Response
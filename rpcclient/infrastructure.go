@@ -7,6 +7,7 @@ package rpcclient
 import (
 	"bytes"
 	"container/list"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -100,6 +101,10 @@ type jsonRequest struct {
 	cmd            interface{}
 	marshalledJSON []byte
 	responseChan   chan *Response
+
+	// idempotent indicates whether the request can safely be repeated
+	// when retrying it after a failure.
+	idempotent bool
 }
 
 // BackendVersion represents the version of the backend the client is currently
@@ -770,40 +775,83 @@ func (c *Client) handleSendPostMessage(jReq *jsonRequest,
 	}
 	url := protocol + "://" + c.config.Host
 
+	// Refuse to send requests that exceed the configured size limit.
+	maxRequestSize := c.config.MaxRequestSize
+	if maxRequestSize > 0 && int64(len(jReq.marshalledJSON)) > maxRequestSize {
+		jReq.responseChan <- &Response{err: ErrRequestTooLarge}
+		return
+	}
+
+	// Abort any in-flight attempt when the client is shut down so stuck
+	// requests don't block the shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
 	var (
 		err, lastErr error
 		backoff      time.Duration
 		httpResponse *http.Response
 	)
 
-	tries := 10
+	policy := c.config.retryPolicy(jReq.method)
+	tries := policy.attempts()
 	for i := 0; i < tries; i++ {
 		var httpReq *http.Request
 
 		bodyReader := bytes.NewReader(jReq.marshalledJSON)
-		httpReq, err = http.NewRequest("POST", url, bodyReader)
+		httpReq, err = http.NewRequestWithContext(ctx, "POST", url,
+			bodyReader)
 		if err != nil {
 			jReq.responseChan <- &Response{result: nil, err: err}
 			return
 		}
-		httpReq.Close = true
+		httpReq.Close = c.config.DisableKeepAlive
 		httpReq.Header.Set("Content-Type", "application/json")
 		for key, value := range c.config.ExtraHeaders {
 			httpReq.Header.Set(key, value)
 		}
 
 		// Configure basic access authorization.
-		user, pass, err := c.config.getAuth()
-		if err != nil {
-			jReq.responseChan <- &Response{result: nil, err: err}
+		user, pass, authErr := c.config.getAuth()
+		if authErr != nil {
+			jReq.responseChan <- &Response{result: nil, err: authErr}
 			return
 		}
 		httpReq.SetBasicAuth(user, pass)
 
 		httpResponse, err = c.httpClient.Do(httpReq)
 
+		// Attempts aborted due to the client shutting down must not be
+		// retried.
+		if err != nil && ctx.Err() != nil {
+			jReq.responseChan <- &Response{err: ErrClientShutdown}
+			return
+		}
+
 		// Quit the retry loop on success or if we can't retry anymore.
-		if err == nil || i == tries-1 {
+		if i == tries-1 {
+			break
+		}
+		if err == nil {
+			if !policy.shouldRetryStatus(httpResponse.StatusCode,
+				jReq.idempotent) {
+
+				break
+			}
+
+			// Discard the response so the underlying connection
+			// can be reused for the next attempt.
+			io.Copy(ioutil.Discard, httpResponse.Body)
+			httpResponse.Body.Close()
+			err = fmt.Errorf("status code: %d", httpResponse.StatusCode)
+		} else if !policy.shouldRetryErr(err, jReq.idempotent) {
 			break
 		}
 
@@ -814,10 +862,7 @@ func (c *Client) handleSendPostMessage(jReq *jsonRequest,
 		lastErr = err
 
 		// Backoff sleep otherwise.
-		backoff = requestRetryInterval * time.Duration(i+1)
-		if backoff > time.Minute {
-			backoff = time.Minute
-		}
+		backoff = policy.backoff(i)
 		log.Debugf("Failed command [%s] with id %d attempt %d."+
 			" Retrying in %v... \n", jReq.method, jReq.id,
 			i, backoff)
@@ -826,6 +871,7 @@ func (c *Client) handleSendPostMessage(jReq *jsonRequest,
 		case <-time.After(backoff):
 
 		case <-shutdown:
+			jReq.responseChan <- &Response{err: ErrClientShutdown}
 			return
 		}
 	}
@@ -845,14 +891,24 @@ func (c *Client) handleSendPostMessage(jReq *jsonRequest,
 		return
 	}
 
-	// Read the raw bytes and close the response.
-	respBytes, err := ioutil.ReadAll(httpResponse.Body)
+	// Read the raw bytes and close the response.  One extra byte beyond
+	// the configured limit is read in order to detect oversized responses.
+	var body io.Reader = httpResponse.Body
+	maxResponseSize := c.config.MaxResponseSize
+	if maxResponseSize > 0 {
+		body = io.LimitReader(body, maxResponseSize+1)
+	}
+	respBytes, err := ioutil.ReadAll(body)
 	httpResponse.Body.Close()
 	if err != nil {
 		err = fmt.Errorf("error reading json reply: %v", err)
 		jReq.responseChan <- &Response{err: err}
 		return
 	}
+	if maxResponseSize > 0 && int64(len(respBytes)) > maxResponseSize {
+		jReq.responseChan <- &Response{err: ErrResponseTooLarge}
+		return
+	}
 
 	// Try to unmarshal the response as a regular JSON-RPC response.
 	var resp rawResponse
@@ -1025,6 +1081,7 @@ func (c *Client) SendCmd(cmd interface{}) chan *Response {
 		cmd:            cmd,
 		marshalledJSON: marshalledJSON,
 		responseChan:   responseChan,
+		idempotent:     IsIdempotentMethod(method),
 	}
 
 	c.sendRequest(jReq)
@@ -1165,8 +1222,14 @@ func (c *Client) start() {
 	// Start the I/O processing handlers depending on whether the client is
 	// in HTTP POST mode or the default websocket mode.
 	if c.config.HTTPPostMode {
-		c.wg.Add(1)
-		go c.sendPostHandler()
+		concurrency := c.config.PostConcurrency
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		c.wg.Add(concurrency)
+		for i := 0; i < concurrency; i++ {
+			go c.sendPostHandler()
+		}
 	} else {
 		c.wg.Add(3)
 		go func() {
@@ -1271,6 +1334,68 @@ type ConnConfig struct {
 	// EnableBCInfoHacks is an option provided to enable compatibility hacks
 	// when connecting to blockchain.info RPC server
 	EnableBCInfoHacks bool
+
+	// The following fields only apply when running in HTTP POST mode.
+
+	// PostConcurrency is the number of requests that may be in flight
+	// concurrently.  Values less than one are treated as one, which
+	// processes requests sequentially in the order they were issued.
+	PostConcurrency int
+
+	// DialTimeout is the maximum amount of time to wait for a connection
+	// to the server to be established.  A value of zero means no timeout.
+	DialTimeout time.Duration
+
+	// RequestTimeout is the maximum amount of time a single attempt of a
+	// request may take, including reading the response.  A value of zero
+	// means no timeout.
+	RequestTimeout time.Duration
+
+	// ResponseHeaderTimeout is the maximum amount of time to wait for the
+	// server's response headers after fully writing a request.  A value of
+	// zero means no timeout.
+	ResponseHeaderTimeout time.Duration
+
+	// DisableKeepAlive specifies that a new connection should be used for
+	// every request instead of reusing idle connections from the pool.
+	DisableKeepAlive bool
+
+	// KeepAlive is the interval between TCP keep-alive probes on
+	// connections to the server.  A value of zero uses the operating system
+	// default and a negative value disables keep-alive probes.
+	KeepAlive time.Duration
+
+	// MaxIdleConns is the maximum number of idle connections to the server
+	// that are kept in the pool for reuse.  A value of zero uses the
+	// default of the net/http package.
+	MaxIdleConns int
+
+	// MaxConns is the maximum number of connections to the server,
+	// including those that are in use.  A value of zero means no limit.
+	MaxConns int
+
+	// IdleConnTimeout is the maximum amount of time an idle connection is
+	// kept in the pool before it is closed.  A value of zero means no
+	// limit.
+	IdleConnTimeout time.Duration
+
+	// MaxRequestSize is the maximum size in bytes of a marshalled request.
+	// Larger requests fail with ErrRequestTooLarge without being sent.  A
+	// value of zero means no limit.
+	MaxRequestSize int64
+
+	// MaxResponseSize is the maximum size in bytes of a response body.
+	// Larger responses fail with ErrResponseTooLarge.  A value of zero
+	// means no limit.
+	MaxResponseSize int64
+
+	// RetryPolicy is the policy used to retry failed requests.  The policy
+	// returned by DefaultRetryPolicy is used when it is nil.
+	RetryPolicy *RetryPolicy
+
+	// MethodRetryPolicies optionally overrides RetryPolicy for individual
+	// RPC methods keyed by the method name.
+	MethodRetryPolicies map[string]*RetryPolicy
 }
 
 // getAuth returns the username and passphrase that will actually be used for
@@ -1335,11 +1460,27 @@ func newHTTPClient(config *ConnConfig) (*http.Client, error) {
 		}
 	}
 
+	dialer := &net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: config.KeepAlive,
+	}
+	maxIdleConnsPerHost := config.MaxIdleConns
+	if maxIdleConnsPerHost == 0 {
+		maxIdleConnsPerHost = http.DefaultMaxIdleConnsPerHost
+	}
 	client := http.Client{
 		Transport: &http.Transport{
-			Proxy:           proxyFunc,
-			TLSClientConfig: tlsConfig,
+			Proxy:                 proxyFunc,
+			DialContext:           dialer.DialContext,
+			TLSClientConfig:       tlsConfig,
+			DisableKeepAlives:     config.DisableKeepAlive,
+			MaxIdleConns:          config.MaxIdleConns,
+			MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+			MaxConnsPerHost:       config.MaxConns,
+			IdleConnTimeout:       config.IdleConnTimeout,
+			ResponseHeaderTimeout: config.ResponseHeaderTimeout,
 		},
+		Timeout: config.RequestTimeout,
 	}
 
 	return &client, nil
@@ -1659,8 +1800,10 @@ func (c *Client) sendAsync() FutureGetBulkResult {
 	// convert the array of marshalled json requests to a single request we can send
 	responseChan := make(chan *Response, 1)
 	marshalledRequest := []byte("[")
+	idempotent := true
 	for iter := c.batchList.Front(); iter != nil; iter = iter.Next() {
 		request := iter.Value.(*jsonRequest)
+		idempotent = idempotent && request.idempotent
 		marshalledRequest = append(marshalledRequest, request.marshalledJSON...)
 		marshalledRequest = append(marshalledRequest, []byte(",")...)
 	}
@@ -1675,6 +1818,7 @@ func (c *Client) sendAsync() FutureGetBulkResult {
 		cmd:            nil,
 		marshalledJSON: marshalledRequest,
 		responseChan:   responseChan,
+		idempotent:     idempotent,
 	}
 	c.sendPostRequest(&request)
 	return responseChan
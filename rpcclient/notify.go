@@ -151,6 +151,14 @@ type NotificationHandlers struct {
 	// github.com/decred/dcrrpcclient.
 	OnRelevantTxAccepted func(transaction []byte)
 
+	// OnDescriptorExtended is invoked when the addresses monitored for a
+	// descriptor registered with RegisterDescriptor are extended since one
+	// of them was used.  It reports the index of the next unused address
+	// and the number of addresses derived from the descriptor.
+	//
+	// NOTE: This is a btcd extension and requires a websocket connection.
+	OnDescriptorExtended func(descriptor string, nextIndex, derived uint32)
+
 	// OnRescanFinished is invoked after a rescan finishes due to a previous
 	// call to Rescan or RescanEndHeight.  Finished rescans should be
 	// signaled on this notification, rather than relying on the return
@@ -179,6 +187,14 @@ type NotificationHandlers struct {
 	// made to register for the notification and the function is non-nil.
 	OnTxAcceptedVerbose func(txDetails *btcjson.TxRawResult)
 
+	// OnTxExpired is invoked when a transaction is evicted from the memory
+	// pool since it expired.  It will only be invoked if a preceding call
+	// to NotifyNewTransactions has been made to register for the
+	// notification and the function is non-nil.
+	//
+	// NOTE: This is a btcd extension and requires a websocket connection.
+	OnTxExpired func(hash *chainhash.Hash)
+
 	// OnBtcdConnected is invoked when a wallet connects or disconnects from
 	// btcd.
 	//
@@ -341,6 +357,24 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 
 		c.ntfnHandlers.OnRelevantTxAccepted(transaction)
 
+	// OnDescriptorExtended
+	case btcjson.DescriptorExtendedNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnDescriptorExtended == nil {
+			return
+		}
+
+		descriptor, nextIndex, derived, err :=
+			parseDescriptorExtendedParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid descriptorextended "+
+				"notification: %v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnDescriptorExtended(descriptor, nextIndex, derived)
+
 	// OnRescanFinished
 	case btcjson.RescanFinishedNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -409,6 +443,23 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 
 		c.ntfnHandlers.OnTxAcceptedVerbose(rawTx)
 
+	// OnTxExpired
+	case btcjson.TxExpiredNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnTxExpired == nil {
+			return
+		}
+
+		hash, err := parseTxExpiredNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid tx expired "+
+				"notification: %v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnTxExpired(hash)
+
 	// OnBtcdConnected
 	case btcjson.BtcdConnectedNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -678,6 +729,40 @@ func parseChainTxNtfnParams(params []json.RawMessage) (*btcutil.Tx,
 	return btcutil.NewTx(&msgTx), block, nil
 }
 
+// parseDescriptorExtendedParams parses out the descriptor, the index of its
+// next unused address and the number of derived addresses from the parameters
+// of a descriptorextended notification.
+func parseDescriptorExtendedParams(params []json.RawMessage) (string, uint32,
+	uint32, error) {
+
+	if len(params) != 3 {
+		return "", 0, 0, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a string.
+	var descriptor string
+	err := json.Unmarshal(params[0], &descriptor)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	// Unmarshal second parameter as an integer.
+	var nextIndex uint32
+	err = json.Unmarshal(params[1], &nextIndex)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	// Unmarshal third parameter as an integer.
+	var derived uint32
+	err = json.Unmarshal(params[2], &derived)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	return descriptor, nextIndex, derived, nil
+}
+
 // parseRescanProgressParams parses out the height of the last rescanned block
 // from the parameters of rescanfinished and rescanprogress notifications.
 func parseRescanProgressParams(params []json.RawMessage) (*chainhash.Hash, int32, time.Time, error) {
@@ -715,6 +800,24 @@ func parseRescanProgressParams(params []json.RawMessage) (*chainhash.Hash, int32
 	return hash, height, time.Unix(blkTime, 0), nil
 }
 
+// parseTxExpiredNtfnParams parses out the transaction hash from the
+// parameters of a txexpired notification.
+func parseTxExpiredNtfnParams(params []json.RawMessage) (*chainhash.Hash, error) {
+	if len(params) != 1 {
+		return nil, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a string.
+	var txHashStr string
+	err := json.Unmarshal(params[0], &txHashStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode string encoding of transaction sha.
+	return chainhash.NewHashFromStr(txHashStr)
+}
+
 // parseTxAcceptedNtfnParams parses out the transaction hash and total amount
 // from the parameters of a txaccepted notification.
 func parseTxAcceptedNtfnParams(params []json.RawMessage) (*chainhash.Hash,
@@ -902,6 +1005,50 @@ func (c *Client) NotifyBlocks() error {
 	return c.NotifyBlocksAsync().Receive()
 }
 
+// NotifyBlocksFromAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See NotifyBlocksFrom for the blocking version and more details.
+//
+// NOTE: This is a btcd extension and requires a websocket connection.
+func (c *Client) NotifyBlocksFromAsync(hash *chainhash.Hash) FutureNotifyBlocksResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	// Ignore the notification if the client is not interested in
+	// notifications.
+	if c.ntfnHandlers == nil {
+		return newNilFutureResult()
+	}
+
+	cmd := btcjson.NewNotifyBlocksFromCmd(hash.String())
+	return c.SendCmd(cmd)
+}
+
+// NotifyBlocksFrom registers the client to receive notifications when blocks
+// are connected and disconnected from the main chain in the same manner as
+// NotifyBlocks.  Before that, the server replays the notifications needed to
+// bring the client from the passed block, typically the last block it
+// processed, up to the current best block.  This includes disconnecting the
+// blocks of a side chain the passed block is part of.  The server replays at
+// most 1440 blocks and replaces a registration made with NotifyBlocks, so the
+// two should not be combined.
+//
+// Unlike NotifyBlocks, the registration is not automatically reestablished
+// after reconnecting since the replay must start from the last block the
+// caller processed.
+//
+// The notifications delivered as a result of this call will be via one of
+// OnBlockConnected or OnBlockDisconnected.
+//
+// NOTE: This is a btcd extension and requires a websocket connection.
+func (c *Client) NotifyBlocksFrom(hash *chainhash.Hash) error {
+	return c.NotifyBlocksFromAsync(hash).Receive()
+}
+
 // FutureNotifySpentResult is a future promise to deliver the result of a
 // NotifySpentAsync RPC invocation (or an applicable error).
 //
@@ -1030,7 +1177,8 @@ func (c *Client) NotifyNewTransactionsAsync(verbose bool) FutureNotifyNewTransac
 //
 // The notifications delivered as a result of this call will be via one of
 // OnTxAccepted (when verbose is false) or OnTxAcceptedVerbose (when verbose is
-// true).
+// true).  Transactions evicted from the memory pool since they expired are
+// reported via OnTxExpired.
 //
 // NOTE: This is a btcd extension and requires a websocket connection.
 func (c *Client) NotifyNewTransactions(verbose bool) error {
@@ -1355,3 +1503,65 @@ func (c *Client) LoadTxFilterAsync(reload bool, addresses []btcutil.Address,
 func (c *Client) LoadTxFilter(reload bool, addresses []btcutil.Address, outPoints []wire.OutPoint) error {
 	return c.LoadTxFilterAsync(reload, addresses, outPoints).Receive()
 }
+
+// FutureRegisterDescriptorResult is a future promise to deliver the result of
+// a RegisterDescriptorAsync RPC invocation (or an applicable error).
+//
+// NOTE: This is a btcd extension and requires a websocket connection.
+type FutureRegisterDescriptorResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the state
+// of the registered descriptor, including the index of its next unused
+// address.
+//
+// NOTE: This is a btcd extension and requires a websocket connection.
+func (r FutureRegisterDescriptorResult) Receive() (*btcjson.RegisterDescriptorResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a registerdescriptor result object.
+	var descResult btcjson.RegisterDescriptorResult
+	err = json.Unmarshal(res, &descResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &descResult, nil
+}
+
+// RegisterDescriptorAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See RegisterDescriptor for the blocking version and more details.
+//
+// NOTE: This is a btcd extension and requires a websocket connection.
+func (c *Client) RegisterDescriptorAsync(descriptor string,
+	gapLimit uint32) FutureRegisterDescriptorResult {
+
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	cmd := btcjson.NewRegisterDescriptorCmd(descriptor, &gapLimit)
+	return c.SendCmd(cmd)
+}
+
+// RegisterDescriptor registers a ranged output descriptor with the websocket
+// client's transaction filter.  The server derives the addresses of the
+// descriptor up to the passed gap limit ahead of the highest used address and
+// keeps extending them as transactions paying to them are observed during
+// mempool acceptance, block acceptance and for all rescanned blocks, and
+// reports each extension with a descriptorextended notification to the
+// OnDescriptorExtended handler.  Registering the descriptor again reports the
+// index of its next unused address as well.
+//
+// NOTE: This is a btcd extension and requires a websocket connection.
+func (c *Client) RegisterDescriptor(descriptor string,
+	gapLimit uint32) (*btcjson.RegisterDescriptorResult, error) {
+
+	return c.RegisterDescriptorAsync(descriptor, gapLimit).Receive()
+}
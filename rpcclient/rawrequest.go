@@ -62,6 +62,7 @@ func (c *Client) RawRequestAsync(method string, params []json.RawMessage) Future
 		cmd:            nil,
 		marshalledJSON: marshalledJSON,
 		responseChan:   responseChan,
+		idempotent:     IsIdempotentMethod(method),
 	}
 	c.sendRequest(jReq)
 
@@ -0,0 +1,178 @@
+// Copyright (c) 2014-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrRequestTooLarge is an error to describe the condition where a
+	// marshalled request exceeds the MaxRequestSize configured for a client
+	// running in HTTP POST mode.  The request is not sent in this case.
+	ErrRequestTooLarge = errors.New("request exceeds the maximum " +
+		"allowed size")
+
+	// ErrResponseTooLarge is an error to describe the condition where the
+	// response from the RPC server exceeds the MaxResponseSize configured
+	// for a client running in HTTP POST mode.
+	ErrResponseTooLarge = errors.New("response exceeds the maximum " +
+		"allowed size")
+)
+
+const (
+	// defaultRetryMaxAttempts is the default number of times a request is
+	// attempted in HTTP POST mode before giving up.
+	defaultRetryMaxAttempts = 10
+
+	// defaultRetryMaxBackoff is the default upper bound on the time to
+	// wait in between request attempts in HTTP POST mode.
+	defaultRetryMaxBackoff = time.Minute
+)
+
+// nonIdempotentMethods houses the RPC methods which change state on the server
+// in a way that must not be repeated.  Requests for these methods are only
+// retried when it is certain the previous attempt never reached the server,
+// unless a retry policy explicitly opts into retrying them regardless.
+var nonIdempotentMethods = map[string]struct{}{
+	"abandontransaction":     {},
+	"addmultisigaddress":     {},
+	"addnode":                {},
+	"backupwallet":           {},
+	"bumpfee":                {},
+	"createnewaccount":       {},
+	"createwallet":           {},
+	"dumpwallet":             {},
+	"encryptwallet":          {},
+	"generate":               {},
+	"generatetoaddress":      {},
+	"getaccountaddress":      {},
+	"getnewaddress":          {},
+	"getrawchangeaddress":    {},
+	"importaddress":          {},
+	"importdescriptors":      {},
+	"importmulti":            {},
+	"importprivkey":          {},
+	"importpubkey":           {},
+	"importwallet":           {},
+	"keypoolrefill":          {},
+	"loadwallet":             {},
+	"lockunspent":            {},
+	"move":                   {},
+	"node":                   {},
+	"renameaccount":          {},
+	"rescanblockchain":       {},
+	"sendfrom":               {},
+	"sendmany":               {},
+	"sendrawtransaction":     {},
+	"sendtoaddress":          {},
+	"setaccount":             {},
+	"setgenerate":            {},
+	"settxfee":               {},
+	"stop":                   {},
+	"submitblock":            {},
+	"unloadwallet":           {},
+	"walletlock":             {},
+	"walletpassphrase":       {},
+	"walletpassphrasechange": {},
+}
+
+// IsIdempotentMethod returns whether or not requests for the passed RPC method
+// can safely be repeated without additional side effects on the server.
+func IsIdempotentMethod(method string) bool {
+	_, ok := nonIdempotentMethods[method]
+	return !ok
+}
+
+// RetryPolicy describes how requests issued by a client running in HTTP POST
+// mode are retried when they fail due to transport errors or because the server
+// is temporarily unable to service them.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times a request is attempted,
+	// including the first one.  Values less than one are treated as one,
+	// which disables retries entirely.
+	MaxAttempts int
+
+	// InitialBackoff is the time to wait before the first retry.  The
+	// wait grows linearly with every further attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the upper bound on the time to wait in between
+	// attempts.  A value of zero means no upper bound.
+	MaxBackoff time.Duration
+
+	// RetryNonIdempotent specifies that requests for methods which change
+	// state on the server are retried under the same conditions as all
+	// other requests.  By default they are only retried when the
+	// connection to the server could not be established, since otherwise
+	// the server might have already processed the failed attempt.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the retry policy used for requests in HTTP POST
+// mode when no policy is configured.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		InitialBackoff: requestRetryInterval,
+		MaxBackoff:     defaultRetryMaxBackoff,
+	}
+}
+
+// attempts returns the total number of attempts allowed by the policy.
+func (p *RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the time to wait before retrying after the passed zero-based
+// attempt failed.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff * time.Duration(attempt+1)
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
+
+// shouldRetryErr returns whether a request which failed with the passed
+// transport error may be attempted again.
+func (p *RetryPolicy) shouldRetryErr(err error, idempotent bool) bool {
+	if idempotent || p.RetryNonIdempotent {
+		return true
+	}
+
+	// Failing to establish a connection means the request was never sent
+	// and is therefore always safe to retry.
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// shouldRetryStatus returns whether a request which received the passed HTTP
+// status code may be attempted again.  Only responses indicating the server
+// did not process the request at all are considered.
+func (p *RetryPolicy) shouldRetryStatus(statusCode int, idempotent bool) bool {
+	if !idempotent && !p.RetryNonIdempotent {
+		return false
+	}
+	return statusCode == http.StatusServiceUnavailable
+}
+
+// retryPolicy returns the retry policy that applies to requests for the passed
+// method.
+func (config *ConnConfig) retryPolicy(method string) *RetryPolicy {
+	if policy, ok := config.MethodRetryPolicies[method]; ok && policy != nil {
+		return policy
+	}
+	if config.RetryPolicy != nil {
+		return config.RetryPolicy
+	}
+	return DefaultRetryPolicy()
+}
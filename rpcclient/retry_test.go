@@ -0,0 +1,244 @@
+// Copyright (c) 2014-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestPostClient returns a client running in HTTP POST mode which connects
+// to the passed test server.
+func newTestPostClient(t *testing.T, server *httptest.Server,
+	modify func(*ConnConfig)) *Client {
+
+	t.Helper()
+
+	config := &ConnConfig{
+		Host:         strings.TrimPrefix(server.URL, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		DisableTLS:   true,
+		HTTPPostMode: true,
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+		},
+	}
+	if modify != nil {
+		modify(config)
+	}
+	client, err := New(config, nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+	t.Cleanup(client.Shutdown)
+	return client
+}
+
+// TestPostRetryIdempotency ensures requests which receive a service
+// unavailable response are only retried when their method is idempotent.
+func TestPostRetryIdempotency(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		},
+	))
+	defer server.Close()
+	client := newTestPostClient(t, server, nil)
+
+	// An idempotent method is attempted the maximum number of times.
+	if _, err := client.GetBlockCount(); err == nil {
+		t.Fatal("getblockcount: expected error")
+	}
+	if got := atomic.SwapInt32(&attempts, 0); got != 3 {
+		t.Fatalf("getblockcount: unexpected attempts - got %d, want 3",
+			got)
+	}
+
+	// A state-changing method must not be retried.
+	if err := client.SetGenerate(true, 1); err == nil {
+		t.Fatal("setgenerate: expected error")
+	}
+	if got := atomic.SwapInt32(&attempts, 0); got != 1 {
+		t.Fatalf("setgenerate: unexpected attempts - got %d, want 1",
+			got)
+	}
+
+	// Unless the policy for the method explicitly opts in.
+	client.config.MethodRetryPolicies = map[string]*RetryPolicy{
+		"setgenerate": {
+			MaxAttempts:        2,
+			InitialBackoff:     time.Millisecond,
+			RetryNonIdempotent: true,
+		},
+	}
+	if err := client.SetGenerate(true, 1); err == nil {
+		t.Fatal("setgenerate: expected error")
+	}
+	if got := atomic.SwapInt32(&attempts, 0); got != 2 {
+		t.Fatalf("setgenerate override: unexpected attempts - got %d, "+
+			"want 2", got)
+	}
+}
+
+// TestIsIdempotentMethod ensures methods which change state on the server,
+// including those whose effects make a repeated request fail, are not
+// considered idempotent.
+func TestIsIdempotentMethod(t *testing.T) {
+	t.Parallel()
+
+	for _, method := range []string{
+		"abandontransaction", "bumpfee", "createwallet",
+		"importdescriptors", "importmulti", "loadwallet",
+		"rescanblockchain", "sendrawtransaction", "sendtoaddress",
+		"unloadwallet",
+	} {
+		if IsIdempotentMethod(method) {
+			t.Errorf("%s: unexpectedly idempotent", method)
+		}
+	}
+	for _, method := range []string{"getblockcount", "getrawtransaction"} {
+		if !IsIdempotentMethod(method) {
+			t.Errorf("%s: unexpectedly not idempotent", method)
+		}
+	}
+}
+
+// TestPostShutdown ensures requests which are in flight or waiting to be
+// retried when the client shuts down are completed with ErrClientShutdown
+// rather than left pending.
+func TestPostShutdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{{
+		name: "in flight",
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			// The request context is only canceled once the
+			// body has been read.
+			ioutil.ReadAll(r.Body)
+			<-r.Context().Done()
+		},
+	}, {
+		name: "waiting to retry",
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		},
+	}}
+
+	for _, test := range tests {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				test.handler(w, r)
+			},
+		))
+		client := newTestPostClient(t, server, func(config *ConnConfig) {
+			config.RetryPolicy = &RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Hour,
+			}
+		})
+
+		future := client.GetBlockCountAsync()
+		for atomic.LoadInt32(&attempts) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		client.Shutdown()
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := future.Receive()
+			result <- err
+		}()
+		select {
+		case err := <-result:
+			if err != ErrClientShutdown {
+				t.Errorf("%s: unexpected error - got %v, want %v",
+					test.name, err, ErrClientShutdown)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%s: request not completed on shutdown",
+				test.name)
+		}
+		if got := atomic.LoadInt32(&attempts); got != 1 {
+			t.Errorf("%s: unexpected attempts - got %d, want 1",
+				test.name, got)
+		}
+		server.Close()
+	}
+}
+
+// TestPostSizeLimits ensures the configured request and response size limits
+// are enforced.
+func TestPostSizeLimits(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.Write([]byte(`{"result":"` + strings.Repeat("00", 64) +
+				`","error":null,"id":1}`))
+		},
+	))
+	defer server.Close()
+
+	client := newTestPostClient(t, server, func(config *ConnConfig) {
+		config.MaxResponseSize = 64
+	})
+	if _, err := client.GetBestBlockHash(); err != ErrResponseTooLarge {
+		t.Fatalf("unexpected error - got %v, want %v", err,
+			ErrResponseTooLarge)
+	}
+
+	client = newTestPostClient(t, server, func(config *ConnConfig) {
+		config.MaxRequestSize = 16
+	})
+	atomic.StoreInt32(&attempts, 0)
+	if _, err := client.GetBestBlockHash(); err != ErrRequestTooLarge {
+		t.Fatalf("unexpected error - got %v, want %v", err,
+			ErrRequestTooLarge)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 0 {
+		t.Fatalf("oversized request was sent %d times", got)
+	}
+}
+
+// TestRetryPolicyBackoff ensures the retry backoff grows linearly and is
+// bounded by the maximum backoff.
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := &RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     3 * time.Second,
+	}
+	want := []time.Duration{
+		time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second,
+	}
+	for attempt, wantBackoff := range want {
+		if got := policy.backoff(attempt); got != wantBackoff {
+			t.Errorf("attempt %d: unexpected backoff - got %v, "+
+				"want %v", attempt, got, wantBackoff)
+		}
+	}
+	if got := policy.attempts(); got != 1 {
+		t.Errorf("unexpected attempts - got %d, want 1", got)
+	}
+}
@@ -0,0 +1,500 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dogesuite/doged/addrmgr"
+	"github.com/dogesuite/doged/btcjson"
+	"github.com/dogesuite/doged/chaincfg/chainhash"
+)
+
+const (
+	// explorerPathPrefix is the path on the RPC listeners the embedded
+	// block explorer is served at when it is enabled.
+	explorerPathPrefix = "/explorer/"
+
+	// explorerRecentBlocks is the number of most recent blocks listed on
+	// the overview page of the block explorer.
+	explorerRecentBlocks = 20
+
+	// explorerMaxMempoolTxns is the maximum number of transactions listed
+	// on the mempool page of the block explorer.
+	explorerMaxMempoolTxns = 500
+
+	// explorerAddressTxns is the maximum number of transactions listed on
+	// the page of an address.
+	explorerAddressTxns = 50
+)
+
+// explorerPage houses the data a page of the block explorer is rendered with.
+type explorerPage struct {
+	Title   string
+	Network string
+	Error   string
+	Data    interface{}
+}
+
+// explorerBlockSummary describes a block in the list of recent blocks.
+type explorerBlockSummary struct {
+	Height int32
+	Hash   string
+	Time   time.Time
+}
+
+// explorerOverview houses the data of the overview page.
+type explorerOverview struct {
+	Height     int32
+	Hash       string
+	Difficulty float64
+	MedianTime time.Time
+	MempoolTxs int
+	Peers      int32
+	Blocks     []explorerBlockSummary
+}
+
+// explorerMempoolTx describes a transaction on the mempool page.
+type explorerMempoolTx struct {
+	Txid string
+	*btcjson.GetRawMempoolVerboseResult
+}
+
+// explorerMempool houses the data of the mempool page.
+type explorerMempool struct {
+	Count int
+	Txns  []explorerMempoolTx
+}
+
+// explorerPeer describes a connected peer on the peers page.
+type explorerPeer struct {
+	ID        int32
+	Addr      string
+	Group     string
+	Inbound   bool
+	UserAgent string
+	Version   uint32
+	Height    int32
+	PingTime  time.Duration
+}
+
+// explorerPeerGroup describes the number of connected peers in a network
+// group.
+type explorerPeerGroup struct {
+	Group string
+	Count int
+}
+
+// explorerPeers houses the data of the peers page.
+type explorerPeers struct {
+	Groups []explorerPeerGroup
+	Peers  []explorerPeer
+}
+
+// explorerTemplates contains the templates of all pages of the block explorer.
+var explorerTemplates = template.Must(template.New("explorer").Funcs(
+	template.FuncMap{
+		"unixtime": func(t int64) time.Time {
+			return time.Unix(t, 0).UTC()
+		},
+	}).Parse(explorerTemplateSrc))
+
+// explorerTemplateSrc is the source of the templates of the block explorer.
+// Every page template includes the shared header and footer.
+const explorerTemplateSrc = `
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - btcd explorer ({{.Network}})</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; }
+table { border-collapse: collapse; }
+td, th { padding: 2px 8px; text-align: left; border-bottom: 1px solid #ddd; }
+code { word-break: break-all; }
+</style>
+</head>
+<body>
+<p>
+<a href="/explorer/">Overview</a> |
+<a href="/explorer/mempool">Mempool</a> |
+<a href="/explorer/peers">Peers</a> |
+<form style="display:inline" action="/explorer/search">
+<input name="q" size="70" placeholder="Block height or hash, transaction id or address">
+</form>
+</p>
+<h2>{{.Title}}</h2>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "error"}}{{template "header" .}}
+<p>{{.Error}}</p>
+{{template "footer" .}}{{end}}
+
+{{define "overview"}}{{template "header" .}}{{with .Data}}
+<table>
+<tr><th>Height</th><td>{{.Height}}</td></tr>
+<tr><th>Best block</th><td><a href="/explorer/block/{{.Hash}}"><code>{{.Hash}}</code></a></td></tr>
+<tr><th>Difficulty</th><td>{{.Difficulty}}</td></tr>
+<tr><th>Median time</th><td>{{.MedianTime.UTC}}</td></tr>
+<tr><th>Mempool transactions</th><td>{{.MempoolTxs}}</td></tr>
+<tr><th>Connected peers</th><td>{{.Peers}}</td></tr>
+</table>
+<h3>Recent blocks</h3>
+<table>
+<tr><th>Height</th><th>Hash</th><th>Time</th></tr>
+{{range .Blocks}}<tr><td>{{.Height}}</td><td><a href="/explorer/block/{{.Hash}}"><code>{{.Hash}}</code></a></td><td>{{.Time.UTC}}</td></tr>
+{{end}}</table>
+{{end}}{{template "footer" .}}{{end}}
+
+{{define "block"}}{{template "header" .}}{{with .Data}}
+<table>
+<tr><th>Hash</th><td><code>{{.Hash}}</code></td></tr>
+<tr><th>Height</th><td>{{.Height}}</td></tr>
+<tr><th>Confirmations</th><td>{{.Confirmations}}</td></tr>
+<tr><th>Time</th><td>{{unixtime .Time}}</td></tr>
+<tr><th>Version</th><td>{{.VersionHex}}</td></tr>
+<tr><th>Merkle root</th><td><code>{{.MerkleRoot}}</code></td></tr>
+<tr><th>Bits</th><td>{{.Bits}}</td></tr>
+<tr><th>Nonce</th><td>{{.Nonce}}</td></tr>
+<tr><th>Difficulty</th><td>{{.Difficulty}}</td></tr>
+<tr><th>Size</th><td>{{.Size}} bytes ({{.Weight}} weight units)</td></tr>
+{{if .PreviousHash}}<tr><th>Previous block</th><td><a href="/explorer/block/{{.PreviousHash}}"><code>{{.PreviousHash}}</code></a></td></tr>{{end}}
+{{if .NextHash}}<tr><th>Next block</th><td><a href="/explorer/block/{{.NextHash}}"><code>{{.NextHash}}</code></a></td></tr>{{end}}
+</table>
+<h3>Transactions ({{len .Tx}})</h3>
+<table>
+{{range .Tx}}<tr><td><a href="/explorer/tx/{{.}}"><code>{{.}}</code></a></td></tr>
+{{end}}</table>
+{{end}}{{template "footer" .}}{{end}}
+
+{{define "tx"}}{{template "header" .}}{{with .Data}}
+<table>
+<tr><th>Txid</th><td><code>{{.Txid}}</code></td></tr>
+<tr><th>Hash</th><td><code>{{.Hash}}</code></td></tr>
+<tr><th>Size</th><td>{{.Size}} bytes ({{.Vsize}} virtual bytes)</td></tr>
+<tr><th>Version</th><td>{{.Version}}</td></tr>
+<tr><th>Lock time</th><td>{{.LockTime}}</td></tr>
+{{if .BlockHash}}<tr><th>Block</th><td><a href="/explorer/block/{{.BlockHash}}"><code>{{.BlockHash}}</code></a></td></tr>
+<tr><th>Confirmations</th><td>{{.Confirmations}}</td></tr>
+<tr><th>Block time</th><td>{{unixtime .Blocktime}}</td></tr>{{else}}<tr><th>Status</th><td>Unconfirmed</td></tr>{{end}}
+</table>
+<h3>Inputs</h3>
+<table>
+{{range .Vin}}<tr><td>{{if .Coinbase}}Coinbase <code>{{.Coinbase}}</code>{{else}}<a href="/explorer/tx/{{.Txid}}"><code>{{.Txid}}</code></a>:{{.Vout}}{{end}}</td></tr>
+{{end}}</table>
+<h3>Outputs</h3>
+<table>
+<tr><th>#</th><th>Value</th><th>Type</th><th>Addresses</th></tr>
+{{range .Vout}}<tr><td>{{.N}}</td><td>{{printf "%.8f" .Value}}</td><td>{{.ScriptPubKey.Type}}</td><td>{{range .ScriptPubKey.Addresses}}<a href="/explorer/address/{{.}}"><code>{{.}}</code></a> {{end}}</td></tr>
+{{end}}</table>
+{{end}}{{template "footer" .}}{{end}}
+
+{{define "address"}}{{template "header" .}}
+<p>Most recent transactions involving the address.</p>
+<table>
+<tr><th>Transaction</th><th>Block time</th><th>Confirmations</th></tr>
+{{range .Data}}<tr><td><a href="/explorer/tx/{{.Txid}}"><code>{{.Txid}}</code></a></td><td>{{if .Blocktime}}{{unixtime .Blocktime}}{{else}}Unconfirmed{{end}}</td><td>{{.Confirmations}}</td></tr>
+{{end}}</table>
+{{template "footer" .}}{{end}}
+
+{{define "mempool"}}{{template "header" .}}{{with .Data}}
+<p>{{.Count}} transactions, showing the {{len .Txns}} most recent.</p>
+<table>
+<tr><th>Transaction</th><th>Virtual size</th><th>Fee</th><th>Added</th><th>Dependencies</th></tr>
+{{range .Txns}}<tr><td><a href="/explorer/tx/{{.Txid}}"><code>{{.Txid}}</code></a></td><td>{{.Vsize}}</td><td>{{printf "%.8f" .Fee}}</td><td>{{unixtime .Time}}</td><td>{{len .Depends}}</td></tr>
+{{end}}</table>
+{{end}}{{template "footer" .}}{{end}}
+
+{{define "peers"}}{{template "header" .}}{{with .Data}}
+<h3>Network groups</h3>
+<table>
+<tr><th>Group</th><th>Peers</th></tr>
+{{range .Groups}}<tr><td>{{.Group}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+<h3>Connected peers</h3>
+<table>
+<tr><th>ID</th><th>Address</th><th>Group</th><th>Direction</th><th>User agent</th><th>Version</th><th>Height</th><th>Ping</th></tr>
+{{range .Peers}}<tr><td>{{.ID}}</td><td>{{.Addr}}</td><td>{{.Group}}</td><td>{{if .Inbound}}inbound{{else}}outbound{{end}}</td><td>{{.UserAgent}}</td><td>{{.Version}}</td><td>{{.Height}}</td><td>{{.PingTime}}</td></tr>
+{{end}}</table>
+{{end}}{{template "footer" .}}{{end}}
+`
+
+// explorerSearchPath returns the path of the block explorer page for the
+// passed search query.  Numbers are treated as block heights and hashes are
+// looked up as blocks before falling back to transactions.  Anything else is
+// treated as an address.
+func explorerSearchPath(query string, haveBlock func(*chainhash.Hash) bool) string {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return explorerPathPrefix
+	}
+
+	if _, err := strconv.ParseUint(query, 10, 31); err == nil {
+		return explorerPathPrefix + "block/" + query
+	}
+	if len(query) == chainhash.MaxHashStringSize {
+		if hash, err := chainhash.NewHashFromStr(query); err == nil {
+			if haveBlock(hash) {
+				return explorerPathPrefix + "block/" + query
+			}
+			return explorerPathPrefix + "tx/" + query
+		}
+	}
+	return explorerPathPrefix + "address/" + url.PathEscape(query)
+}
+
+// renderExplorerPage executes the named template with the passed page data and
+// writes the result with the passed status code.
+func (s *rpcServer) renderExplorerPage(w http.ResponseWriter, status int,
+	name string, page *explorerPage) {
+
+	page.Network = s.cfg.ChainParams.Name
+
+	var buf bytes.Buffer
+	if err := explorerTemplates.ExecuteTemplate(&buf, name, page); err != nil {
+		rpcsLog.Errorf("Failed to render explorer page: %v", err)
+		errCode := http.StatusInternalServerError
+		http.Error(w, strconv.Itoa(errCode)+" "+
+			http.StatusText(errCode), errCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; "+
+		"style-src 'unsafe-inline'; form-action 'self'")
+	w.WriteHeader(status)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		rpcsLog.Errorf("Failed to write explorer page: %v", err)
+	}
+}
+
+// renderExplorerError renders an error page for the passed error returned by
+// an RPC handler.
+func (s *rpcServer) renderExplorerError(w http.ResponseWriter, title string,
+	err error) {
+
+	status := http.StatusBadRequest
+	if rpcErr, ok := err.(*btcjson.RPCError); ok {
+		switch rpcErr.Code {
+		// Unknown blocks and transactions share the same error code.
+		case btcjson.ErrRPCBlockNotFound:
+			status = http.StatusNotFound
+		case btcjson.ErrRPCInternal.Code:
+			status = http.StatusInternalServerError
+		}
+	}
+	s.renderExplorerPage(w, status, "error", &explorerPage{
+		Title: title,
+		Error: err.Error(),
+	})
+}
+
+// handleExplorer serves the pages of the embedded block explorer.  The pages
+// are populated through the RPC handlers so they present the same data as the
+// corresponding RPCs and make use of the same indexes.
+func (s *rpcServer) handleExplorer(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, explorerPathPrefix)
+	page, arg := path, ""
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		page, arg = path[:i], path[i+1:]
+	}
+
+	switch {
+	case page == "":
+		s.explorerOverview(w)
+
+	case page == "search":
+		target := explorerSearchPath(r.FormValue("q"),
+			func(hash *chainhash.Hash) bool {
+				_, err := s.cfg.Chain.HeaderByHash(hash)
+				return err == nil
+			})
+		http.Redirect(w, r, target, http.StatusSeeOther)
+
+	case page == "block" && arg != "":
+		s.explorerBlock(w, arg)
+
+	case page == "tx" && arg != "":
+		verbose := 1
+		result, err := handleGetRawTransaction(s,
+			&btcjson.GetRawTransactionCmd{Txid: arg, Verbose: &verbose},
+			nil)
+		if err != nil {
+			s.renderExplorerError(w, "Transaction", err)
+			return
+		}
+		s.renderExplorerPage(w, http.StatusOK, "tx", &explorerPage{
+			Title: "Transaction",
+			Data:  result,
+		})
+
+	case page == "address" && arg != "":
+		verbose, count, reverse := 1, explorerAddressTxns, true
+		result, err := handleSearchRawTransactions(s,
+			&btcjson.SearchRawTransactionsCmd{
+				Address: arg,
+				Verbose: &verbose,
+				Count:   &count,
+				Reverse: &reverse,
+			}, nil)
+		if err != nil {
+			s.renderExplorerError(w, "Address "+arg, err)
+			return
+		}
+		s.renderExplorerPage(w, http.StatusOK, "address", &explorerPage{
+			Title: "Address " + arg,
+			Data:  result,
+		})
+
+	case page == "mempool" && arg == "":
+		s.explorerMempool(w)
+
+	case page == "peers" && arg == "":
+		s.explorerPeers(w)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// explorerOverview renders the overview page of the block explorer.
+func (s *rpcServer) explorerOverview(w http.ResponseWriter) {
+	best := s.cfg.Chain.BestSnapshot()
+	overview := &explorerOverview{
+		Height:     best.Height,
+		Hash:       best.Hash.String(),
+		Difficulty: getDifficultyRatio(best.Bits, s.cfg.ChainParams),
+		MedianTime: best.MedianTime,
+		MempoolTxs: s.cfg.TxMemPool.Count(),
+		Peers:      s.cfg.ConnMgr.ConnectedCount(),
+	}
+	for height := best.Height; height >= 0 &&
+		best.Height-height < explorerRecentBlocks; height-- {
+
+		hash, err := s.cfg.Chain.BlockHashByHeight(height)
+		if err != nil {
+			break
+		}
+		header, err := s.cfg.Chain.HeaderByHash(hash)
+		if err != nil {
+			break
+		}
+		overview.Blocks = append(overview.Blocks, explorerBlockSummary{
+			Height: height,
+			Hash:   hash.String(),
+			Time:   header.Timestamp,
+		})
+	}
+
+	s.renderExplorerPage(w, http.StatusOK, "overview", &explorerPage{
+		Title: "Overview",
+		Data:  overview,
+	})
+}
+
+// explorerBlock renders the page of the block identified by the passed hash or
+// height.
+func (s *rpcServer) explorerBlock(w http.ResponseWriter, id string) {
+	if height, err := strconv.ParseInt(id, 10, 32); err == nil {
+		hash, err := handleGetBlockHash(s,
+			&btcjson.GetBlockHashCmd{Index: height}, nil)
+		if err != nil {
+			s.renderExplorerError(w, "Block", err)
+			return
+		}
+		id = hash.(string)
+	}
+
+	verbosity := 1
+	result, err := handleGetBlock(s,
+		&btcjson.GetBlockCmd{Hash: id, Verbosity: &verbosity}, nil)
+	if err != nil {
+		s.renderExplorerError(w, "Block", err)
+		return
+	}
+	block := result.(btcjson.GetBlockVerboseResult)
+	s.renderExplorerPage(w, http.StatusOK, "block", &explorerPage{
+		Title: "Block " + strconv.FormatInt(block.Height, 10),
+		Data:  block,
+	})
+}
+
+// explorerMempool renders the mempool page of the block explorer which lists
+// the most recently added transactions.
+func (s *rpcServer) explorerMempool(w http.ResponseWriter) {
+	descs := s.cfg.TxMemPool.RawMempoolVerbose()
+	txns := make([]explorerMempoolTx, 0, len(descs))
+	for txid, desc := range descs {
+		txns = append(txns, explorerMempoolTx{txid, desc})
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		if txns[i].Time != txns[j].Time {
+			return txns[i].Time > txns[j].Time
+		}
+		return txns[i].Txid < txns[j].Txid
+	})
+	if len(txns) > explorerMaxMempoolTxns {
+		txns = txns[:explorerMaxMempoolTxns]
+	}
+
+	s.renderExplorerPage(w, http.StatusOK, "mempool", &explorerPage{
+		Title: "Mempool",
+		Data:  &explorerMempool{Count: len(descs), Txns: txns},
+	})
+}
+
+// explorerPeers renders the peers page of the block explorer which lists the
+// connected peers along with the number of peers per network group.
+func (s *rpcServer) explorerPeers(w http.ResponseWriter) {
+	var peers explorerPeers
+	groupCounts := make(map[string]int)
+	for _, p := range s.cfg.ConnMgr.ConnectedPeers() {
+		statsSnap := p.ToPeer().StatsSnapshot()
+		group := addrmgr.GroupKey(p.ToPeer().NA())
+		groupCounts[group]++
+		peers.Peers = append(peers.Peers, explorerPeer{
+			ID:        statsSnap.ID,
+			Addr:      statsSnap.Addr,
+			Group:     group,
+			Inbound:   statsSnap.Inbound,
+			UserAgent: statsSnap.UserAgent,
+			Version:   statsSnap.Version,
+			Height:    statsSnap.LastBlock,
+			PingTime: time.Duration(statsSnap.LastPingMicros) *
+				time.Microsecond,
+		})
+	}
+	sort.Slice(peers.Peers, func(i, j int) bool {
+		return peers.Peers[i].ID < peers.Peers[j].ID
+	})
+	for group, count := range groupCounts {
+		peers.Groups = append(peers.Groups, explorerPeerGroup{
+			Group: group,
+			Count: count,
+		})
+	}
+	sort.Slice(peers.Groups, func(i, j int) bool {
+		if peers.Groups[i].Count != peers.Groups[j].Count {
+			return peers.Groups[i].Count > peers.Groups[j].Count
+		}
+		return peers.Groups[i].Group < peers.Groups[j].Group
+	})
+
+	s.renderExplorerPage(w, http.StatusOK, "peers", &explorerPage{
+		Title: "Peers",
+		Data:  &peers,
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/dogesuite/doged/btcjson"
+	"github.com/dogesuite/doged/chaincfg"
+	"github.com/dogesuite/doged/chaincfg/chainhash"
+)
+
+// TestExplorerSearchPath ensures search queries are mapped to the expected
+// block explorer pages.
+func TestExplorerSearchPath(t *testing.T) {
+	blockHash := chaincfg.MainNetParams.GenesisHash.String()
+	haveBlock := func(hash *chainhash.Hash) bool {
+		return hash.IsEqual(chaincfg.MainNetParams.GenesisHash)
+	}
+	txid := strings.Repeat("ab", chainhash.HashSize)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "/explorer/"},
+		{" 1234 ", "/explorer/block/1234"},
+		{blockHash, "/explorer/block/" + blockHash},
+		{txid, "/explorer/tx/" + txid},
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa",
+			"/explorer/address/1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+		{"a/../b", "/explorer/address/a%2F..%2Fb"},
+	}
+	for _, test := range tests {
+		if got := explorerSearchPath(test.query, haveBlock); got != test.want {
+			t.Errorf("query %q: unexpected path - got %q, want %q",
+				test.query, got, test.want)
+		}
+	}
+}
+
+// TestExplorerTemplates ensures all block explorer pages can be rendered with
+// the data the handlers populate them with and that the data is escaped.
+func TestExplorerTemplates(t *testing.T) {
+	fee := &btcjson.GetRawMempoolVerboseResult{Fee: 0.0001}
+	tests := []struct {
+		name string
+		data interface{}
+	}{
+		{"error", nil},
+		{"overview", &explorerOverview{
+			Blocks: []explorerBlockSummary{{Hash: "<b>"}},
+		}},
+		{"block", btcjson.GetBlockVerboseResult{Tx: []string{"<b>"}}},
+		{"tx", &btcjson.TxRawResult{
+			Vin: []btcjson.Vin{{Coinbase: "<b>"}, {Txid: "<b>"}},
+			Vout: []btcjson.Vout{{ScriptPubKey: btcjson.ScriptPubKeyResult{
+				Addresses: []string{"<b>"},
+			}}},
+		}},
+		{"address", []btcjson.SearchRawTransactionsResult{{Txid: "<b>"}}},
+		{"mempool", &explorerMempool{
+			Txns: []explorerMempoolTx{{"<b>", fee}},
+		}},
+		{"peers", &explorerPeers{
+			Groups: []explorerPeerGroup{{Group: "<b>"}},
+			Peers:  []explorerPeer{{UserAgent: "<b>"}},
+		}},
+	}
+	for _, test := range tests {
+		var sb strings.Builder
+		err := explorerTemplates.ExecuteTemplate(&sb, test.name,
+			&explorerPage{Title: "<b>", Error: "<b>", Data: test.data})
+		if err != nil {
+			t.Errorf("%s: unable to render page: %v", test.name, err)
+			continue
+		}
+		if strings.Contains(sb.String(), "<b>") {
+			t.Errorf("%s: page contains unescaped data", test.name)
+		}
+	}
+
+	// Ensure the templates don't fail on empty data either.
+	err := explorerTemplates.ExecuteTemplate(ioutil.Discard, "overview",
+		&explorerPage{Data: &explorerOverview{}})
+	if err != nil {
+		t.Errorf("unable to render empty overview: %v", err)
+	}
+}
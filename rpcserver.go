@@ -21,12 +21,14 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/dogesuite/doged/addrmgr"
 	"github.com/dogesuite/doged/blockchain"
 	"github.com/dogesuite/doged/blockchain/indexers"
 	"github.com/dogesuite/doged/btcec/v2/ecdsa"
@@ -131,6 +133,7 @@ type commandHandler func(*rpcServer, interface{}, <-chan struct{}) (interface{},
 var rpcHandlers map[string]commandHandler
 var rpcHandlersBeforeInit = map[string]commandHandler{
 	"addnode":                handleAddNode,
+	"bumpfeepsbt":            handleBumpFeePSBT,
 	"createrawtransaction":   handleCreateRawTransaction,
 	"debuglevel":             handleDebugLevel,
 	"decoderawtransaction":   handleDecodeRawTransaction,
@@ -151,6 +154,7 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getconnectioncount":     handleGetConnectionCount,
 	"getcurrentnet":          handleGetCurrentNet,
 	"getdifficulty":          handleGetDifficulty,
+	"getdustlimits":          handleGetDustLimits,
 	"getgenerate":            handleGetGenerate,
 	"gethashespersec":        handleGetHashesPerSec,
 	"getheaders":             handleGetHeaders,
@@ -159,11 +163,13 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getmininginfo":          handleGetMiningInfo,
 	"getnettotals":           handleGetNetTotals,
 	"getnetworkhashps":       handleGetNetworkHashPS,
+	"getnetworkinfo":         handleGetNetworkInfo,
 	"getnodeaddresses":       handleGetNodeAddresses,
 	"getpeerinfo":            handleGetPeerInfo,
 	"getrawmempool":          handleGetRawMempool,
 	"getrawtransaction":      handleGetRawTransaction,
 	"gettxout":               handleGetTxOut,
+	"gettxrejectioninfo":     handleGetTxRejectionInfo,
 	"help":                   handleHelp,
 	"node":                   handleNode,
 	"ping":                   handlePing,
@@ -233,7 +239,6 @@ var rpcUnimplemented = map[string]struct{}{
 	"estimatepriority": {},
 	"getchaintips":     {},
 	"getmempoolentry":  {},
-	"getnetworkinfo":   {},
 	"getwork":          {},
 	"invalidateblock":  {},
 	"preciousblock":    {},
@@ -245,9 +250,11 @@ var rpcLimited = map[string]struct{}{
 	// Websockets commands
 	"loadtxfilter":          {},
 	"notifyblocks":          {},
+	"notifyblocksfrom":      {},
 	"notifynewtransactions": {},
 	"notifyreceived":        {},
 	"notifyspent":           {},
+	"registerdescriptor":    {},
 	"rescan":                {},
 	"rescanblocks":          {},
 	"session":               {},
@@ -256,6 +263,7 @@ var rpcLimited = map[string]struct{}{
 	"help": {},
 
 	// HTTP/S-only commands
+	"bumpfeepsbt":           {},
 	"createrawtransaction":  {},
 	"decoderawtransaction":  {},
 	"decodescript":          {},
@@ -269,14 +277,17 @@ var rpcLimited = map[string]struct{}{
 	"getcfilter":            {},
 	"getcfilterheader":      {},
 	"getcurrentnet":         {},
+	"getdustlimits":         {},
 	"getdifficulty":         {},
 	"getheaders":            {},
 	"getinfo":               {},
 	"getnettotals":          {},
+	"getnetworkinfo":        {},
 	"getnetworkhashps":      {},
 	"getrawmempool":         {},
 	"getrawtransaction":     {},
 	"gettxout":              {},
+	"gettxrejectioninfo":    {},
 	"searchrawtransactions": {},
 	"sendrawtransaction":    {},
 	"submitblock":           {},
@@ -513,6 +524,153 @@ func messageToHex(msg wire.Message) (string, error) {
 	return hex.EncodeToString(buf.Bytes()), nil
 }
 
+// handleBumpFeePSBT handles bumpfeepsbt commands.
+func handleBumpFeePSBT(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.BumpFeePSBTCmd)
+
+	txHash, err := chainhash.NewHashFromStr(c.Txid)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Txid)
+	}
+
+	method := "rbf"
+	if c.Method != nil {
+		method = *c.Method
+	}
+	if method != "rbf" && method != "cpfp" {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Method must be either \"rbf\" or \"cpfp\"",
+		}
+	}
+
+	// The fee rate is given in BTC/kvB while the mempool works with
+	// satoshi per 1000 virtual bytes.
+	var feeRate btcutil.Amount
+	if c.FeeRate != nil {
+		feeRate, err = btcutil.NewAmount(*c.FeeRate)
+		if err != nil || feeRate < 0 {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Invalid fee rate",
+			}
+		}
+	}
+
+	tx, err := s.cfg.TxMemPool.FetchTransaction(txHash)
+	if err != nil {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+
+	// The output index may only be omitted when there is no choice.
+	outputIndex := 0
+	if c.OutputIndex != nil {
+		outputIndex = *c.OutputIndex
+	} else if len(tx.MsgTx().TxOut) != 1 {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "Output index is required for transactions " +
+				"with multiple outputs",
+		}
+	}
+
+	var bump *mempool.FeeBump
+	if method == "rbf" {
+		bump, err = s.cfg.TxMemPool.BumpFeeRBF(txHash, feeRate, outputIndex)
+	} else {
+		bump, err = s.cfg.TxMemPool.BumpFeeCPFP(txHash, feeRate, outputIndex)
+	}
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+
+	// The mempool only knows about unconfirmed previous transactions, so
+	// include the confirmed ones non-witness inputs must commit to from
+	// the transaction index when it is available.
+	if s.cfg.TxIndex != nil {
+		for i, txIn := range bump.Packet.UnsignedTx.TxIn {
+			input := &bump.Packet.Inputs[i]
+			if input.WitnessUtxo != nil || input.NonWitnessUtxo != nil {
+				continue
+			}
+			prevTx, err := fetchIndexedTx(s, &txIn.PreviousOutPoint.Hash)
+			if err != nil {
+				return nil, err
+			}
+			input.NonWitnessUtxo = prevTx
+		}
+	}
+
+	// Refuse to return a packet which can't be signed since the previous
+	// transactions of some of its inputs are unknown.
+	if missing := bump.MissingUtxoInputs(); len(missing) > 0 {
+		outpoints := make([]string, 0, len(missing))
+		for _, i := range missing {
+			txIn := bump.Packet.UnsignedTx.TxIn[i]
+			outpoints = append(outpoints,
+				txIn.PreviousOutPoint.String())
+		}
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCNoTxInfo,
+			Message: fmt.Sprintf("Previous transactions of inputs "+
+				"spending %s are unavailable without the "+
+				"transaction index (--txindex)",
+				strings.Join(outpoints, ", ")),
+		}
+	}
+
+	packet, err := bump.Packet.B64Encode()
+	if err != nil {
+		context := "Failed to encode packet"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return &btcjson.BumpFeePSBTResult{
+		PSBT:    packet,
+		OrigFee: btcutil.Amount(bump.OrigFee).ToBTC(),
+		Fee:     btcutil.Amount(bump.Fee).ToBTC(),
+		VSize:   bump.VSize,
+		FeeRate: btcutil.Amount(bump.FeeRate).ToBTC(),
+	}, nil
+}
+
+// fetchIndexedTx loads the transaction with the passed hash from the database
+// using the transaction index.
+func fetchIndexedTx(s *rpcServer, txHash *chainhash.Hash) (*wire.MsgTx, error) {
+	// Look up the location of the transaction.
+	blockRegion, err := s.cfg.TxIndex.TxBlockRegion(txHash)
+	if err != nil {
+		context := "Failed to retrieve transaction location"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if blockRegion == nil {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+
+	// Load the raw transaction bytes from the database.
+	var txBytes []byte
+	err = s.cfg.DB.View(func(dbTx database.Tx) error {
+		var err error
+		txBytes, err = dbTx.FetchBlockRegion(blockRegion)
+		return err
+	})
+	if err != nil {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+
+	// Deserialize the transaction
+	var msgTx wire.MsgTx
+	err = msgTx.Deserialize(bytes.NewReader(txBytes))
+	if err != nil {
+		context := "Failed to deserialize transaction"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return &msgTx, nil
+}
+
 // handleCreateRawTransaction handles createrawtransaction commands.
 func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.CreateRawTransactionCmd)
@@ -779,6 +937,18 @@ func createTxRawResult(chainParams *chaincfg.Params, mtx *wire.MsgTx,
 	return txReply, nil
 }
 
+// createTxRawDecodeResult returns the decoderawtransaction JSON result for the
+// passed transaction.
+func createTxRawDecodeResult(mtx *wire.MsgTx, chainParams *chaincfg.Params) *btcjson.TxRawDecodeResult {
+	return &btcjson.TxRawDecodeResult{
+		Txid:     mtx.TxHash().String(),
+		Version:  mtx.Version,
+		Locktime: mtx.LockTime,
+		Vin:      createVinList(mtx),
+		Vout:     createVoutList(mtx, chainParams, nil),
+	}
+}
+
 // handleDecodeRawTransaction handles decoderawtransaction commands.
 func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.DecodeRawTransactionCmd)
@@ -802,30 +972,12 @@ func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 	}
 
 	// Create and return the result.
-	txReply := btcjson.TxRawDecodeResult{
-		Txid:     mtx.TxHash().String(),
-		Version:  mtx.Version,
-		Locktime: mtx.LockTime,
-		Vin:      createVinList(&mtx),
-		Vout:     createVoutList(&mtx, s.cfg.ChainParams, nil),
-	}
-	return txReply, nil
+	return *createTxRawDecodeResult(&mtx, s.cfg.ChainParams), nil
 }
 
-// handleDecodeScript handles decodescript commands.
-func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	c := cmd.(*btcjson.DecodeScriptCmd)
-
-	// Convert the hex script to bytes.
-	hexStr := c.HexScript
-	if len(hexStr)%2 != 0 {
-		hexStr = "0" + hexStr
-	}
-	script, err := hex.DecodeString(hexStr)
-	if err != nil {
-		return nil, rpcDecodeHexError(hexStr)
-	}
-
+// createDecodeScriptResult returns the decodescript JSON result for the passed
+// script using the given chain parameters to encode any addresses.
+func createDecodeScriptResult(script []byte, chainParams *chaincfg.Params) (*btcjson.DecodeScriptResult, error) {
 	// The disassembled string will contain [error] inline if the script
 	// doesn't fully parse, so ignore the error here.
 	disbuf, _ := txscript.DisasmString(script)
@@ -834,21 +986,20 @@ func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	// Ignore the error here since an error means the script couldn't parse
 	// and there is no additinal information about it anyways.
 	scriptClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(script,
-		s.cfg.ChainParams)
+		chainParams)
 	addresses := make([]string, len(addrs))
 	for i, addr := range addrs {
 		addresses[i] = addr.EncodeAddress()
 	}
 
 	// Convert the script itself to a pay-to-script-hash address.
-	p2sh, err := btcutil.NewAddressScriptHash(script, s.cfg.ChainParams)
+	p2sh, err := btcutil.NewAddressScriptHash(script, chainParams)
 	if err != nil {
-		context := "Failed to convert script to pay-to-script-hash"
-		return nil, internalRPCError(err.Error(), context)
+		return nil, err
 	}
 
 	// Generate and return the reply.
-	reply := btcjson.DecodeScriptResult{
+	reply := &btcjson.DecodeScriptResult{
 		Asm:       disbuf,
 		ReqSigs:   int32(reqSigs),
 		Type:      scriptClass.String(),
@@ -860,6 +1011,28 @@ func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return reply, nil
 }
 
+// handleDecodeScript handles decodescript commands.
+func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DecodeScriptCmd)
+
+	// Convert the hex script to bytes.
+	hexStr := c.HexScript
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	script, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+
+	reply, err := createDecodeScriptResult(script, s.cfg.ChainParams)
+	if err != nil {
+		context := "Failed to convert script to pay-to-script-hash"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return *reply, nil
+}
+
 // handleEstimateFee handles estimatefee commands.
 func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.EstimateFeeCmd)
@@ -1240,6 +1413,15 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 		},
 	}
 
+	// Report the script verification flags the next block is validated
+	// with, which includes any configured script flag overrides.
+	scriptFlags, err := chain.NextScriptFlags()
+	if err != nil {
+		context := "Failed to obtain script verification flags"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	chainInfo.ScriptFlags = scriptFlags.Names()
+
 	// Finally, query the BIP0009 version bits state for all currently
 	// defined BIP0009 soft-fork deployments.
 	for deployment, deploymentDetails := range params.Deployments {
@@ -2268,6 +2450,51 @@ func handleGetDifficulty(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return getDifficultyRatio(best.Bits, s.cfg.ChainParams), nil
 }
 
+// dustLimitScripts houses a representative public key script for each output
+// type reported by the getdustlimits command.  Only the type and size of the
+// scripts matter, so the keys and hashes they commit to are all zero.
+var dustLimitScripts = func() [][]byte {
+	hash20 := make([]byte, 20)
+	hash32 := make([]byte, 32)
+	pubKey := append([]byte{0x02}, hash32...)
+
+	return [][]byte{
+		append(append([]byte{txscript.OP_DATA_33}, pubKey...),
+			txscript.OP_CHECKSIG),
+		append(append([]byte{txscript.OP_DUP, txscript.OP_HASH160,
+			txscript.OP_DATA_20}, hash20...), txscript.OP_EQUALVERIFY,
+			txscript.OP_CHECKSIG),
+		append(append([]byte{txscript.OP_HASH160, txscript.OP_DATA_20},
+			hash20...), txscript.OP_EQUAL),
+		append([]byte{txscript.OP_0, txscript.OP_DATA_20}, hash20...),
+		append([]byte{txscript.OP_0, txscript.OP_DATA_32}, hash32...),
+		append([]byte{txscript.OP_1, txscript.OP_DATA_32}, hash32...),
+	}
+}()
+
+// handleGetDustLimits implements the getdustlimits command.
+func handleGetDustLimits(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	outputs := make([]btcjson.DustLimitResult, 0, len(dustLimitScripts))
+	for _, pkScript := range dustLimitScripts {
+		txOut := wire.TxOut{PkScript: pkScript}
+		threshold := mempool.MinNonDustValue(pkScript, cfg.minRelayTxFee,
+			s.cfg.ChainParams)
+		outputs = append(outputs, btcjson.DustLimitResult{
+			Type:       txscript.GetScriptClass(pkScript).String(),
+			OutputSize: int64(txOut.SerializeSize()),
+			InputSize:  int64(mempool.TypicalInputSize(pkScript)),
+			Threshold:  btcutil.Amount(threshold).ToBTC(),
+		})
+	}
+
+	return &btcjson.GetDustLimitsResult{
+		RelayFee:      cfg.minRelayTxFee.ToBTC(),
+		HardDustLimit: btcutil.Amount(s.cfg.ChainParams.HardDustLimit).ToBTC(),
+		SoftDustLimit: btcutil.Amount(s.cfg.ChainParams.SoftDustLimit).ToBTC(),
+		Outputs:       outputs,
+	}, nil
+}
+
 // handleGetGenerate implements the getgenerate command.
 func handleGetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return s.cfg.CPUMiner.IsMining(), nil
@@ -2496,6 +2723,156 @@ func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hashesPerSec, nil
 }
 
+// serviceFlagNames maps the service flags to the names the reference
+// implementation reports them with in the localservicesnames field of
+// getnetworkinfo.
+var serviceFlagNames = []struct {
+	flag wire.ServiceFlag
+	name string
+}{
+	{wire.SFNodeNetwork, "NETWORK"},
+	{wire.SFNodeGetUTXO, "GETUTXO"},
+	{wire.SFNodeBloom, "BLOOM"},
+	{wire.SFNodeWitness, "WITNESS"},
+	{wire.SFNodeCF, "COMPACT_FILTERS"},
+}
+
+// transportFeatures maps the optional peer-to-peer protocol features the
+// server negotiates with peers to the protocol version which introduced them.
+// Relaying transactions by witness hash is not supported and thus not listed.
+var transportFeatures = []struct {
+	version uint32
+	name    string
+}{
+	{wire.SendHeadersVersion, "sendheaders"},
+	{wire.FeeFilterVersion, "feefilter"},
+	{wire.AddrV2Version, "addrv2"},
+}
+
+// localTransportFeatures returns the names of the optional peer-to-peer
+// protocol features supported by the passed protocol version.
+func localTransportFeatures(pver uint32) []string {
+	names := make([]string, 0, len(transportFeatures))
+	for _, feature := range transportFeatures {
+		if pver >= feature.version {
+			names = append(names, feature.name)
+		}
+	}
+	return names
+}
+
+// serviceNames returns the names of the passed service flags.  Unknown flags
+// are named by their bit as UNKNOWN[2^bit] like the reference implementation.
+func serviceNames(services wire.ServiceFlag) []string {
+	names := make([]string, 0, len(serviceFlagNames))
+	for _, sf := range serviceFlagNames {
+		if services&sf.flag == sf.flag {
+			names = append(names, sf.name)
+			services &^= sf.flag
+		}
+	}
+	for bit := uint(0); bit < 64; bit++ {
+		if services&(1<<bit) != 0 {
+			names = append(names, fmt.Sprintf("UNKNOWN[2^%d]", bit))
+		}
+	}
+	return names
+}
+
+// networkInfoNetworks returns the reachability of the networks reported by
+// getnetworkinfo based on the configured proxies.  Peers on the clearnet are
+// always reachable, onion services are reachable unless disabled and there is
+// no support for I2P or CJDNS.
+func networkInfoNetworks() []btcjson.NetworksResult {
+	onionProxy := cfg.OnionProxy
+	if onionProxy == "" {
+		onionProxy = cfg.Proxy
+	}
+	onionReachable := !cfg.NoOnion && onionProxy != ""
+	if !onionReachable {
+		onionProxy = ""
+	}
+
+	networks := []struct {
+		name      string
+		reachable bool
+		proxy     string
+	}{
+		{"ipv4", true, cfg.Proxy},
+		{"ipv6", true, cfg.Proxy},
+		{"onion", onionReachable, onionProxy},
+		{"i2p", false, ""},
+		{"cjdns", false, ""},
+	}
+	results := make([]btcjson.NetworksResult, 0, len(networks))
+	for _, network := range networks {
+		results = append(results, btcjson.NetworksResult{
+			Name:                      network.name,
+			Limited:                   !network.reachable,
+			Reachable:                 network.reachable,
+			Proxy:                     network.proxy,
+			ProxyRandomizeCredentials: network.proxy != "" && cfg.TorIsolation,
+		})
+	}
+	return results
+}
+
+// handleGetNetworkInfo implements the getnetworkinfo command.
+func handleGetNetworkInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Build the user agent advertised to peers.
+	msg := wire.MsgVersion{UserAgent: wire.DefaultUserAgent}
+	err := msg.AddUserAgent(userAgentName, userAgentVersion,
+		cfg.UserAgentComments...)
+	if err != nil {
+		context := "Failed to build user agent"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	var inbound, outbound int32
+	for _, p := range s.cfg.ConnMgr.ConnectedPeers() {
+		if p.ToPeer().Inbound() {
+			inbound++
+		} else {
+			outbound++
+		}
+	}
+
+	localAddrs := s.cfg.ConnMgr.LocalAddresses()
+	localAddresses := make([]btcjson.LocalAddressesResult, 0,
+		len(localAddrs))
+	for _, la := range localAddrs {
+		localAddresses = append(localAddresses, btcjson.LocalAddressesResult{
+			Address: la.NetAddress.Addr.String(),
+			Port:    la.NetAddress.Port,
+			Score:   int32(la.Score),
+		})
+	}
+	sort.Slice(localAddresses, func(i, j int) bool {
+		return localAddresses[i].Score > localAddresses[j].Score
+	})
+
+	services := s.cfg.ConnMgr.LocalServices()
+	reply := &btcjson.GetNetworkInfoResult{
+		Version:                int32(1000000*appMajor + 10000*appMinor + 100*appPatch),
+		SubVersion:             msg.UserAgent,
+		ProtocolVersion:        int32(peer.MaxProtocolVersion),
+		LocalServices:          fmt.Sprintf("%016x", uint64(services)),
+		LocalServicesNames:     serviceNames(services),
+		LocalTransportFeatures: localTransportFeatures(peer.MaxProtocolVersion),
+		LocalRelay:             !cfg.BlocksOnly,
+		TimeOffset:             int64(s.cfg.TimeSource.Offset().Seconds()),
+		Connections:            inbound + outbound,
+		ConnectionsIn:          inbound,
+		ConnectionsOut:         outbound,
+		NetworkActive:          true,
+		Networks:               networkInfoNetworks(),
+		RelayFee:               cfg.minRelayTxFee.ToBTC(),
+		IncrementalFee:         s.cfg.TxMemPool.IncrementalRelayFee().ToBTC(),
+		LocalAddresses:         localAddresses,
+	}
+	return reply, nil
+}
+
 // handleGetNodeAddresses implements the getnodeaddresses command.
 func handleGetNodeAddresses(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetNodeAddressesCmd)
@@ -2814,6 +3191,39 @@ func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	return txOutReply, nil
 }
 
+// handleGetTxRejectionInfo implements the gettxrejectioninfo command.
+func handleGetTxRejectionInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetTxRejectionInfoCmd)
+
+	// Convert the provided transaction hash hex to a Hash.  It may be the
+	// hash of the transaction with or without its witness.
+	txHash, err := chainhash.NewHashFromStr(c.Txid)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Txid)
+	}
+
+	var rejected []*mempool.RejectedTx
+	if s.cfg.RejectFilter != nil {
+		rejected = s.cfg.RejectFilter.Lookup(txHash)
+	}
+	if len(rejected) == 0 {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+
+	results := make([]btcjson.GetTxRejectionInfoResult, 0, len(rejected))
+	for _, r := range rejected {
+		results = append(results, btcjson.GetTxRejectionInfoResult{
+			Txid:         r.Hash.String(),
+			Hash:         r.WitnessHash.String(),
+			RejectCode:   uint8(r.RejectCode),
+			RejectReason: r.Reason,
+			Time:         r.Time.Unix(),
+			Height:       r.Height,
+		})
+	}
+	return results, nil
+}
+
 // handleHelp implements the help command.
 func handleHelp(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.HelpCmd)
@@ -2903,33 +3313,11 @@ func fetchInputTxos(s *rpcServer, tx *wire.MsgTx) (map[wire.OutPoint]wire.TxOut,
 			continue
 		}
 
-		// Look up the location of the transaction.
-		blockRegion, err := s.cfg.TxIndex.TxBlockRegion(&origin.Hash)
-		if err != nil {
-			context := "Failed to retrieve transaction location"
-			return nil, internalRPCError(err.Error(), context)
-		}
-		if blockRegion == nil {
-			return nil, rpcNoTxInfoError(&origin.Hash)
-		}
-
-		// Load the raw transaction bytes from the database.
-		var txBytes []byte
-		err = s.cfg.DB.View(func(dbTx database.Tx) error {
-			var err error
-			txBytes, err = dbTx.FetchBlockRegion(blockRegion)
-			return err
-		})
-		if err != nil {
-			return nil, rpcNoTxInfoError(&origin.Hash)
-		}
-
-		// Deserialize the transaction
-		var msgTx wire.MsgTx
-		err = msgTx.Deserialize(bytes.NewReader(txBytes))
+		// Load the transaction from the database using the
+		// transaction index.
+		msgTx, err := fetchIndexedTx(s, &origin.Hash)
 		if err != nil {
-			context := "Failed to deserialize transaction"
-			return nil, internalRPCError(err.Error(), context)
+			return nil, err
 		}
 
 		// Add the referenced output to the map.
@@ -3875,6 +4263,12 @@ func (s *rpcServer) NotifyNewTransactions(txns []*mempool.TxDesc) {
 	}
 }
 
+// NotifyExpiredTransaction notifies websocket clients of the passed
+// transaction which was evicted from the mempool since it expired.
+func (s *rpcServer) NotifyExpiredTransaction(txD *mempool.TxDesc) {
+	s.ntfnMgr.NotifyMempoolTxExpired(txD.Tx)
+}
+
 // limitConnections responds with a 503 service unavailable and returns true if
 // adding another client would exceed the maximum allow RPC clients.
 //
@@ -4385,6 +4779,61 @@ func (s *rpcServer) Start() {
 		s.jsonRPCRead(w, r, isAdmin)
 	})
 
+	// Machine-readable specification of the supported commands.  Limited
+	// users are only shown the commands they are allowed to use.
+	rpcServeMux.HandleFunc("/spec", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		r.Close = true
+
+		if s.limitConnections(w, r.RemoteAddr) {
+			return
+		}
+		s.incrementClients()
+		defer s.decrementClients()
+		_, isAdmin, err := s.checkAuth(r, true)
+		if err != nil {
+			jsonAuthFail(w)
+			return
+		}
+
+		spec, err := s.helpCacher.rpcSpec(!isAdmin)
+		if err != nil {
+			rpcsLog.Errorf("Failed to generate RPC specification: %v",
+				err)
+			errCode := http.StatusInternalServerError
+			http.Error(w, strconv.Itoa(errCode)+" "+
+				http.StatusText(errCode), errCode)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(spec); err != nil {
+			rpcsLog.Errorf("Failed to write RPC specification: %v",
+				err)
+		}
+	})
+
+	// Embedded block explorer.  It is only served to admin users since
+	// it exposes information such as the connected peers.
+	if cfg.Explorer {
+		rpcServeMux.HandleFunc(explorerPathPrefix, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Connection", "close")
+			r.Close = true
+
+			if s.limitConnections(w, r.RemoteAddr) {
+				return
+			}
+			s.incrementClients()
+			defer s.decrementClients()
+			_, isAdmin, err := s.checkAuth(r, true)
+			if err != nil || !isAdmin {
+				jsonAuthFail(w)
+				return
+			}
+
+			s.handleExplorer(w, r)
+		})
+	}
+
 	// Websocket endpoint.
 	rpcServeMux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		authenticated, isAdmin, err := s.checkAuth(r, false)
@@ -4528,6 +4977,13 @@ type rpcserverConnManager interface {
 	// NodeAddresses returns an array consisting node addresses which can
 	// potentially be used to find new nodes in the network.
 	NodeAddresses() []*wire.NetAddressV2
+
+	// LocalAddresses returns the local addresses advertised to peers along
+	// with their scores.
+	LocalAddresses() []addrmgr.LocalAddress
+
+	// LocalServices returns the services advertised to peers.
+	LocalServices() wire.ServiceFlag
 }
 
 // rpcserverSyncManager represents a sync manager for use with the RPC server.
@@ -4605,6 +5061,10 @@ type rpcserverConfig struct {
 	// The fee estimator keeps track of how long transactions are left in
 	// the mempool before they are mined into blocks.
 	FeeEstimator *mempool.FeeEstimator
+
+	// RejectFilter remembers the transactions which were rejected for
+	// being invalid under the consensus rules along with the reason.
+	RejectFilter *mempool.RejectFilter
 }
 
 // newRPCServer returns a new instance of the rpcServer struct.
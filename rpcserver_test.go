@@ -0,0 +1,54 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dogesuite/doged/peer"
+	"github.com/dogesuite/doged/wire"
+)
+
+// TestServiceNames ensures service flags are named like the reference
+// implementation names them in getnetworkinfo.
+func TestServiceNames(t *testing.T) {
+	tests := []struct {
+		services wire.ServiceFlag
+		want     []string
+	}{
+		{0, []string{}},
+		{defaultServices, []string{"NETWORK", "BLOOM", "WITNESS",
+			"COMPACT_FILTERS"}},
+		{wire.SFNodeNetwork | wire.SFNodeXthin | 1<<40,
+			[]string{"NETWORK", "UNKNOWN[2^4]", "UNKNOWN[2^40]"}},
+	}
+	for i, test := range tests {
+		got := serviceNames(test.services)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("test #%d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+// TestLocalTransportFeatures ensures the optional protocol features reported
+// by getnetworkinfo are the ones supported by the protocol version.
+func TestLocalTransportFeatures(t *testing.T) {
+	tests := []struct {
+		pver uint32
+		want []string
+	}{
+		{wire.RejectVersion, []string{}},
+		{wire.FeeFilterVersion, []string{"sendheaders", "feefilter"}},
+		{peer.MaxProtocolVersion, []string{"sendheaders", "feefilter",
+			"addrv2"}},
+	}
+	for i, test := range tests {
+		got := localTransportFeatures(test.pver)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("test #%d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
@@ -6,6 +6,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"sort"
 	"strings"
@@ -40,6 +41,24 @@ var helpDescsEnUS = map[string]string{
 	"node-target":        "Either the IP address and port of the peer to operate on, or a valid peer ID.",
 	"node-connectsubcmd": "'perm' to make the connected peer a permanent one, 'temp' to try a single connect to a peer",
 
+	// BumpFeePSBTCmd help.
+	"bumpfeepsbt--synopsis": "Returns an unsigned PSBT which raises the fee rate of a transaction in the memory pool.\n" +
+		"The 'rbf' method replaces the transaction with one spending the same inputs, funding the additional fee from the change output at the given index, which is dropped when it would become dust.\n" +
+		"The 'cpfp' method spends the output at the given index back to the same script in a child paying for the combined size of the parent and child.\n" +
+		"The inputs include the previous outputs known to the server so the packet can be signed directly.\n" +
+		"Non-witness inputs spending confirmed outputs require the transaction index (--txindex) to include their previous transactions, otherwise an error is returned.",
+	"bumpfeepsbt-txid":        "The hash of the transaction to bump",
+	"bumpfeepsbt-method":      "'rbf' to build a replacement or 'cpfp' to build a child",
+	"bumpfeepsbt-feerate":     "The target fee rate in BTC/kvB, or 0 for the minimum fee rate allowed",
+	"bumpfeepsbt-outputindex": "The change output to reduce for 'rbf' or the output to spend for 'cpfp' (optional for transactions with a single output)",
+
+	// BumpFeePSBTResult help.
+	"bumpfeepsbtresult-psbt":    "The base64-encoded unsigned packet",
+	"bumpfeepsbtresult-origfee": "The fee in BTC paid by the bumped transaction",
+	"bumpfeepsbtresult-fee":     "The fee in BTC paid by the new transaction",
+	"bumpfeepsbtresult-vsize":   "The estimated virtual size of the new transaction once signed",
+	"bumpfeepsbtresult-feerate": "The resulting fee rate in BTC/kvB, combined with the parent for 'cpfp'",
+
 	// TransactionInput help.
 	"transactioninput-txid": "The hash of the input transaction",
 	"transactioninput-vout": "The specific output of the input transaction to redeem",
@@ -183,6 +202,7 @@ var helpDescsEnUS = map[string]string{
 	"getblockchaininforesult-chainwork":            "The total cumulative work in the best chain",
 	"getblockchaininforesult-size_on_disk":         "The estimated size of the block and undo files on disk",
 	"getblockchaininforesult-initialblockdownload": "Estimate of whether this node is in Initial Block Download mode",
+	"getblockchaininforesult-scriptflags":          "The script verification flags the next block is validated with, including any flags overridden on test networks",
 	"getblockchaininforesult-softforks":            "The status of the super-majority soft-forks",
 	"getblockchaininforesult-unifiedsoftforks":     "The status of the super-majority soft-forks used by bitcoind on or after v0.19.0",
 
@@ -371,6 +391,23 @@ var helpDescsEnUS = map[string]string{
 	"getdifficulty--synopsis": "Returns the proof-of-work difficulty as a multiple of the minimum difficulty.",
 	"getdifficulty--result0":  "The difficulty",
 
+	// GetDustLimitsCmd help.
+	"getdustlimits--synopsis": "Returns the smallest output values which are not considered dust for the common output types.\n" +
+		"The thresholds account for the size of a typical input spending each output type at the current minimum relay fee and never fall below the hard dust limit of the network.\n" +
+		"Outputs below the soft dust limit of the network are not dust, but each of them requires an additional fee of the soft dust limit.",
+
+	// GetDustLimitsResult help.
+	"getdustlimitsresult-relayfee":      "Minimum relay fee in BTC/kB used to derive the thresholds",
+	"getdustlimitsresult-harddustlimit": "Fixed minimum value in BTC of standard outputs on the network",
+	"getdustlimitsresult-softdustlimit": "Value in BTC below which each output requires an additional fee of this amount",
+	"getdustlimitsresult-outputs":       "Dust threshold of each output type",
+
+	// DustLimitResult help.
+	"dustlimitresult-type":       "The type of the output script (e.g. 'pubkeyhash')",
+	"dustlimitresult-outputsize": "Serialized size in bytes of an output of the type",
+	"dustlimitresult-inputsize":  "Size in virtual bytes of a typical input spending an output of the type",
+	"dustlimitresult-threshold":  "Smallest value in BTC of an output of the type which is not dust",
+
 	// GetGenerateCmd help.
 	"getgenerate--synopsis": "Returns if the server is set to generate coins (mine) or not.",
 	"getgenerate--result0":  "True if mining, false if not",
@@ -456,6 +493,40 @@ var helpDescsEnUS = map[string]string{
 	"getnettotalsresult-totalbytessent": "Total bytes sent",
 	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
 
+	// GetNetworkInfoCmd help.
+	"getnetworkinfo--synopsis": "Returns a JSON object containing information about the peer-to-peer network and the relay policy of the server.",
+
+	// GetNetworkInfoResult help.
+	"getnetworkinforesult-version":                "The version of the server",
+	"getnetworkinforesult-subversion":             "The user agent the server advertises to peers",
+	"getnetworkinforesult-protocolversion":        "The latest supported protocol version",
+	"getnetworkinforesult-localservices":          "The services the server offers to peers as a hex-encoded bit field",
+	"getnetworkinforesult-localservicesnames":     "The names of the services the server offers to peers",
+	"getnetworkinforesult-localtransportfeatures": "The optional peer-to-peer protocol features the server negotiates with peers which support them (sendheaders, feefilter and addrv2)",
+	"getnetworkinforesult-localrelay":             "Whether or not transactions are relayed to and requested from peers",
+	"getnetworkinforesult-timeoffset":             "The time offset",
+	"getnetworkinforesult-connections":            "The number of connected peers",
+	"getnetworkinforesult-connections_in":         "The number of inbound peers",
+	"getnetworkinforesult-connections_out":        "The number of outbound peers",
+	"getnetworkinforesult-networkactive":          "Whether or not peer-to-peer networking is enabled",
+	"getnetworkinforesult-networks":               "Information about the reachability of each network",
+	"getnetworkinforesult-relayfee":               "The minimum relay fee for transactions in BTC/kvB",
+	"getnetworkinforesult-incrementalfee":         "The minimum fee rate increase for replacing mempool transactions in BTC/kvB",
+	"getnetworkinforesult-localaddresses":         "The local addresses advertised to peers",
+	"getnetworkinforesult-warnings":               "Any network and blockchain warnings",
+
+	// NetworksResult help.
+	"networksresult-name":                        "The network (ipv4, ipv6, onion, i2p or cjdns)",
+	"networksresult-limited":                     "Whether or not connections are restricted to other networks",
+	"networksresult-reachable":                   "Whether or not peers on the network can be connected to",
+	"networksresult-proxy":                       "The proxy used for the network, if any",
+	"networksresult-proxy_randomize_credentials": "Whether or not random credentials are used for the proxy to isolate streams",
+
+	// LocalAddressesResult help.
+	"localaddressesresult-address": "The local address",
+	"localaddressesresult-port":    "The local port",
+	"localaddressesresult-score":   "The relative preference of the address",
+
 	// GetNodeAddressesResult help.
 	"getnodeaddressesresult-time":     "Timestamp in seconds since epoch (Jan 1 1970 GMT) keeping track of when the node was last seen",
 	"getnodeaddressesresult-services": "The services offered",
@@ -533,6 +604,20 @@ var helpDescsEnUS = map[string]string{
 	"gettxout-vout":           "The index of the output",
 	"gettxout-includemempool": "Include the mempool when true",
 
+	// GetTxRejectionInfoCmd help.
+	"gettxrejectioninfo--synopsis": "Returns why a transaction was rejected for being invalid under the consensus rules.\n" +
+		"A bounded number of the most recent rejections are remembered across restarts, while transactions rejected due to policy, such as insufficient fees, are never remembered.",
+	"gettxrejectioninfo-txid":     "The hash of the transaction with or without its witness",
+	"gettxrejectioninfo--result0": "The rejected transactions with the hash, which differ in their witnesses when there are several, from the oldest to the most recent rejection",
+
+	// GetTxRejectionInfoResult help.
+	"gettxrejectioninforesult-txid":         "The hash of the transaction",
+	"gettxrejectioninforesult-hash":         "The hash of the transaction including its witness",
+	"gettxrejectioninforesult-rejectcode":   "The reject code sent to peers",
+	"gettxrejectioninforesult-rejectreason": "The reason the transaction was rejected",
+	"gettxrejectioninforesult-time":         "The time the transaction was rejected in seconds since 1 Jan 1970 GMT",
+	"gettxrejectioninforesult-height":       "The height of the best chain when the transaction was rejected",
+
 	// HelpCmd help.
 	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
 	"help-command":     "The command to retrieve help for",
@@ -633,11 +718,19 @@ var helpDescsEnUS = map[string]string{
 	// NotifyBlocksCmd help.
 	"notifyblocks--synopsis": "Request notifications for whenever a block is connected or disconnected from the main (best) chain.",
 
+	// NotifyBlocksFromCmd help.
+	"notifyblocksfrom--synopsis": "Request notifications for whenever a block is connected or disconnected from the main (best) chain, starting with the notifications needed to bring the client from the passed block up to the current best block.\n" +
+		"Blocks of the passed block's side chain are disconnected back to the main chain before the main chain blocks after it are connected.\n" +
+		"The replay is followed by the notifications for new blocks without any gaps or duplicates in between.\n" +
+		"At most 1440 blocks are replayed, clients which are further behind need to catch up with rescanblocks first.\n" +
+		"Replaces the notifications requested with notifyblocks.",
+	"notifyblocksfrom-hash": "The hash of the last block processed by the client",
+
 	// StopNotifyBlocksCmd help.
 	"stopnotifyblocks--synopsis": "Cancel registered notifications for whenever a block is connected or disconnected from the main (best) chain.",
 
 	// NotifyNewTransactionsCmd help.
-	"notifynewtransactions--synopsis": "Send either a txaccepted or a txacceptedverbose notification when a new transaction is accepted into the mempool.",
+	"notifynewtransactions--synopsis": "Send either a txaccepted or a txacceptedverbose notification when a new transaction is accepted into the mempool and a txexpired notification when a transaction expires from it.",
 	"notifynewtransactions-verbose":   "Specifies which type of notification to receive. If verbose is true, then the caller receives txacceptedverbose, otherwise the caller receives txaccepted",
 
 	// StopNotifyNewTransactionsCmd help.
@@ -666,10 +759,26 @@ var helpDescsEnUS = map[string]string{
 
 	// LoadTxFilterCmd help.
 	"loadtxfilter--synopsis": "Load, add to, or reload a websocket client's transaction filter for mempool transactions, new blocks and rescanblocks.",
-	"loadtxfilter-reload":    "Load a new filter instead of adding data to an existing one",
+	"loadtxfilter-reload":    "Load a new filter instead of adding data to an existing one, keeping the descriptors registered with registerdescriptor",
 	"loadtxfilter-addresses": "Array of addresses to add to the transaction filter",
 	"loadtxfilter-outpoints": "Array of outpoints to add to the transaction filter",
 
+	// RegisterDescriptorCmd help.
+	"registerdescriptor--synopsis": "Register a ranged output descriptor with a websocket client's transaction filter, creating the filter if necessary.\n" +
+		"The addresses of the descriptor are derived ahead up to the gap limit and added to the filter.\n" +
+		"As transactions paying to them are observed in the mempool, new blocks and rescanblocks, further addresses are derived so the gap limit of unused addresses always follows the highest used one, which is reported with a descriptorextended notification.\n" +
+		"Registering an already registered descriptor updates its gap limit and reports its current state.\n" +
+		"At most 16 descriptors can be registered by a client and they are kept when the filter is reloaded with loadtxfilter.\n" +
+		"Supported are pkh, wpkh, sh(wpkh) and tr descriptors without script trees of extended public keys ending in an unhardened wildcard.",
+	"registerdescriptor-descriptor": "The ranged output descriptor with an optional checksum (e.g. 'wpkh(xpub.../0/*)')",
+	"registerdescriptor-gaplimit":   "The number of unused addresses to derive ahead of the highest used one",
+
+	// RegisterDescriptorResult help.
+	"registerdescriptorresult-descriptor": "The descriptor including its checksum",
+	"registerdescriptorresult-gaplimit":   "The number of unused addresses derived ahead of the highest used one",
+	"registerdescriptorresult-nextindex":  "The index of the next unused address, following the highest used one",
+	"registerdescriptorresult-derived":    "The number of addresses derived and monitored starting at index zero",
+
 	// Rescan help.
 	"rescan--synopsis": "Rescan block chain for transactions to addresses.\n" +
 		"When the endblock parameter is omitted, the rescan continues through the best block in the main chain.\n" +
@@ -713,6 +822,7 @@ var helpDescsEnUS = map[string]string{
 // pointer to the type (or nil to indicate no return value).
 var rpcResultTypes = map[string][]interface{}{
 	"addnode":                nil,
+	"bumpfeepsbt":            {(*btcjson.BumpFeePSBTResult)(nil)},
 	"createrawtransaction":   {(*string)(nil)},
 	"debuglevel":             {(*string)(nil), (*string)(nil)},
 	"decoderawtransaction":   {(*btcjson.TxRawDecodeResult)(nil)},
@@ -733,6 +843,7 @@ var rpcResultTypes = map[string][]interface{}{
 	"getconnectioncount":     {(*int32)(nil)},
 	"getcurrentnet":          {(*uint32)(nil)},
 	"getdifficulty":          {(*float64)(nil)},
+	"getdustlimits":          {(*btcjson.GetDustLimitsResult)(nil)},
 	"getgenerate":            {(*bool)(nil)},
 	"gethashespersec":        {(*float64)(nil)},
 	"getheaders":             {(*[]string)(nil)},
@@ -741,11 +852,13 @@ var rpcResultTypes = map[string][]interface{}{
 	"getmininginfo":          {(*btcjson.GetMiningInfoResult)(nil)},
 	"getnettotals":           {(*btcjson.GetNetTotalsResult)(nil)},
 	"getnetworkhashps":       {(*float64)(nil)},
+	"getnetworkinfo":         {(*btcjson.GetNetworkInfoResult)(nil)},
 	"getnodeaddresses":       {(*[]btcjson.GetNodeAddressesResult)(nil)},
 	"getpeerinfo":            {(*[]btcjson.GetPeerInfoResult)(nil)},
 	"getrawmempool":          {(*[]string)(nil), (*btcjson.GetRawMempoolVerboseResult)(nil)},
 	"getrawtransaction":      {(*string)(nil), (*btcjson.TxRawResult)(nil)},
 	"gettxout":               {(*btcjson.GetTxOutResult)(nil)},
+	"gettxrejectioninfo":     {(*[]btcjson.GetTxRejectionInfoResult)(nil)},
 	"node":                   nil,
 	"help":                   {(*string)(nil), (*string)(nil)},
 	"ping":                   nil,
@@ -765,6 +878,7 @@ var rpcResultTypes = map[string][]interface{}{
 	"loadtxfilter":              nil,
 	"session":                   {(*btcjson.SessionResult)(nil)},
 	"notifyblocks":              nil,
+	"notifyblocksfrom":          nil,
 	"stopnotifyblocks":          nil,
 	"notifynewtransactions":     nil,
 	"stopnotifynewtransactions": nil,
@@ -772,6 +886,7 @@ var rpcResultTypes = map[string][]interface{}{
 	"stopnotifyreceived":        nil,
 	"notifyspent":               nil,
 	"stopnotifyspent":           nil,
+	"registerdescriptor":        {(*btcjson.RegisterDescriptorResult)(nil)},
 	"rescan":                    nil,
 	"rescanblocks":              {(*[]btcjson.RescannedBlock)(nil)},
 }
@@ -782,6 +897,7 @@ type helpCacher struct {
 	sync.Mutex
 	usage      string
 	methodHelp map[string]string
+	spec       map[bool][]byte
 }
 
 // rpcMethodHelp returns an RPC help string for the provided method.
@@ -850,10 +966,71 @@ func (c *helpCacher) rpcUsage(includeWebsockets bool) (string, error) {
 	return c.usage, nil
 }
 
+// rpcSpec returns the OpenRPC document describing all supported RPC commands,
+// including the websocket commands, marshalled to JSON.  When the limited flag
+// is set, only the commands available to limited users are included.
+//
+// This function is safe for concurrent access.
+func (c *helpCacher) rpcSpec(limited bool) ([]byte, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Return the cached spec if it is available.
+	if spec, ok := c.spec[limited]; ok {
+		return spec, nil
+	}
+
+	// Generate the schema for every command the user has access to.
+	methods := make([]*btcjson.MethodSchema, 0, len(rpcHandlers)+
+		len(wsHandlers))
+	added := make(map[string]struct{})
+	addMethod := func(method string) error {
+		if _, ok := rpcLimited[method]; limited && !ok {
+			return nil
+		}
+		if _, ok := added[method]; ok {
+			return nil
+		}
+		added[method] = struct{}{}
+		resultTypes, ok := rpcResultTypes[method]
+		if !ok {
+			return errors.New("no result types specified for " +
+				"method " + method)
+		}
+		schema, err := btcjson.GenerateMethodSchema(method,
+			helpDescsEnUS, resultTypes...)
+		if err != nil {
+			return err
+		}
+		methods = append(methods, schema)
+		return nil
+	}
+	for k := range rpcHandlers {
+		if err := addMethod(k); err != nil {
+			return nil, err
+		}
+	}
+	for k := range wsHandlers {
+		if err := addMethod(k); err != nil {
+			return nil, err
+		}
+	}
+
+	doc := btcjson.NewOpenRPCDocument("btcd JSON-RPC API", version(),
+		methods)
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	c.spec[limited] = spec
+	return spec, nil
+}
+
 // newHelpCacher returns a new instance of a help cacher which provides help and
 // usage for the RPC server commands and caches the results for future calls.
 func newHelpCacher() *helpCacher {
 	return &helpCacher{
 		methodHelp: make(map[string]string),
+		spec:       make(map[bool][]byte),
 	}
 }
@@ -4,7 +4,12 @@
 
 package main
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dogesuite/doged/btcjson"
+)
 
 // TestHelp ensures the help is reasonably accurate by checking that every
 // command specified also has result types defined and the one-line usage and
@@ -63,3 +68,50 @@ func TestHelp(t *testing.T) {
 		}
 	}
 }
+
+// TestSpec ensures the OpenRPC document describes every supported command and
+// that limited users are only shown the commands they are allowed to use.
+func TestSpec(t *testing.T) {
+	helpCacher := newHelpCacher()
+	for _, limited := range []bool{false, true} {
+		spec, err := helpCacher.rpcSpec(limited)
+		if err != nil {
+			t.Fatalf("Failed to generate spec (limited %v): %v",
+				limited, err)
+		}
+		var doc btcjson.OpenRPCDocument
+		if err := json.Unmarshal(spec, &doc); err != nil {
+			t.Fatalf("Failed to unmarshal spec (limited %v): %v",
+				limited, err)
+		}
+
+		described := make(map[string]struct{}, len(doc.Methods))
+		for _, method := range doc.Methods {
+			if _, ok := described[method.Name]; ok {
+				t.Errorf("Spec describes method '%v' more "+
+					"than once", method.Name)
+			}
+			described[method.Name] = struct{}{}
+			if _, ok := rpcLimited[method.Name]; limited && !ok {
+				t.Errorf("Limited spec includes method '%v'",
+					method.Name)
+			}
+		}
+		methods := make([]string, 0, len(rpcHandlers)+len(wsHandlers))
+		for k := range rpcHandlers {
+			methods = append(methods, k)
+		}
+		for k := range wsHandlers {
+			methods = append(methods, k)
+		}
+		for _, k := range methods {
+			if _, ok := rpcLimited[k]; limited && !ok {
+				continue
+			}
+			if _, ok := described[k]; !ok {
+				t.Errorf("Spec (limited %v) does not describe "+
+					"method '%v'", limited, k)
+			}
+		}
+	}
+}
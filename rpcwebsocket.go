@@ -18,6 +18,7 @@ import (
 	"io"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dogesuite/doged/blockchain"
@@ -28,6 +29,7 @@ import (
 	"github.com/dogesuite/doged/txscript"
 	"github.com/dogesuite/doged/wire"
 	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/btcutil/hdkeychain"
 	"github.com/btcsuite/websocket"
 	"golang.org/x/crypto/ripemd160"
 )
@@ -39,6 +41,27 @@ const (
 	// handler since notifications have their own queuing mechanism
 	// independent of the send channel buffer.
 	websocketSendBufferSize = 50
+
+	// defaultDescriptorGapLimit is the default number of unused addresses
+	// derived ahead of the highest used address of a descriptor registered
+	// with the registerdescriptor command.
+	defaultDescriptorGapLimit = 20
+
+	// maxDescriptorGapLimit is the maximum gap limit of a descriptor
+	// registered with the registerdescriptor command.
+	maxDescriptorGapLimit = 10000
+
+	// maxClientDescriptors is the maximum number of descriptors a websocket
+	// client can register with the registerdescriptor command.  Together
+	// with maxDescriptorGapLimit, this bounds the number of addresses
+	// derived for a client.
+	maxClientDescriptors = 16
+
+	// maxBlockReplayDepth is the maximum number of block notifications the
+	// notifyblocksfrom command replays to bring a client up to the current
+	// best block.  Clients which are further behind need to catch up with
+	// rescanblocks first.
+	maxBlockReplayDepth = 1440
 )
 
 type semaphore chan struct{}
@@ -66,9 +89,11 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 	"loadtxfilter":              handleLoadTxFilter,
 	"help":                      handleWebsocketHelp,
 	"notifyblocks":              handleNotifyBlocks,
+	"notifyblocksfrom":          handleNotifyBlocksFrom,
 	"notifynewtransactions":     handleNotifyNewTransactions,
 	"notifyreceived":            handleNotifyReceived,
 	"notifyspent":               handleNotifySpent,
+	"registerdescriptor":        handleRegisterDescriptor,
 	"session":                   handleSession,
 	"stopnotifyblocks":          handleStopNotifyBlocks,
 	"stopnotifynewtransactions": handleStopNotifyNewTransactions,
@@ -113,6 +138,7 @@ func (s *rpcServer) WebsocketHandler(conn *websocket.Conn, remoteAddr string,
 	client.Start()
 	client.WaitForShutdown()
 	s.ntfnMgr.RemoveClient(client)
+	client.stopBlockReplay()
 	rpcsLog.Infof("Disconnected websocket client %s", remoteAddr)
 }
 
@@ -226,6 +252,43 @@ func (m *wsNotificationManager) NotifyBlockDisconnected(block *btcutil.Block) {
 	}
 }
 
+// notifyReplayedBlock passes a block connected or disconnected by the chain
+// subscription of a client created by the notifyblocksfrom command to the
+// notification manager for block notification processing.  Since the chain
+// invokes the subscription with its lock held, the notification is queued
+// rather than sent to the client directly so a slow client can't stall the
+// chain.
+//
+// While the subscription replays blocks, wait is set and the function does not
+// return until the notification manager processed the block.  This keeps the
+// replay from flooding the queue of the notification manager, which is shared
+// by all clients, with the blocks of a single one.
+func (m *wsNotificationManager) notifyReplayedBlock(wsc *wsClient,
+	block *btcutil.Block, connected, wait bool) {
+
+	n := &notificationReplayedBlock{
+		wsc:       wsc,
+		block:     block,
+		connected: connected,
+	}
+	if wait {
+		n.done = make(chan struct{})
+	}
+	select {
+	case m.queueNotification <- n:
+	case <-m.quit:
+		return
+	}
+	if !wait {
+		return
+	}
+	select {
+	case <-n.done:
+	case <-wsc.quit:
+	case <-m.quit:
+	}
+}
+
 // NotifyMempoolTx passes a transaction accepted by mempool to the
 // notification manager for transaction notification processing.  If
 // isNew is true, the tx is is a new transaction, rather than one
@@ -246,6 +309,22 @@ func (m *wsNotificationManager) NotifyMempoolTx(tx *btcutil.Tx, isNew bool) {
 	}
 }
 
+// NotifyMempoolTxExpired passes a transaction evicted from the mempool since
+// it expired to the notification manager for transaction notification
+// processing.
+func (m *wsNotificationManager) NotifyMempoolTxExpired(tx *btcutil.Tx) {
+	n := (*notificationTxExpiredFromMempool)(tx)
+
+	// As NotifyMempoolTxExpired will be called by mempool and the RPC
+	// server may no longer be running, use a select statement to unblock
+	// enqueuing the notification once the RPC server has begun shutting
+	// down.
+	select {
+	case m.queueNotification <- n:
+	case <-m.quit:
+	}
+}
+
 // wsClientFilter tracks relevant addresses for each websocket client for
 // the `rescanblocks` extension. It is modified by the `loadtxfilter` command.
 //
@@ -266,6 +345,38 @@ type wsClientFilter struct {
 
 	// Outpoints of unspent outputs.
 	unspent map[wire.OutPoint]struct{}
+
+	// Ranged descriptors registered with the filter keyed by their string
+	// representation and the addresses derived from them keyed by their
+	// encoding.
+	descriptors     map[string]*filterDescriptor
+	descriptorAddrs map[string]filterDescriptorAddr
+
+	// extended houses the descriptors whose derived addresses were extended
+	// since the client was last notified about it.
+	extended map[*filterDescriptor]struct{}
+}
+
+// filterDescriptor tracks the addresses derived from a ranged output
+// descriptor registered with a wsClientFilter.
+type filterDescriptor struct {
+	desc     *outputDescriptor
+	gapLimit uint32
+
+	// derived is the number of addresses derived from the descriptor
+	// starting at index zero.
+	derived uint32
+
+	// nextIndex is the index following the highest index of the addresses
+	// which have been used.
+	nextIndex uint32
+}
+
+// filterDescriptorAddr identifies the descriptor an address of a
+// wsClientFilter was derived from along with its index.
+type filterDescriptorAddr struct {
+	fd    *filterDescriptor
+	index uint32
 }
 
 // newWSClientFilter creates a new, empty wsClientFilter struct to be used
@@ -280,6 +391,9 @@ func newWSClientFilter(addresses []string, unspentOutPoints []wire.OutPoint, par
 		uncompressedPubKeys: map[[65]byte]struct{}{},
 		otherAddresses:      map[string]struct{}{},
 		unspent:             make(map[wire.OutPoint]struct{}, len(unspentOutPoints)),
+		descriptors:         map[string]*filterDescriptor{},
+		descriptorAddrs:     map[string]filterDescriptorAddr{},
+		extended:            map[*filterDescriptor]struct{}{},
 	}
 
 	for _, s := range addresses {
@@ -444,6 +558,96 @@ func (f *wsClientFilter) removeUnspentOutPoint(op *wire.OutPoint) {
 	delete(f.unspent, *op)
 }
 
+// addDescriptor registers the passed ranged descriptor with the
+// wsClientFilter, or updates the gap limit of the descriptor when it is
+// already registered, and adds the addresses it derives to the filter.
+func (f *wsClientFilter) addDescriptor(desc *outputDescriptor, gapLimit uint32) *filterDescriptor {
+	fd, ok := f.descriptors[desc.String()]
+	if !ok {
+		fd = &filterDescriptor{desc: desc}
+		f.descriptors[desc.String()] = fd
+	}
+	fd.gapLimit = gapLimit
+	f.extendDescriptor(fd)
+	return fd
+}
+
+// addDescriptorsFrom registers the descriptors of the passed filter with the
+// wsClientFilter, keeping the next unused index of each.
+//
+// The passed filter must be locked by the caller.
+func (f *wsClientFilter) addDescriptorsFrom(from *wsClientFilter) {
+	for key, fromFd := range from.descriptors {
+		fd := &filterDescriptor{
+			desc:      fromFd.desc,
+			gapLimit:  fromFd.gapLimit,
+			nextIndex: fromFd.nextIndex,
+		}
+		f.descriptors[key] = fd
+		f.extendDescriptor(fd)
+	}
+}
+
+// extendDescriptor derives the addresses of the passed descriptor and adds
+// them to the wsClientFilter until the gap limit of unused addresses follows
+// the highest used one.
+func (f *wsClientFilter) extendDescriptor(fd *filterDescriptor) {
+	for fd.derived < fd.nextIndex+fd.gapLimit &&
+		fd.derived < hdkeychain.HardenedKeyStart {
+
+		index := fd.derived
+		fd.derived++
+
+		// Indexes without a valid key are skipped.
+		a, err := fd.desc.deriveAddress(index)
+		if err != nil {
+			continue
+		}
+		f.addAddress(a)
+		f.descriptorAddrs[a.EncodeAddress()] = filterDescriptorAddr{
+			fd:    fd,
+			index: index,
+		}
+	}
+}
+
+// markAddressUsed advances the next unused index of the descriptor the passed
+// address of the wsClientFilter was derived from, if any, and extends the
+// derived addresses to maintain the gap limit.  The client is notified about
+// the extension by the next call to takeExtendedDescriptors.
+func (f *wsClientFilter) markAddressUsed(a btcutil.Address) {
+	// Outputs paying to public keys match the pubkey hash addresses derived
+	// from them.
+	if pubKey, ok := a.(*btcutil.AddressPubKey); ok {
+		a = pubKey.AddressPubKeyHash()
+	}
+
+	da, ok := f.descriptorAddrs[a.EncodeAddress()]
+	if !ok || da.index < da.fd.nextIndex {
+		return
+	}
+	da.fd.nextIndex = da.index + 1
+	f.extendDescriptor(da.fd)
+	f.extended[da.fd] = struct{}{}
+}
+
+// takeExtendedDescriptors returns the notifications reporting the current
+// state of the descriptors of the wsClientFilter which were extended since the
+// last call.
+func (f *wsClientFilter) takeExtendedDescriptors() []*btcjson.DescriptorExtendedNtfn {
+	if len(f.extended) == 0 {
+		return nil
+	}
+
+	ntfns := make([]*btcjson.DescriptorExtendedNtfn, 0, len(f.extended))
+	for fd := range f.extended {
+		ntfns = append(ntfns, btcjson.NewDescriptorExtendedNtfn(
+			fd.desc.String(), fd.nextIndex, fd.derived))
+		delete(f.extended, fd)
+	}
+	return ntfns
+}
+
 // Notification types
 type notificationBlockConnected btcutil.Block
 type notificationBlockDisconnected btcutil.Block
@@ -451,6 +655,13 @@ type notificationTxAcceptedByMempool struct {
 	isNew bool
 	tx    *btcutil.Tx
 }
+type notificationTxExpiredFromMempool btcutil.Tx
+type notificationReplayedBlock struct {
+	wsc       *wsClient
+	block     *btcutil.Block
+	connected bool
+	done      chan struct{}
+}
 
 // Notification control requests
 type notificationRegisterClient wsClient
@@ -532,6 +743,23 @@ out:
 						block)
 				}
 
+			case *notificationReplayedBlock:
+				replayClients := map[chan struct{}]*wsClient{
+					n.wsc.quit: n.wsc,
+				}
+				if n.connected {
+					m.notifyBlockConnected(replayClients, n.block)
+					m.notifyFilteredBlockConnected(replayClients,
+						n.block)
+				} else {
+					m.notifyBlockDisconnected(replayClients, n.block)
+					m.notifyFilteredBlockDisconnected(replayClients,
+						n.block)
+				}
+				if n.done != nil {
+					close(n.done)
+				}
+
 			case *notificationTxAcceptedByMempool:
 				if n.isNew && len(txNotifications) != 0 {
 					m.notifyForNewTx(txNotifications, n.tx)
@@ -539,6 +767,12 @@ out:
 				m.notifyForTx(watchedOutPoints, watchedAddrs, n.tx, nil)
 				m.notifyRelevantTxAccepted(n.tx, clients)
 
+			case *notificationTxExpiredFromMempool:
+				if len(txNotifications) != 0 {
+					m.notifyExpiredTx(txNotifications,
+						(*btcutil.Tx)(n))
+				}
+
 			case *notificationRegisterBlocks:
 				wsc := (*wsClient)(n)
 				blockNotifications[wsc.quit] = wsc
@@ -672,6 +906,7 @@ func (m *wsNotificationManager) subscribedClients(tx *btcutil.Tx,
 			filter.mu.Lock()
 			for _, a := range addrs {
 				if filter.existsAddress(a) {
+					filter.markAddressUsed(a)
 					subscribed[quitChan] = struct{}{}
 					op := wire.OutPoint{
 						Hash:  *tx.Hash(),
@@ -773,6 +1008,12 @@ func (m *wsNotificationManager) notifyFilteredBlockConnected(clients map[chan st
 		}
 		wsc.QueueNotification(marshalledJSON)
 	}
+
+	// Notify the clients whose descriptors were extended by the
+	// transactions of the block.
+	for _, wsc := range clients {
+		wsc.notifyDescriptorsExtended()
+	}
 }
 
 // notifyFilteredBlockDisconnected notifies websocket clients that have registered for
@@ -868,6 +1109,22 @@ func (m *wsNotificationManager) notifyForNewTx(clients map[chan struct{}]*wsClie
 	}
 }
 
+// notifyExpiredTx notifies websocket clients that have registered for updates
+// when new transactions are added to the memory pool that a transaction was
+// evicted from it since it expired.
+func (m *wsNotificationManager) notifyExpiredTx(clients map[chan struct{}]*wsClient, tx *btcutil.Tx) {
+	ntfn := btcjson.NewTxExpiredNtfn(tx.Hash().String())
+	marshalledJSON, err := btcjson.MarshalCmd(btcjson.RpcVersion1, nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal tx expired notification: %s",
+			err.Error())
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
 // RegisterSpentRequests requests a notification when each of the passed
 // outpoints is confirmed spent (contained in a block connected to the main
 // chain) for the passed websocket client.  The request is automatically
@@ -1054,6 +1311,7 @@ func (m *wsNotificationManager) notifyRelevantTxAccepted(tx *btcutil.Tx,
 		}
 		for quitChan := range clientsToNotify {
 			clients[quitChan].QueueNotification(marshalled)
+			clients[quitChan].notifyDescriptorsExtended()
 		}
 	}
 }
@@ -1292,6 +1550,10 @@ type wsClient struct {
 	// `rescanblocks` methods.
 	filterData *wsClientFilter
 
+	// blockReplayUnsubscribe removes the chain subscription created by the
+	// notifyblocksfrom command, if any.
+	blockReplayUnsubscribe func()
+
 	// Networking infrastructure.
 	serviceRequestSem semaphore
 	ntfnChan          chan []byte
@@ -1942,6 +2204,32 @@ func (c *wsClient) QueueNotification(marshalledJSON []byte) error {
 	return nil
 }
 
+// notifyDescriptorsExtended notifies the websocket client about the
+// descriptors of its transaction filter whose derived addresses were extended
+// since it was last notified.
+func (c *wsClient) notifyDescriptorsExtended() {
+	c.Lock()
+	filter := c.filterData
+	c.Unlock()
+	if filter == nil {
+		return
+	}
+
+	filter.mu.Lock()
+	ntfns := filter.takeExtendedDescriptors()
+	filter.mu.Unlock()
+
+	for _, ntfn := range ntfns {
+		marshalled, err := btcjson.MarshalCmd(btcjson.RpcVersion1, nil, ntfn)
+		if err != nil {
+			rpcsLog.Errorf("Failed to marshal descriptor extended "+
+				"notification: %v", err)
+			return
+		}
+		c.QueueNotification(marshalled)
+	}
+}
+
 // Disconnected returns whether or not the websocket client is disconnected.
 func (c *wsClient) Disconnected() bool {
 	c.Lock()
@@ -2087,33 +2375,215 @@ func handleLoadTxFilter(wsc *wsClient, icmd interface{}) (interface{}, error) {
 	params := wsc.server.cfg.ChainParams
 
 	wsc.Lock()
-	if cmd.Reload || wsc.filterData == nil {
-		wsc.filterData = newWSClientFilter(cmd.Addresses, outPoints,
+	filter := wsc.filterData
+	wsc.Unlock()
+	if cmd.Reload || filter == nil {
+		// Registered descriptors are kept when reloading the filter
+		// since they are not part of the loaded filter data.
+		newFilter := newWSClientFilter(cmd.Addresses, outPoints,
 			params)
+		if filter != nil {
+			filter.mu.Lock()
+			newFilter.addDescriptorsFrom(filter)
+			filter.mu.Unlock()
+		}
+
+		wsc.Lock()
+		wsc.filterData = newFilter
 		wsc.Unlock()
 	} else {
-		wsc.Unlock()
-
-		wsc.filterData.mu.Lock()
+		filter.mu.Lock()
 		for _, a := range cmd.Addresses {
-			wsc.filterData.addAddressStr(a, params)
+			filter.addAddressStr(a, params)
 		}
 		for i := range outPoints {
-			wsc.filterData.addUnspentOutPoint(&outPoints[i])
+			filter.addUnspentOutPoint(&outPoints[i])
 		}
-		wsc.filterData.mu.Unlock()
+		filter.mu.Unlock()
 	}
 
 	return nil, nil
 }
 
+// handleRegisterDescriptor implements the registerdescriptor command extension
+// for websocket connections.
+func handleRegisterDescriptor(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd := icmd.(*btcjson.RegisterDescriptorCmd)
+
+	gapLimit := uint32(defaultDescriptorGapLimit)
+	if cmd.GapLimit != nil {
+		gapLimit = *cmd.GapLimit
+	}
+	if gapLimit == 0 || gapLimit > maxDescriptorGapLimit {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Gap limit must be between 1 and %d",
+				maxDescriptorGapLimit),
+		}
+	}
+
+	params := wsc.server.cfg.ChainParams
+	desc, err := parseDescriptor(cmd.Descriptor, params)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Invalid descriptor: " + err.Error(),
+		}
+	}
+
+	wsc.Lock()
+	if wsc.filterData == nil {
+		wsc.filterData = newWSClientFilter(nil, nil, params)
+	}
+	filter := wsc.filterData
+	wsc.Unlock()
+
+	filter.mu.Lock()
+	_, registered := filter.descriptors[desc.String()]
+	if !registered && len(filter.descriptors) >= maxClientDescriptors {
+		filter.mu.Unlock()
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("No more than %d descriptors can "+
+				"be registered", maxClientDescriptors),
+		}
+	}
+	fd := filter.addDescriptor(desc, gapLimit)
+	result := &btcjson.RegisterDescriptorResult{
+		Descriptor: fd.desc.String(),
+		GapLimit:   fd.gapLimit,
+		NextIndex:  fd.nextIndex,
+		Derived:    fd.derived,
+	}
+	filter.mu.Unlock()
+
+	return result, nil
+}
+
 // handleNotifyBlocks implements the notifyblocks command extension for
 // websocket connections.
 func handleNotifyBlocks(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	// Notifications from a previous notifyblocksfrom command would be
+	// delivered twice otherwise.
+	wsc.stopBlockReplay()
 	wsc.server.ntfnMgr.RegisterBlockUpdates(wsc)
 	return nil, nil
 }
 
+// handleNotifyBlocksFrom implements the notifyblocksfrom command extension for
+// websocket connections.
+func handleNotifyBlocksFrom(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*btcjson.NotifyBlocksFromCmd)
+	if !ok {
+		return nil, btcjson.ErrRPCInternal
+	}
+
+	hash, err := chainhash.NewHashFromStr(cmd.Hash)
+	if err != nil {
+		return nil, rpcDecodeHexError(cmd.Hash)
+	}
+	chain := wsc.server.cfg.Chain
+	depth, err := blockReplayDepth(chain, hash)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+	if depth > maxBlockReplayDepth {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Block %v is more than %d blocks "+
+				"behind the best block, use rescanblocks to "+
+				"catch up first", hash, maxBlockReplayDepth),
+		}
+	}
+
+	// The notifications are sent in the same manner as the ones sent to
+	// clients registered through notifyblocks.  Both the replayed and the
+	// live notifications pass through the queue of the notification
+	// manager, which keeps them strictly ordered.  The replay waits for
+	// each of its blocks to be processed before fetching the next one.
+	m := wsc.server.ntfnMgr
+	replaying := int32(1)
+	callback := func(n *blockchain.Notification) {
+		wait := atomic.LoadInt32(&replaying) == 1
+		switch n.Type {
+		case blockchain.NTBlockConnected:
+			m.notifyReplayedBlock(wsc, n.Data.(*btcutil.Block),
+				true, wait)
+
+		case blockchain.NTBlockDisconnected:
+			m.notifyReplayedBlock(wsc, n.Data.(*btcutil.Block),
+				false, wait)
+		}
+	}
+
+	// Replace any previous replay subscription of the client and stop the
+	// notifications of a previous notifyblocks command, which the
+	// subscription delivers from now on.
+	wsc.stopBlockReplay()
+	m.UnregisterBlockUpdates(wsc)
+	unsubscribe, err := chain.SubscribeFrom(hash, callback, wsc.quit)
+	atomic.StoreInt32(&replaying, 0)
+	if err != nil {
+		if wsc.Disconnected() {
+			return nil, nil
+		}
+		context := "Failed to replay block notifications"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	wsc.Lock()
+	wsc.blockReplayUnsubscribe = unsubscribe
+	wsc.Unlock()
+
+	// The client might have disconnected while the notifications were being
+	// replayed, in which case nothing else removes the subscription.
+	if wsc.Disconnected() {
+		wsc.stopBlockReplay()
+	}
+
+	return nil, nil
+}
+
+// blockReplayDepth returns the number of block notifications needed to bring a
+// client from the passed block up to the current best block.  It stops
+// counting once the depth exceeds maxBlockReplayDepth.
+func blockReplayDepth(chain *blockchain.BlockChain, hash *chainhash.Hash) (int32, error) {
+	// Count the blocks to disconnect when the block is on a side chain.
+	var depth int32
+	for !chain.MainChainHasBlock(hash) {
+		header, err := chain.HeaderByHash(hash)
+		if err != nil {
+			return 0, err
+		}
+		depth++
+		if depth > maxBlockReplayDepth {
+			return depth, nil
+		}
+		hash = &header.PrevBlock
+	}
+
+	height, err := chain.BlockHeightByHash(hash)
+	if err != nil {
+		return 0, err
+	}
+	return depth + chain.BestSnapshot().Height - height, nil
+}
+
+// stopBlockReplay removes the chain subscription created for the client by the
+// notifyblocksfrom command, if any.
+func (c *wsClient) stopBlockReplay() {
+	c.Lock()
+	unsubscribe := c.blockReplayUnsubscribe
+	c.blockReplayUnsubscribe = nil
+	c.Unlock()
+
+	if unsubscribe != nil {
+		unsubscribe()
+	}
+}
+
 // handleSession implements the session command extension for websocket
 // connections.
 func handleSession(wsc *wsClient, icmd interface{}) (interface{}, error) {
@@ -2124,6 +2594,7 @@ func handleSession(wsc *wsClient, icmd interface{}) (interface{}, error) {
 // websocket connections.
 func handleStopNotifyBlocks(wsc *wsClient, icmd interface{}) (interface{}, error) {
 	wsc.server.ntfnMgr.UnregisterBlockUpdates(wsc)
+	wsc.stopBlockReplay()
 	return nil, nil
 }
 
@@ -2467,6 +2938,7 @@ func rescanBlockFilter(filter *wsClientFilter, block *btcutil.Block, params *cha
 				if !filter.existsAddress(a) {
 					continue
 				}
+				filter.markAddressUsed(a)
 
 				op := wire.OutPoint{
 					Hash:  *tx.Hash(),
@@ -2552,6 +3024,10 @@ func handleRescanBlocks(wsc *wsClient, icmd interface{}) (interface{}, error) {
 		}
 	}
 
+	// Notify the client about the descriptors extended by the rescanned
+	// transactions.
+	wsc.notifyDescriptorsExtended()
+
 	return &discoveredData, nil
 }
 
@@ -0,0 +1,162 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// sdNotifySocketEnv is the environment variable systemd passes the
+	// path of its notification socket in to services with Type=notify.
+	sdNotifySocketEnv = "NOTIFY_SOCKET"
+
+	// sdWatchdogUsecEnv and sdWatchdogPidEnv are the environment variables
+	// systemd passes the watchdog interval of services with WatchdogSec=
+	// and the process which is expected to send keep-alive notifications
+	// in.
+	sdWatchdogUsecEnv = "WATCHDOG_USEC"
+	sdWatchdogPidEnv  = "WATCHDOG_PID"
+)
+
+// sdNotifier sends service status notifications to systemd as described by
+// sd_notify(3).  Its methods are no-ops on a nil notifier, which is what
+// newSdNotifier returns when the process is not started by systemd as a
+// service with Type=notify.
+type sdNotifier struct {
+	conn *net.UnixConn
+
+	// watchdogInterval is the interval keep-alive notifications are sent
+	// at once the service is ready, or 0 when the watchdog is disabled.
+	watchdogInterval time.Duration
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newSdNotifier returns a notifier which sends notifications to the socket
+// systemd passes in the environment, or nil when it doesn't pass one.
+func newSdNotifier() (*sdNotifier, error) {
+	socket := os.Getenv(sdNotifySocketEnv)
+	if socket == "" {
+		return nil, nil
+	}
+
+	// Abstract socket addresses are passed with a leading '@', which the
+	// net package translates as well.
+	addr := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdNotifier{
+		conn: conn,
+		watchdogInterval: sdWatchdogInterval(os.Getenv(sdWatchdogUsecEnv),
+			os.Getenv(sdWatchdogPidEnv), os.Getpid()),
+		quit: make(chan struct{}),
+	}, nil
+}
+
+// sdWatchdogInterval returns the interval keep-alive notifications must be
+// sent at for the passed watchdog environment, which is half the watchdog
+// timeout to leave room for scheduling delays, or 0 when the watchdog is
+// disabled or intended for a process other than the passed one.
+func sdWatchdogInterval(usec, watchdogPid string, pid int) time.Duration {
+	if watchdogPid != "" && watchdogPid != strconv.Itoa(pid) {
+		return 0
+	}
+	timeout, err := strconv.ParseUint(usec, 10, 63)
+	if err != nil || timeout == 0 {
+		return 0
+	}
+	return time.Duration(timeout) * time.Microsecond / 2
+}
+
+// notify sends the passed newline-separated variable assignments to systemd.
+func (n *sdNotifier) notify(state string) error {
+	if n == nil {
+		return nil
+	}
+	_, err := n.conn.Write([]byte(state))
+	return err
+}
+
+// Ready notifies systemd that startup is complete and starts sending watchdog
+// keep-alive notifications when the watchdog is enabled.  The passed liveness
+// check is invoked before each keep-alive notification and must only return
+// once the node is responsive, e.g. by making a round trip through the sync
+// manager, so systemd restarts the service when the node hangs.
+func (n *sdNotifier) Ready(status string, livenessCheck func()) {
+	if n == nil {
+		return
+	}
+	if err := n.notify("READY=1\nSTATUS=" + status); err != nil {
+		btcdLog.Warnf("Unable to notify systemd of readiness: %v", err)
+	}
+
+	if n.watchdogInterval == 0 {
+		return
+	}
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+
+		ticker := time.NewTicker(n.watchdogInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				// The check is abandoned on shutdown since the
+				// subsystem it relies on might have stopped
+				// already.
+				alive := make(chan struct{})
+				go func() {
+					livenessCheck()
+					close(alive)
+				}()
+				select {
+				case <-alive:
+				case <-n.quit:
+					return
+				}
+
+				err := n.notify("WATCHDOG=1")
+				if err != nil {
+					btcdLog.Warnf("Unable to notify "+
+						"systemd watchdog: %v", err)
+				}
+
+			case <-n.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stopping notifies systemd that shutdown has begun.
+func (n *sdNotifier) Stopping(status string) {
+	if n == nil {
+		return
+	}
+	if err := n.notify("STOPPING=1\nSTATUS=" + status); err != nil {
+		btcdLog.Warnf("Unable to notify systemd of shutdown: %v", err)
+	}
+}
+
+// Close stops sending watchdog keep-alive notifications and closes the
+// connection to systemd.
+func (n *sdNotifier) Close() {
+	if n == nil {
+		return
+	}
+	close(n.quit)
+	n.wg.Wait()
+	n.conn.Close()
+}
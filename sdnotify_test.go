@@ -0,0 +1,166 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSdWatchdogInterval ensures the watchdog interval is derived from the
+// environment systemd passes.
+func TestSdWatchdogInterval(t *testing.T) {
+	tests := []struct {
+		usec        string
+		watchdogPid string
+		want        time.Duration
+	}{
+		{"", "", 0},
+		{"0", "", 0},
+		{"invalid", "", 0},
+		{"30000000", "", 15 * time.Second},
+		{"30000000", "42", 15 * time.Second},
+		{"30000000", "43", 0},
+	}
+	for i, test := range tests {
+		got := sdWatchdogInterval(test.usec, test.watchdogPid, 42)
+		if got != test.want {
+			t.Errorf("test #%d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+// TestSdNotifier ensures notifications are sent to the socket systemd passes
+// and no notifier is created without one.
+func TestSdNotifier(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("systemd is only available on Linux")
+	}
+
+	os.Unsetenv(sdNotifySocketEnv)
+	notifier, err := newSdNotifier()
+	if err != nil || notifier != nil {
+		t.Fatalf("Unexpected notifier without socket: %v", err)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "btcd")
+	if err != nil {
+		t.Fatalf("Failed creating a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	socket := filepath.Join(tmpDir, "notify")
+	conn, err := net.ListenUnixgram("unixgram",
+		&net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Unable to listen on socket: %v", err)
+	}
+	defer conn.Close()
+
+	os.Setenv(sdNotifySocketEnv, socket)
+	os.Setenv(sdWatchdogUsecEnv, "20000")
+	defer func() {
+		os.Unsetenv(sdNotifySocketEnv)
+		os.Unsetenv(sdWatchdogUsecEnv)
+	}()
+	notifier, err = newSdNotifier()
+	if err != nil {
+		t.Fatalf("Unable to create notifier: %v", err)
+	}
+
+	receive := func() string {
+		t.Helper()
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var buf [256]byte
+		n, err := conn.Read(buf[:])
+		if err != nil {
+			t.Fatalf("Unable to receive notification: %v", err)
+		}
+		return string(buf[:n])
+	}
+
+	// The liveness check blocks until the gate is closed, which simulates
+	// a hung node while it is open.
+	var mtx sync.Mutex
+	gate := make(chan struct{})
+	close(gate)
+	setGate := func(c chan struct{}) {
+		mtx.Lock()
+		gate = c
+		mtx.Unlock()
+	}
+	livenessCheck := func() {
+		mtx.Lock()
+		c := gate
+		mtx.Unlock()
+		<-c
+	}
+
+	// Watchdog keep-alive notifications follow readiness.
+	notifier.Ready("Running", livenessCheck)
+	if got := receive(); got != "READY=1\nSTATUS=Running" {
+		t.Fatalf("Unexpected readiness notification %q", got)
+	}
+	if got := receive(); got != "WATCHDOG=1" {
+		t.Fatalf("Unexpected watchdog notification %q", got)
+	}
+
+	// No keep-alive notifications are sent while the node is hung.  The
+	// ones sent before, including one by a check which passed already, are
+	// drained first.
+	hung := make(chan struct{})
+	setGate(hung)
+	var buf [256]byte
+	for {
+		conn.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+		if _, err := conn.Read(buf[:]); err != nil {
+			break
+		}
+	}
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if n, err := conn.Read(buf[:]); err == nil {
+		t.Fatalf("Unexpected notification %q while hung", buf[:n])
+	}
+	close(hung)
+	if got := receive(); got != "WATCHDOG=1" {
+		t.Fatalf("Unexpected watchdog notification %q", got)
+	}
+
+	// Keep-alive notifications may still be sent once shutdown begins.
+	notifier.Stopping("Shutting down")
+	for {
+		got := receive()
+		if got == "WATCHDOG=1" {
+			continue
+		}
+		if got != "STOPPING=1\nSTATUS=Shutting down" {
+			t.Fatalf("Unexpected stopping notification %q", got)
+		}
+		break
+	}
+
+	// Closing the notifier does not wait for a hung liveness check.
+	hung = make(chan struct{})
+	defer close(hung)
+	setGate(hung)
+	time.Sleep(20 * time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		notifier.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on hung liveness check")
+	}
+}
@@ -243,6 +243,14 @@ type server struct {
 	// the mempool before they are mined into blocks.
 	feeEstimator *mempool.FeeEstimator
 
+	// The reject filter remembers transactions which were rejected for
+	// being invalid under the consensus rules across restarts.
+	rejectFilter *mempool.RejectFilter
+
+	// txDiffer mirrors the transactions processed by the mempool to a
+	// reference node when the diffnode option is set and is nil otherwise.
+	txDiffer *txDiffer
+
 	// cfCheckptCaches stores a cached slice of filter headers for cfcheckpt
 	// messages for each filter type.
 	cfCheckptCaches    map[wire.FilterType][]cfHeaderKV
@@ -341,24 +349,29 @@ func (sp *serverPeer) relayTxDisabled() bool {
 	return isDisabled
 }
 
-// pushAddrMsg sends a legacy addr message to the connected peer using the
-// provided addresses.
+// pushAddrMsg sends the provided addresses the connected peer doesn't already
+// know about to it.
 func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddressV2) {
-	if sp.WantsAddrV2() {
-		// If the peer supports addrv2, we'll be pushing an addrv2
-		// message instead. The logic is otherwise identical to the
-		// addr case below.
-		addrs := make([]*wire.NetAddressV2, 0, len(addresses))
-		for _, addr := range addresses {
-			// Filter addresses already known to the peer.
-			if sp.addressKnown(addr) {
-				continue
-			}
-
-			addrs = append(addrs, addr)
+	addrs := make([]*wire.NetAddressV2, 0, len(addresses))
+	for _, addr := range addresses {
+		// Filter addresses already known to the peer.
+		if sp.addressKnown(addr) {
+			continue
 		}
 
-		known, err := sp.PushAddrV2Msg(addrs)
+		addrs = append(addrs, addr)
+	}
+
+	sp.pushAddrs(addrs)
+}
+
+// pushAddrs sends an addrv2 message to the connected peer using the provided
+// addresses when it supports addrv2, and a legacy addr message otherwise.  The
+// addresses are sent as provided except for Tor v3 addresses, which legacy addr
+// messages can't house.
+func (sp *serverPeer) pushAddrs(addresses []*wire.NetAddressV2) {
+	if sp.WantsAddrV2() {
+		known, err := sp.PushAddrV2Msg(addresses)
 		if err != nil {
 			peerLog.Errorf("Can't push addrv2 message to %s: %v",
 				sp.Peer, err)
@@ -374,11 +387,6 @@ func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddressV2) {
 
 	addrs := make([]*wire.NetAddress, 0, len(addresses))
 	for _, addr := range addresses {
-		// Filter addresses already known to the peer.
-		if sp.addressKnown(addr) {
-			continue
-		}
-
 		// Must skip the V3 addresses for legacy ADDR messages.
 		if addr.IsTorV3() {
 			continue
@@ -1275,6 +1283,14 @@ func (sp *serverPeer) OnGetAddr(_ *peer.Peer, msg *wire.MsgGetAddr) {
 		return
 	}
 
+	// Don't return any addresses when serving them has been disabled,
+	// which is always the case when listening is disabled.
+	if cfg.DisableAddrServe {
+		peerLog.Debugf("Ignoring getaddr request from peer %v since "+
+			"address serving is disabled", sp)
+		return
+	}
+
 	// Do not accept getaddr requests from outbound peers.  This reduces
 	// fingerprinting attacks.
 	if !sp.Inbound() {
@@ -1292,11 +1308,16 @@ func (sp *serverPeer) OnGetAddr(_ *peer.Peer, msg *wire.MsgGetAddr) {
 	}
 	sp.sentAddrs = true
 
-	// Get the current known addresses from the address manager.
-	addrCache := sp.server.addrManager.AddressCache()
+	// Get the known addresses to serve to the network group of the peer
+	// from the address manager.  The same addresses are served to all
+	// peers in the group for a period of time so the full address table
+	// can't be enumerated through repeated requests.
+	addrCache := sp.server.addrManager.AddressCacheForGroup(
+		addrmgr.GroupKey(sp.NA()))
 
-	// Push the addresses.
-	sp.pushAddrMsg(addrCache)
+	// Push the addresses as cached without filtering the ones the peer is
+	// known to have so all peers in the group receive the same response.
+	sp.pushAddrs(addrCache)
 }
 
 // OnAddr is invoked when a peer receives an addr bitcoin message and is
@@ -1526,6 +1547,29 @@ func (s *server) TransactionConfirmed(tx *btcutil.Tx) {
 	s.RemoveRebroadcastInventory(iv)
 }
 
+// handleMempoolNotification handles notifications from the memory pool.  It
+// stops rebroadcasting transactions which were evicted from the pool since
+// they are no longer going to be mined and reports them to websocket clients.
+func (s *server) handleMempoolNotification(notification *mempool.Notification) {
+	switch notification.Type {
+	case mempool.NTTxExpired:
+		txD, ok := notification.Data.(*mempool.TxDesc)
+		if !ok {
+			srvrLog.Warnf("Mempool expired notification is not a " +
+				"transaction descriptor.")
+			break
+		}
+
+		srvrLog.Debugf("Transaction %v expired from the mempool after "+
+			"%v", txD.Tx.Hash(), time.Since(txD.Added).Truncate(time.Second))
+		if s.rpcServer != nil {
+			iv := wire.NewInvVect(wire.InvTypeTx, txD.Tx.Hash())
+			s.RemoveRebroadcastInventory(iv)
+			s.rpcServer.NotifyExpiredTransaction(txD)
+		}
+	}
+}
+
 // pushTxMsg sends a tx message for the provided transaction hash to the
 // connected peer.  An error is returned if the transaction hash is not known.
 func (s *server) pushTxMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<- struct{},
@@ -2476,6 +2520,17 @@ func (s *server) Start() {
 		go s.upnpUpdateThread()
 	}
 
+	// Periodically evict expired transactions from the memory pool since
+	// it is otherwise only done when blocks are connected.
+	if cfg.MempoolExpiry > 0 {
+		s.wg.Add(1)
+		go func() {
+			s.txMemPool.ExpiryHandler(mempool.DefaultExpireScanInterval,
+				s.quit)
+			s.wg.Done()
+		}()
+	}
+
 	if !cfg.DisableRPC {
 		s.wg.Add(1)
 
@@ -2490,6 +2545,10 @@ func (s *server) Start() {
 	if cfg.Generate {
 		s.cpuMiner.Start()
 	}
+
+	if s.txDiffer != nil {
+		s.txDiffer.Start()
+	}
 }
 
 // Stop gracefully shuts down the server by stopping and disconnecting all
@@ -2511,10 +2570,16 @@ func (s *server) Stop() error {
 		s.rpcServer.Stop()
 	}
 
-	// Save fee estimator state in the database.
+	// Stop comparing transactions against the reference node.
+	if s.txDiffer != nil {
+		s.txDiffer.Stop()
+	}
+
+	// Save fee estimator and reject filter state in the database.
 	s.db.Update(func(tx database.Tx) error {
 		metadata := tx.Metadata()
 		metadata.Put(mempool.EstimateFeeDatabaseKey, s.feeEstimator.Save())
+		metadata.Put(mempool.RejectFilterDatabaseKey, s.rejectFilter.Save())
 
 		return nil
 	})
@@ -2823,14 +2888,15 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	// Create a new block chain instance with the appropriate configuration.
 	var err error
 	s.chain, err = blockchain.New(&blockchain.Config{
-		DB:           s.db,
-		Interrupt:    interrupt,
-		ChainParams:  s.chainParams,
-		Checkpoints:  checkpoints,
-		TimeSource:   s.timeSource,
-		SigCache:     s.sigCache,
-		IndexManager: indexManager,
-		HashCache:    s.hashCache,
+		DB:                  s.db,
+		Interrupt:           interrupt,
+		ChainParams:         s.chainParams,
+		Checkpoints:         checkpoints,
+		TimeSource:          s.timeSource,
+		SigCache:            s.sigCache,
+		IndexManager:        indexManager,
+		HashCache:           s.hashCache,
+		ScriptFlagOverrides: cfg.scriptFlagOverrides,
 	})
 	if err != nil {
 		return nil, err
@@ -2866,17 +2932,57 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			mempool.DefaultEstimateFeeMinRegisteredBlocks)
 	}
 
+	// Restore the transactions which were rejected for being invalid under
+	// the consensus rules from the database.  They remain invalid
+	// regardless of the state of the chain, so they are kept even when the
+	// chain has changed in the meantime.
+	db.Update(func(tx database.Tx) error {
+		metadata := tx.Metadata()
+		rejectFilterData := metadata.Get(mempool.RejectFilterDatabaseKey)
+		if rejectFilterData != nil {
+			metadata.Delete(mempool.RejectFilterDatabaseKey)
+
+			var err error
+			s.rejectFilter, err = mempool.RestoreRejectFilter(
+				rejectFilterData, mempool.DefaultMaxRejectedTxs)
+			if err != nil {
+				peerLog.Errorf("Failed to restore reject filter %v",
+					err)
+			}
+		}
+
+		return nil
+	})
+	if s.rejectFilter == nil {
+		s.rejectFilter = mempool.NewRejectFilter(
+			mempool.DefaultMaxRejectedTxs)
+	}
+
+	// Compare the acceptance of transactions against a reference node if
+	// requested.
+	var txProcessed func(*btcutil.Tx, error)
+	if cfg.DiffNode != "" {
+		s.txDiffer, err = newTxDiffer()
+		if err != nil {
+			return nil, err
+		}
+		txProcessed = s.txDiffer.TxProcessed
+	}
+
 	txC := mempool.Config{
 		Policy: mempool.Policy{
 			DisableRelayPriority: cfg.NoRelayPriority,
 			AcceptNonStd:         cfg.RelayNonStd,
+			AcceptAnnex:          cfg.RelayAnnex,
 			FreeTxRelayLimit:     cfg.FreeTxRelayLimit,
 			MaxOrphanTxs:         cfg.MaxOrphanTxs,
 			MaxOrphanTxSize:      defaultMaxOrphanTxSize,
 			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 			MinRelayTxFee:        cfg.minRelayTxFee,
+			IncrementalRelayFee:  cfg.incrementalRelayFee,
 			MaxTxVersion:         2,
 			RejectReplacement:    cfg.RejectReplacement,
+			MaxTxAge:             cfg.MempoolExpiry,
 		},
 		ChainParams:    chainParams,
 		FetchUtxoView:  s.chain.FetchUtxoView,
@@ -2885,13 +2991,17 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		CalcSequenceLock: func(tx *btcutil.Tx, view *blockchain.UtxoViewpoint) (*blockchain.SequenceLock, error) {
 			return s.chain.CalcSequenceLock(tx, view, true)
 		},
-		IsDeploymentActive: s.chain.IsDeploymentActive,
-		SigCache:           s.sigCache,
-		HashCache:          s.hashCache,
-		AddrIndex:          s.addrIndex,
-		FeeEstimator:       s.feeEstimator,
+		IsDeploymentActive:   s.chain.IsDeploymentActive,
+		SigCache:             s.sigCache,
+		HashCache:            s.hashCache,
+		AddrIndex:            s.addrIndex,
+		FeeEstimator:         s.feeEstimator,
+		TxProcessed:          txProcessed,
+		RejectFilter:         s.rejectFilter,
+		ConsensusScriptFlags: s.chain.NextScriptFlags,
 	}
 	s.txMemPool = mempool.New(&txC)
+	s.txMemPool.Subscribe(s.handleMempoolNotification)
 
 	s.syncManager, err = netsync.New(&netsync.Config{
 		PeerNotifier:       &s,
@@ -3043,6 +3153,7 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			AddrIndex:    s.addrIndex,
 			CfIndex:      s.cfIndex,
 			FeeEstimator: s.feeEstimator,
+			RejectFilter: s.rejectFilter,
 		})
 		if err != nil {
 			return nil, err
@@ -27,6 +27,12 @@ const (
 	// svcDesc is the description of the service.
 	svcDesc = "Downloads and stays synchronized with the bitcoin block " +
 		"chain and provides chain services to applications."
+
+	// svcStopWaitHint is the time in milliseconds the service control
+	// manager is told to wait for progress while the service is stopping.
+	// Shutting down the server and flushing the database can take longer
+	// than that, so progress is reported at half the interval.
+	svcStopWaitHint = 30000
 )
 
 // elog is used to send messages to the Windows event log.
@@ -71,7 +77,15 @@ func (s *btcdService) Execute(args []string, r <-chan svc.ChangeRequest, changes
 	// Service is now started.
 	changes <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
 
+	// Progress of a pending stop is reported by a ticker which only starts
+	// once the stop is requested.
+	stopProgress := time.NewTicker(svcStopWaitHint / 2 * time.Millisecond)
+	stopProgress.Stop()
+	defer stopProgress.Stop()
+
 	var mainServer *server
+	var checkPoint uint32
+	var exitCode uint32
 loop:
 	for {
 		select {
@@ -83,7 +97,16 @@ loop:
 			case svc.Stop, svc.Shutdown:
 				// Service stop is pending.  Don't accept any
 				// more commands while pending.
-				changes <- svc.Status{State: svc.StopPending}
+				changes <- svc.Status{
+					State:    svc.StopPending,
+					WaitHint: svcStopWaitHint,
+				}
+
+				// Keep reporting progress until the main
+				// function exits so the service control
+				// manager doesn't consider the service hung.
+				stopProgress.Reset(svcStopWaitHint / 2 *
+					time.Millisecond)
 
 				// Signal the main function to exit.
 				shutdownRequestChannel <- struct{}{}
@@ -93,13 +116,25 @@ loop:
 					"request #%d.", c))
 			}
 
+		case <-stopProgress.C:
+			checkPoint++
+			changes <- svc.Status{
+				State:      svc.StopPending,
+				CheckPoint: checkPoint,
+				WaitHint:   svcStopWaitHint,
+			}
+
 		case srvr := <-serverChan:
 			mainServer = srvr
 			logServiceStartOfDay(mainServer)
 
 		case err := <-doneChan:
+			// Report the failure with a non-zero exit code so the
+			// recovery actions of the service, such as restarting
+			// it, are taken.
 			if err != nil {
 				elog.Error(1, err.Error())
+				exitCode = 1
 			}
 			break loop
 		}
@@ -107,7 +142,7 @@ loop:
 
 	// Service is now stopped.
 	changes <- svc.Status{State: svc.Stopped}
-	return false, 0
+	return false, exitCode
 }
 
 // installService attempts to install the btcd service.  Typically this should
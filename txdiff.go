@@ -0,0 +1,325 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dogesuite/doged/btcjson"
+	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/mempool"
+	"github.com/dogesuite/doged/rpcclient"
+	"github.com/dogesuite/doged/wire"
+)
+
+const (
+	// maxTxDiffQueue is the maximum number of processed transactions
+	// waiting to be mirrored to the reference node.  Transactions processed
+	// while the queue is full are not compared.
+	maxTxDiffQueue = 1000
+
+	// txDiffRequestTimeout is the maximum amount of time to wait for the
+	// reference node to respond to a single testmempoolaccept request.
+	txDiffRequestTimeout = 30 * time.Second
+)
+
+// txStateRejectReasons houses the reject reasons of the reference
+// implementation which depend on the contents of its mempool or the chain
+// state it has synced rather than on its acceptance rules.  Disagreements
+// involving them are expected while the two nodes see transactions and blocks
+// in a different order, so they are not reported as divergences.
+var txStateRejectReasons = map[string]struct{}{
+	"bad-txns-inputs-missingorspent":      {},
+	"mempool full":                        {},
+	"missing-inputs":                      {},
+	"txn-already-in-mempool":              {},
+	"txn-already-known":                   {},
+	"txn-mempool-conflict":                {},
+	"txn-same-nonwitness-data-in-mempool": {},
+}
+
+// referenceRejectCodes maps the prefixes of the reject reasons of the reference
+// implementation to the reject codes the local mempool uses for the same rules.
+// Reasons without a known prefix are policy rules, which the local mempool
+// rejects as nonstandard.
+var referenceRejectCodes = []struct {
+	prefix string
+	code   wire.RejectCode
+}{
+	{"bad-txns-", wire.RejectInvalid},
+	{"dust", wire.RejectDust},
+	{"insufficient fee", wire.RejectInsufficientFee},
+	{"insufficient priority", wire.RejectInsufficientFee},
+	{"mandatory-script-verify-flag-failed", wire.RejectInvalid},
+	{"mempool min fee not met", wire.RejectInsufficientFee},
+	{"min relay fee not met", wire.RejectInsufficientFee},
+	{"txn-already-", wire.RejectDuplicate},
+}
+
+// referenceRejectCode returns the local reject code corresponding to the passed
+// reject reason of the reference implementation.
+func referenceRejectCode(reason string) wire.RejectCode {
+	for _, r := range referenceRejectCodes {
+		if strings.HasPrefix(reason, r.prefix) {
+			return r.code
+		}
+	}
+	return wire.RejectNonstandard
+}
+
+// txDiffOutcome describes how the acceptance of a transaction by the local
+// mempool compares to the reference node.
+type txDiffOutcome int
+
+const (
+	// txDiffAgree indicates both nodes accepted or both nodes rejected the
+	// transaction.
+	txDiffAgree txDiffOutcome = iota
+
+	// txDiffInconclusive indicates the nodes disagree, but at least one of
+	// them made its decision based on its mempool or chain state.
+	txDiffInconclusive
+
+	// txDiffDiverge indicates the nodes disagree based on their acceptance
+	// rules.
+	txDiffDiverge
+
+	// txDiffReasonMismatch indicates both nodes rejected the transaction
+	// based on their acceptance rules, but for different reasons.
+	txDiffReasonMismatch
+)
+
+// compareTxAcceptance returns how the local result of processing a
+// transaction, as indicated by the passed rejection error or nil when it was
+// accepted, compares to the result of the reference node.
+func compareTxAcceptance(localErr error,
+	remote *btcjson.TestMempoolAcceptResult) txDiffOutcome {
+
+	// Only rule errors reflect the acceptance rules of the mempool, while
+	// duplicate rejections are due to its state, such as already having
+	// the transaction or missing its inputs.
+	localState := false
+	var localCode wire.RejectCode
+	if localErr != nil {
+		localCode, _ = mempool.ErrToRejectErr(localErr)
+		_, ok := localErr.(mempool.RuleError)
+		localState = !ok || localCode == wire.RejectDuplicate
+	}
+	_, remoteState := txStateRejectReasons[remote.RejectReason]
+
+	if (localErr == nil) == remote.Allowed {
+		// Rejections for different rules hint at the rules having
+		// drifted apart even though the outcome is the same.
+		if localErr != nil && !localState && !remoteState &&
+			localCode != referenceRejectCode(remote.RejectReason) {
+
+			return txDiffReasonMismatch
+		}
+		return txDiffAgree
+	}
+
+	if localState || remoteState {
+		return txDiffInconclusive
+	}
+	return txDiffDiverge
+}
+
+// txDiffItem houses a transaction processed by the mempool along with the
+// reason it was rejected, or nil when it was accepted.
+type txDiffItem struct {
+	tx  *btcutil.Tx
+	err error
+}
+
+// txDiffer mirrors every transaction accepted or rejected by the mempool to
+// the testmempoolaccept RPC of a reference node, such as Dogecoin Core, and
+// logs where the two implementations disagree.  This helps catch policy and
+// consensus drift between them before it matters.
+type txDiffer struct {
+	// The following variables must only be used atomically.
+	dropped uint64
+
+	client *rpcclient.Client
+	queue  chan *txDiffItem
+	wg     sync.WaitGroup
+	quit   chan struct{}
+}
+
+// newTxDiffer returns a new differential transaction acceptance tester which
+// connects to the reference node configured by the diffnode options.
+func newTxDiffer() (*txDiffer, error) {
+	connCfg := &rpcclient.ConnConfig{
+		Host:           cfg.DiffNode,
+		User:           cfg.DiffNodeUser,
+		Pass:           cfg.DiffNodePass,
+		HTTPPostMode:   true,
+		DisableTLS:     cfg.DiffNodeCert == "",
+		RequestTimeout: txDiffRequestTimeout,
+
+		// Transactions which can't be compared right away aren't
+		// worth retrying since the reference node has likely seen
+		// other transactions in the meantime.
+		RetryPolicy: &rpcclient.RetryPolicy{MaxAttempts: 1},
+	}
+	if cfg.DiffNodeCert != "" {
+		certs, err := ioutil.ReadFile(cfg.DiffNodeCert)
+		if err != nil {
+			return nil, err
+		}
+		connCfg.Certificates = certs
+	}
+	client, err := rpcclient.New(connCfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txDiffer{
+		client: client,
+		queue:  make(chan *txDiffItem, maxTxDiffQueue),
+		quit:   make(chan struct{}),
+	}, nil
+}
+
+// TxProcessed queues the passed transaction processed by the mempool to be
+// compared against the reference node.  It never blocks, so it may be used as
+// the TxProcessed callback of the mempool.
+//
+// This function is safe for concurrent access.
+func (d *txDiffer) TxProcessed(tx *btcutil.Tx, err error) {
+	select {
+	case d.queue <- &txDiffItem{tx: tx, err: err}:
+	default:
+		atomic.AddUint64(&d.dropped, 1)
+	}
+}
+
+// compare mirrors the passed processed transaction to the reference node and
+// logs the outcome.  It returns an error when the reference node could not be
+// queried, while the transaction is skipped once the differ is stopped.
+func (d *txDiffer) compare(item *txDiffItem) error {
+	select {
+	case <-d.quit:
+		return nil
+	default:
+	}
+
+	results, err := d.client.TestMempoolAccept(
+		[]*wire.MsgTx{item.tx.MsgTx()}, 0)
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 {
+		return fmt.Errorf("unexpected number of results %d",
+			len(results))
+	}
+	remote := results[0]
+
+	local := "accepted"
+	if item.err != nil {
+		local = fmt.Sprintf("rejected (%v)", item.err)
+	}
+	reference := "allowed"
+	if !remote.Allowed {
+		reference = fmt.Sprintf("rejected (%s)", remote.RejectReason)
+	}
+
+	switch compareTxAcceptance(item.err, remote) {
+	case txDiffAgree:
+		tdifLog.Tracef("Transaction %v: %s locally, %s by reference "+
+			"node", item.tx.Hash(), local, reference)
+
+	case txDiffReasonMismatch:
+		tdifLog.Debugf("Transaction %v: %s locally, %s by reference "+
+			"node for a different reason", item.tx.Hash(), local,
+			reference)
+
+	case txDiffInconclusive:
+		tdifLog.Debugf("Transaction %v: %s locally, %s by reference "+
+			"node due to differing mempool or chain state",
+			item.tx.Hash(), local, reference)
+
+	case txDiffDiverge:
+		var buf bytes.Buffer
+		if err := item.tx.MsgTx().Serialize(&buf); err != nil {
+			return err
+		}
+		tdifLog.Warnf("Acceptance of transaction %v (wtxid %v) diverges "+
+			"from the reference node: %s locally, %s by reference "+
+			"node -- raw transaction: %s", item.tx.Hash(),
+			item.tx.WitnessHash(), local, reference,
+			hex.EncodeToString(buf.Bytes()))
+	}
+	return nil
+}
+
+// handler mirrors queued transactions to the reference node until the differ
+// is stopped.  It must be run as a goroutine.
+func (d *txDiffer) handler() {
+	defer d.wg.Done()
+
+	var failing bool
+	for {
+		select {
+		case item := <-d.queue:
+			err := d.compare(item)
+			select {
+			case <-d.quit:
+				// Queries aborted by the shutdown of the
+				// client aren't worth reporting.
+				return
+			default:
+			}
+			switch {
+			case err != nil && !failing:
+				failing = true
+				tdifLog.Warnf("Unable to query reference node %s: "+
+					"%v", cfg.DiffNode, err)
+
+			case err != nil:
+				tdifLog.Debugf("Unable to query reference node %s "+
+					"for transaction %v: %v", cfg.DiffNode,
+					item.tx.Hash(), err)
+
+			case failing:
+				failing = false
+				tdifLog.Infof("Reference node %s is reachable "+
+					"again", cfg.DiffNode)
+			}
+
+			if n := atomic.SwapUint64(&d.dropped, 0); n > 0 {
+				tdifLog.Warnf("Skipped comparing %d transactions "+
+					"since the queue was full", n)
+			}
+
+		case <-d.quit:
+			return
+		}
+	}
+}
+
+// Start begins mirroring transactions to the reference node.
+func (d *txDiffer) Start() {
+	tdifLog.Infof("Comparing transaction acceptance against reference "+
+		"node %s", cfg.DiffNode)
+
+	d.wg.Add(1)
+	go d.handler()
+}
+
+// Stop stops mirroring transactions and disconnects from the reference node.
+// A query which is in flight is aborted by shutting down the client so the
+// shutdown doesn't have to wait for an unresponsive reference node.
+func (d *txDiffer) Stop() {
+	close(d.quit)
+	d.client.Shutdown()
+	d.wg.Wait()
+}
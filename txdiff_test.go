@@ -0,0 +1,70 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dogesuite/doged/blockchain"
+	"github.com/dogesuite/doged/btcjson"
+	"github.com/dogesuite/doged/mempool"
+	"github.com/dogesuite/doged/wire"
+)
+
+// TestCompareTxAcceptance ensures disagreements with the reference node are
+// only reported as divergences when both nodes decided based on their
+// acceptance rules, and that rejections for different rules are told apart.
+func TestCompareTxAcceptance(t *testing.T) {
+	policyErr := mempool.RuleError{Err: mempool.TxRuleError{
+		RejectCode: wire.RejectNonstandard,
+	}}
+	consensusErr := mempool.RuleError{Err: blockchain.RuleError{
+		ErrorCode: blockchain.ErrScriptValidation,
+	}}
+	dustErr := mempool.RuleError{Err: mempool.TxRuleError{
+		RejectCode: wire.RejectDust,
+	}}
+	duplicateErr := mempool.RuleError{Err: mempool.TxRuleError{
+		RejectCode: wire.RejectDuplicate,
+	}}
+	allowed := &btcjson.TestMempoolAcceptResult{Allowed: true}
+	rejected := &btcjson.TestMempoolAcceptResult{RejectReason: "dust"}
+	invalid := &btcjson.TestMempoolAcceptResult{
+		RejectReason: "mandatory-script-verify-flag-failed (Script " +
+			"failed an OP_EQUALVERIFY operation)",
+	}
+	missing := &btcjson.TestMempoolAcceptResult{
+		RejectReason: "missing-inputs",
+	}
+
+	tests := []struct {
+		name     string
+		localErr error
+		remote   *btcjson.TestMempoolAcceptResult
+		want     txDiffOutcome
+	}{
+		{"both accept", nil, allowed, txDiffAgree},
+		{"both reject as dust", dustErr, rejected, txDiffAgree},
+		{"both reject for different rules", policyErr, rejected,
+			txDiffReasonMismatch},
+		{"both reject as invalid", consensusErr, invalid, txDiffAgree},
+		{"both reject for state", duplicateErr, missing, txDiffAgree},
+		{"local policy reject", policyErr, allowed, txDiffDiverge},
+		{"local consensus reject", consensusErr, allowed, txDiffDiverge},
+		{"remote policy reject", nil, rejected, txDiffDiverge},
+		{"local duplicate", duplicateErr, allowed, txDiffInconclusive},
+		{"local internal error", errors.New("db"), allowed,
+			txDiffInconclusive},
+		{"remote missing inputs", nil, missing, txDiffInconclusive},
+	}
+	for _, test := range tests {
+		got := compareTxAcceptance(test.localErr, test.remote)
+		if got != test.want {
+			t.Errorf("%s: unexpected outcome - got %d, want %d",
+				test.name, got, test.want)
+		}
+	}
+}
@@ -116,6 +116,77 @@ const (
 	ScriptVerifyDiscourageUpgradeablePubkeyType
 )
 
+// scriptFlagNames maps each script flag to the name the reference
+// implementation uses for it, in the order of the flag bits.
+var scriptFlagNames = []struct {
+	flag ScriptFlags
+	name string
+}{
+	{ScriptBip16, "P2SH"},
+	{ScriptStrictMultiSig, "NULLDUMMY"},
+	{ScriptDiscourageUpgradableNops, "DISCOURAGE_UPGRADABLE_NOPS"},
+	{ScriptVerifyCheckLockTimeVerify, "CHECKLOCKTIMEVERIFY"},
+	{ScriptVerifyCheckSequenceVerify, "CHECKSEQUENCEVERIFY"},
+	{ScriptVerifyCleanStack, "CLEANSTACK"},
+	{ScriptVerifyDERSignatures, "DERSIG"},
+	{ScriptVerifyLowS, "LOW_S"},
+	{ScriptVerifyMinimalData, "MINIMALDATA"},
+	{ScriptVerifyNullFail, "NULLFAIL"},
+	{ScriptVerifySigPushOnly, "SIGPUSHONLY"},
+	{ScriptVerifyStrictEncoding, "STRICTENC"},
+	{ScriptVerifyWitness, "WITNESS"},
+	{ScriptVerifyDiscourageUpgradeableWitnessProgram, "DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM"},
+	{ScriptVerifyMinimalIf, "MINIMALIF"},
+	{ScriptVerifyWitnessPubKeyType, "WITNESS_PUBKEYTYPE"},
+	{ScriptVerifyTaproot, "TAPROOT"},
+	{ScriptVerifyDiscourageUpgradeableTaprootVersion, "DISCOURAGE_UPGRADABLE_TAPROOT_VERSION"},
+	{ScriptVerifyDiscourageOpSuccess, "DISCOURAGE_OP_SUCCESS"},
+	{ScriptVerifyDiscourageUpgradeablePubkeyType, "DISCOURAGE_UPGRADABLE_PUBKEYTYPE"},
+}
+
+// ScriptFlagFromName returns the script flag with the passed name as used by
+// the reference implementation, such as "CLEANSTACK" or "TAPROOT".  The lookup
+// is case insensitive.  False is returned when no flag has the name.
+func ScriptFlagFromName(name string) (ScriptFlags, bool) {
+	for _, entry := range scriptFlagNames {
+		if strings.EqualFold(entry.name, name) {
+			return entry.flag, true
+		}
+	}
+	return 0, false
+}
+
+// Names returns the names of all flags set in the bitmask in the order of the
+// flag bits.
+func (flags ScriptFlags) Names() []string {
+	names := make([]string, 0, len(scriptFlagNames))
+	for _, entry := range scriptFlagNames {
+		if flags&entry.flag == entry.flag {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
+
+// Validate returns an error with the ErrInvalidFlags code when the flags form
+// a combination the engine refuses to verify scripts with.  Witness
+// verification requires pay-to-script-hash verification and a clean stack is
+// only required in combination with either of them.
+func (flags ScriptFlags) Validate() error {
+	if flags&ScriptBip16 != 0 {
+		return nil
+	}
+	if flags&ScriptVerifyWitness != 0 {
+		return scriptError(ErrInvalidFlags,
+			"WITNESS requires P2SH to be enabled")
+	}
+	if flags&ScriptVerifyCleanStack != 0 {
+		return scriptError(ErrInvalidFlags,
+			"CLEANSTACK requires P2SH or WITNESS to be enabled")
+	}
+	return nil
+}
+
 const (
 	// MaxStackSize is the maximum combined height of stack and alt stack
 	// during execution.
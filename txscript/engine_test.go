@@ -6,6 +6,7 @@
 package txscript
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/dogesuite/doged/chaincfg/chainhash"
@@ -426,3 +427,66 @@ func TestCheckSignatureEncoding(t *testing.T) {
 		}
 	}
 }
+
+// TestScriptFlagNames ensures every script flag has a name which maps back to
+// the flag.
+func TestScriptFlagNames(t *testing.T) {
+	t.Parallel()
+
+	for flag := ScriptBip16; flag <= ScriptVerifyDiscourageUpgradeablePubkeyType; flag <<= 1 {
+		names := flag.Names()
+		if len(names) != 1 {
+			t.Errorf("flag %#x: unexpected names %v", uint32(flag), names)
+			continue
+		}
+		got, ok := ScriptFlagFromName(names[0])
+		if !ok || got != flag {
+			t.Errorf("flag %#x: name %q maps to %#x", uint32(flag),
+				names[0], uint32(got))
+		}
+	}
+
+	if flag, ok := ScriptFlagFromName("cleanstack"); !ok ||
+		flag != ScriptVerifyCleanStack {
+
+		t.Errorf("lowercase name not recognized")
+	}
+	if _, ok := ScriptFlagFromName("BOGUS"); ok {
+		t.Errorf("unknown name recognized")
+	}
+
+	flags := ScriptBip16 | ScriptVerifyWitness | ScriptVerifyTaproot
+	want := []string{"P2SH", "WITNESS", "TAPROOT"}
+	if got := flags.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected names - got %v, want %v", got, want)
+	}
+}
+
+// TestScriptFlagsValidate ensures flag combinations the engine refuses to
+// verify scripts with are reported as invalid.
+func TestScriptFlagsValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		flags ScriptFlags
+		valid bool
+	}{
+		{0, true},
+		{ScriptBip16 | ScriptVerifyWitness | ScriptVerifyCleanStack, true},
+		{ScriptBip16 | ScriptVerifyCleanStack, true},
+		{ScriptVerifyWitness, false},
+		{ScriptVerifyCleanStack, false},
+		{ScriptVerifyWitness | ScriptVerifyCleanStack, false},
+	}
+	for _, test := range tests {
+		err := test.flags.Validate()
+		if test.valid && err != nil {
+			t.Errorf("flags %v: unexpected error: %v",
+				test.flags.Names(), err)
+		}
+		if !test.valid && !IsErrorCode(err, ErrInvalidFlags) {
+			t.Errorf("flags %v: unexpected error: %v",
+				test.flags.Names(), err)
+		}
+	}
+}
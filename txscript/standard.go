@@ -467,6 +467,32 @@ func isWitnessTaprootScript(script []byte) bool {
 	return extractWitnessV1KeyBytes(script) != nil
 }
 
+// IsWitnessUnknownScript returns true if the passed script is a witness
+// program with a version that does not have any meaning yet.  Such programs
+// are reserved for future soft forks and are therefore anyone-can-spend under
+// the current consensus rules.
+//
+// NOTE: GetScriptClass classifies these scripts as NonStandardTy, so callers
+// which need to tell them apart, such as standardness policy, must check for
+// them explicitly.
+func IsWitnessUnknownScript(script []byte) bool {
+	version, program, valid := extractWitnessProgramInfo(script)
+	if !valid || version == BaseSegwitWitnessVersion {
+		return false
+	}
+
+	// Only version 1 programs with a 32-byte x-only public key are taproot
+	// outputs.
+	return version != TaprootWitnessVersion || len(program) != 32
+}
+
+// IsAnnexedWitness returns true if the passed witness has a final push that is
+// a taproot annex.  The annex only has meaning when the witness spends a
+// taproot output.
+func IsAnnexedWitness(witness wire.TxWitness) bool {
+	return isAnnexedWitness(witness)
+}
+
 // isAnnexedWitness returns true if the passed witness has a final push
 // that is a witness annex.
 func isAnnexedWitness(witness wire.TxWitness) bool {
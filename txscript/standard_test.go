@@ -1107,6 +1107,33 @@ var scriptClassTests = []struct {
 		script: "0 DATA_32 0x9f96ade4b41d5433f4eda31e1738ec2b36f6e7d1420d94a6af99801a88f7f7ff",
 		class:  WitnessV0ScriptHashTy,
 	},
+	{
+		// A pay to taproot pk script.
+		name:   "Pay To Taproot",
+		script: "1 DATA_32 0x1a82f7457a9ba6ab1074e9f50053eefc637f8b046e389b636766bdc7d1f676f8",
+		class:  WitnessV1TaprootTy,
+	},
+	{
+		// A witness program with a version reserved for future soft
+		// forks is not a recognized script class.
+		name:   "witness v2 program",
+		script: "2 DATA_32 0x1a82f7457a9ba6ab1074e9f50053eefc637f8b046e389b636766bdc7d1f676f8",
+		class:  NonStandardTy,
+	},
+	{
+		// A version 1 witness program which is not a taproot output
+		// due to its length is not a recognized script class.
+		name:   "witness v1 short program",
+		script: "1 DATA_2 0x4e73",
+		class:  NonStandardTy,
+	},
+	{
+		// A version 0 witness program with an invalid length is not
+		// reserved for future use.
+		name:   "witness v0 invalid length",
+		script: "0 DATA_2 0x4e73",
+		class:  NonStandardTy,
+	},
 }
 
 // TestScriptClass ensures all the scripts in scriptClassTests have the expected
@@ -1125,6 +1152,30 @@ func TestScriptClass(t *testing.T) {
 	}
 }
 
+// TestIsWitnessUnknownScript ensures only witness programs with a version
+// reserved for future soft forks are identified as such.
+func TestIsWitnessUnknownScript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		script string
+		want   bool
+	}{
+		{"witness v2 program", "2 DATA_32 0x1a82f7457a9ba6ab1074e9f50053eefc637f8b046e389b636766bdc7d1f676f8", true},
+		{"witness v1 short program", "1 DATA_2 0x4e73", true},
+		{"taproot", "1 DATA_32 0x1a82f7457a9ba6ab1074e9f50053eefc637f8b046e389b636766bdc7d1f676f8", false},
+		{"witness v0 invalid length", "0 DATA_2 0x4e73", false},
+		{"pay to pubkey hash", "DUP HASH160 DATA_20 0x433ec2ac1ffa1b7b7d027f564529c57197f9ae88 EQUALVERIFY CHECKSIG", false},
+	}
+	for _, test := range tests {
+		script := mustParseShortForm(test.script)
+		if got := IsWitnessUnknownScript(script); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
 // TestStringifyClass ensures the script class string returns the expected
 // string for each script class.
 func TestStringifyClass(t *testing.T) {
@@ -165,7 +165,58 @@ func upgradeDataPaths() error {
 	return nil
 }
 
-// doUpgrades performs upgrades to btcd as new versions require it.
+// upgradeNetDataDir moves a block database, along with the address manager
+// state, which resides directly in the configured data directory instead of the
+// subdirectory for its network into that subdirectory.  Such a flat layout is
+// the result of data directories that were prepared by hand, or copied from
+// software which keeps the data for the main network at the top level.  Since
+// the network the data belongs to is determined from the block database, only
+// data for the active network is moved.
+//
+// The block database is moved last, so a migration which was interrupted is
+// completed on the next start.  The data directory of the network MUST be
+// locked by the caller so that no other instance for the network migrates at
+// the same time.
+func upgradeNetDataDir() error {
+	rootDir := filepath.Dir(cfg.DataDir)
+	oldDbPath := filepath.Join(rootDir, blockDbNamePrefix+"_"+cfg.DbType)
+	net, ok := blockDbNetwork(oldDbPath)
+	if !ok || net != activeNetParams.Net {
+		return nil
+	}
+
+	newDbPath := blockDbPath(cfg.DbType)
+	if fileExists(newDbPath) {
+		btcdLog.Warnf("Not migrating the block database in '%s' since "+
+			"'%s' already exists", oldDbPath, newDbPath)
+		return nil
+	}
+
+	btcdLog.Infof("Migrating block database from '%s' to '%s'", oldDbPath,
+		newDbPath)
+	err := os.MkdirAll(cfg.DataDir, 0700)
+	if err != nil {
+		return err
+	}
+
+	// Move the address manager state first since it was created for the
+	// same network as the database, which can no longer be told once the
+	// database was moved.
+	oldPeersPath := filepath.Join(rootDir, "peers.json")
+	newPeersPath := filepath.Join(cfg.DataDir, "peers.json")
+	if fileExists(oldPeersPath) && !fileExists(newPeersPath) {
+		err := os.Rename(oldPeersPath, newPeersPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(oldDbPath, newDbPath)
+}
+
+// doUpgrades performs upgrades to btcd as new versions require it.  Upgrades
+// of the data directory of the network are performed separately by
+// upgradeNetDataDir once it is locked.
 func doUpgrades() error {
 	err := upgradeDBPaths()
 	if err != nil {
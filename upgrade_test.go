@@ -0,0 +1,164 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btclog"
+	"github.com/dogesuite/doged/wire"
+)
+
+// TestUpgradeNetDataDir ensures a block database and address manager state
+// residing directly in the data directory are moved into the subdirectory of
+// the network, including when an earlier migration was interrupted, while an
+// already migrated data directory is left alone.
+func TestUpgradeNetDataDir(t *testing.T) {
+	// Silence the logging of the migration since the log rotator is not
+	// initialized during tests.
+	level := btcdLog.Level()
+	btcdLog.SetLevel(btclog.LevelOff)
+	defer btcdLog.SetLevel(level)
+
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+
+	// writeBlockDb creates an ffldb block database for the passed network
+	// in the passed directory.
+	writeBlockDb := func(dir string, net wire.BitcoinNet) {
+		dbPath := filepath.Join(dir, blockDbNamePrefix+"_ffldb")
+		if err := os.MkdirAll(dbPath, 0700); err != nil {
+			t.Fatalf("Failed creating database directory: %v", err)
+		}
+		var record [8]byte
+		binary.LittleEndian.PutUint32(record[:], uint32(net))
+		err := ioutil.WriteFile(filepath.Join(dbPath,
+			ffldbFirstBlockFilename), record[:], 0600)
+		if err != nil {
+			t.Fatalf("Failed writing block file: %v", err)
+		}
+	}
+	writePeers := func(dir, contents string) {
+		err := ioutil.WriteFile(filepath.Join(dir, "peers.json"),
+			[]byte(contents), 0600)
+		if err != nil {
+			t.Fatalf("Failed writing peers file: %v", err)
+		}
+	}
+	readPeers := func(dir string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, "peers.json"))
+		if err != nil {
+			return ""
+		}
+		return string(contents)
+	}
+
+	tests := []struct {
+		name string
+
+		// setup prepares the root data directory and the data
+		// directory of the main network.
+		setup func(rootDir, netDir string)
+
+		// migrated is whether the block database of the data directory
+		// is expected to be moved into the data directory of the
+		// network, and netPeers and rootPeers are the expected
+		// contents of the peers files afterwards.
+		migrated  bool
+		netPeers  string
+		rootPeers string
+	}{
+		{
+			name: "flat layout",
+			setup: func(rootDir, netDir string) {
+				writeBlockDb(rootDir, wire.MainNet)
+				writePeers(rootDir, "root")
+			},
+			migrated: true,
+			netPeers: "root",
+		},
+		{
+			name: "flat layout of another network",
+			setup: func(rootDir, netDir string) {
+				writeBlockDb(rootDir, wire.TestNet3)
+				writePeers(rootDir, "root")
+			},
+			rootPeers: "root",
+		},
+		{
+			name: "already migrated",
+			setup: func(rootDir, netDir string) {
+				writeBlockDb(netDir, wire.MainNet)
+				writePeers(netDir, "net")
+			},
+			netPeers: "net",
+		},
+		{
+			name: "already migrated with a database left behind",
+			setup: func(rootDir, netDir string) {
+				writeBlockDb(rootDir, wire.MainNet)
+				writePeers(rootDir, "root")
+				writeBlockDb(netDir, wire.MainNet)
+				writePeers(netDir, "net")
+			},
+			netPeers:  "net",
+			rootPeers: "root",
+		},
+		{
+			name: "partially migrated",
+			setup: func(rootDir, netDir string) {
+				// The peers file was moved before the migration
+				// was interrupted.
+				writeBlockDb(rootDir, wire.MainNet)
+				if err := os.MkdirAll(netDir, 0700); err != nil {
+					t.Fatalf("Failed creating data "+
+						"directory: %v", err)
+				}
+				writePeers(netDir, "root")
+			},
+			migrated: true,
+			netPeers: "root",
+		},
+	}
+
+	for _, test := range tests {
+		rootDir, err := ioutil.TempDir("", "btcd")
+		if err != nil {
+			t.Fatalf("Failed creating a temporary directory: %v", err)
+		}
+		defer os.RemoveAll(rootDir)
+
+		netDir := filepath.Join(rootDir, netName(&mainNetParams))
+		cfg = &config{DataDir: netDir, DbType: "ffldb"}
+		test.setup(rootDir, netDir)
+
+		if err := upgradeNetDataDir(); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		rootDb := filepath.Join(rootDir, blockDbNamePrefix+"_ffldb")
+		netDb := filepath.Join(netDir, blockDbNamePrefix+"_ffldb")
+		if test.migrated && (fileExists(rootDb) || !fileExists(netDb)) {
+			t.Errorf("%s: block database was not migrated", test.name)
+		}
+		if !test.migrated && fileExists(rootDb) != (test.rootPeers != "") {
+			t.Errorf("%s: block database was unexpectedly moved",
+				test.name)
+		}
+		if got := readPeers(netDir); got != test.netPeers {
+			t.Errorf("%s: unexpected network peers file %q, want %q",
+				test.name, got, test.netPeers)
+		}
+		if got := readPeers(rootDir); got != test.rootPeers {
+			t.Errorf("%s: unexpected root peers file %q, want %q",
+				test.name, got, test.rootPeers)
+		}
+	}
+}
@@ -0,0 +1,493 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dogesuite/doged/blockchain"
+	"github.com/dogesuite/doged/btcjson"
+	"github.com/dogesuite/doged/btcutil"
+	"github.com/dogesuite/doged/btcutil/psbt"
+	"github.com/dogesuite/doged/chaincfg"
+	"github.com/dogesuite/doged/txscript"
+	"github.com/dogesuite/doged/wire"
+	flags "github.com/jessevdk/go-flags"
+)
+
+const (
+	// utilCmdName is the first command line argument which selects the
+	// offline utility subcommands instead of starting the node.
+	utilCmdName = "util"
+)
+
+// utilCommand describes an offline utility subcommand which decodes raw data
+// into its JSON representation.
+type utilCommand struct {
+	// description is a short one-line summary shown in the usage output.
+	description string
+
+	// decode converts the raw (already hex, base64 or binary decoded)
+	// input into a value suitable for JSON marshalling.
+	decode func(data []byte, chainParams *chaincfg.Params) (interface{}, error)
+}
+
+// utilCommands maps the names of the supported utility subcommands to their
+// implementations.
+var utilCommands = map[string]utilCommand{
+	"decodeblock": {
+		description: "Decode a serialized block",
+		decode:      decodeBlockUtil,
+	},
+	"decodetx": {
+		description: "Decode a serialized transaction",
+		decode:      decodeTxUtil,
+	},
+	"decodescript": {
+		description: "Decode a script",
+		decode:      decodeScriptUtil,
+	},
+	"decodepsbt": {
+		description: "Decode a partially signed transaction (base64 by default)",
+		decode:      decodePsbtUtil,
+	},
+}
+
+// utilOptions defines the command line options accepted by the utility
+// subcommands.
+type utilOptions struct {
+	Binary         bool `long:"binary" description:"Read raw binary input instead of hex (or base64 for decodepsbt)"`
+	RegressionTest bool `long:"regtest" description:"Use the regression test network parameters"`
+	SimNet         bool `long:"simnet" description:"Use the simulation test network parameters"`
+	SigNet         bool `long:"signet" description:"Use the signet test network parameters"`
+	TestNet3       bool `long:"testnet" description:"Use the test network parameters"`
+}
+
+// utilUsage returns the usage text listing all of the utility subcommands.
+func utilUsage(appName string) string {
+	names := make([]string, 0, len(utilCommands))
+	for name := range utilCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Usage:\n  %s %s <subcommand> [OPTIONS] [FILE]\n\n",
+		appName, utilCmdName)
+	fmt.Fprintf(&buf, "Reads from FILE, or standard input when FILE is "+
+		"omitted or '-'.\n\nSubcommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "  %-14s %s\n", name,
+			utilCommands[name].description)
+	}
+	return buf.String()
+}
+
+// runUtilCommand parses and executes the offline utility subcommand described
+// by args, which excludes the program name and the util keyword itself.  The
+// decoded result is written to stdout as indented JSON.
+func runUtilCommand(appName string, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stdout, utilUsage(appName))
+		return nil
+	}
+
+	name := args[0]
+	utilCmd, ok := utilCommands[name]
+	if !ok {
+		return fmt.Errorf("unknown %s subcommand '%s'\n\n%s", utilCmdName,
+			name, utilUsage(appName))
+	}
+
+	var opts utilOptions
+	parser := flags.NewParser(&opts, flags.HelpFlag)
+	parser.Name = appName + " " + utilCmdName + " " + name
+	parser.Usage = "[OPTIONS] [FILE]"
+	remainingArgs, err := parser.ParseArgs(args[1:])
+	if err != nil {
+		if e, ok := err.(*flags.Error); ok && e.Type == flags.ErrHelp {
+			fmt.Fprintln(stdout, err)
+			return nil
+		}
+		return err
+	}
+	if len(remainingArgs) > 1 {
+		return fmt.Errorf("%s: too many arguments -- only a single "+
+			"input file may be specified", name)
+	}
+
+	// Select the network parameters used when encoding addresses.
+	netParams := &mainNetParams
+	numNets := 0
+	if opts.TestNet3 {
+		numNets++
+		netParams = &testNet3Params
+	}
+	if opts.RegressionTest {
+		numNets++
+		netParams = &regressionNetParams
+	}
+	if opts.SimNet {
+		numNets++
+		netParams = &simNetParams
+	}
+	if opts.SigNet {
+		numNets++
+		netParams = &sigNetParams
+	}
+	if numNets > 1 {
+		return fmt.Errorf("%s: the testnet, regtest, signet and simnet "+
+			"params can't be used together -- choose one", name)
+	}
+
+	// Read the input from the named file or stdin.
+	var input []byte
+	if len(remainingArgs) == 0 || remainingArgs[0] == "-" {
+		input, err = ioutil.ReadAll(stdin)
+	} else {
+		input, err = ioutil.ReadFile(cleanAndExpandPath(remainingArgs[0]))
+	}
+	if err != nil {
+		return err
+	}
+
+	data := input
+	if !opts.Binary {
+		data, err = decodeUtilText(name, input)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := utilCmd.decode(data, netParams.Params)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	marshalled, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "%s\n", marshalled)
+	return err
+}
+
+// decodeUtilText decodes the textual input of the named subcommand.  All
+// whitespace is ignored so data wrapped across multiple lines, such as when it
+// was copied from a log file, can be decoded directly.  PSBTs are expected to
+// be base64 encoded while everything else is hex encoded.
+func decodeUtilText(name string, input []byte) ([]byte, error) {
+	text := strings.Join(strings.Fields(string(input)), "")
+	if text == "" {
+		return nil, errors.New("no input data provided")
+	}
+
+	if name == "decodepsbt" {
+		data, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 input: %v", err)
+		}
+		return data, nil
+	}
+
+	if len(text)%2 != 0 {
+		text = "0" + text
+	}
+	data, err := hex.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex input: %v", err)
+	}
+	return data, nil
+}
+
+// decodeBlockUtil implements the decodeblock utility subcommand.  The result
+// mirrors the getblock RPC with verbosity 2 minus the fields that require
+// chain context such as the height and number of confirmations.
+func decodeBlockUtil(data []byte, chainParams *chaincfg.Params) (interface{}, error) {
+	blk, err := btcutil.NewBlockFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("block decode failed: %v", err)
+	}
+
+	blockHeader := &blk.MsgBlock().Header
+	blockHash := blk.Hash().String()
+	txns := blk.Transactions()
+	rawTxns := make([]btcjson.TxRawResult, len(txns))
+	for i, tx := range txns {
+		rawTxn, err := createTxRawResult(chainParams, tx.MsgTx(),
+			tx.Hash().String(), nil, "", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		rawTxn.BlockHash = blockHash
+		rawTxn.Time = blockHeader.Timestamp.Unix()
+		rawTxn.Blocktime = blockHeader.Timestamp.Unix()
+		rawTxns[i] = *rawTxn
+	}
+
+	return &btcjson.GetBlockVerboseResult{
+		Hash:         blockHash,
+		Version:      blockHeader.Version,
+		VersionHex:   fmt.Sprintf("%08x", blockHeader.Version),
+		MerkleRoot:   blockHeader.MerkleRoot.String(),
+		PreviousHash: blockHeader.PrevBlock.String(),
+		Nonce:        blockHeader.Nonce,
+		Time:         blockHeader.Timestamp.Unix(),
+		Size:         int32(len(data)),
+		StrippedSize: int32(blk.MsgBlock().SerializeSizeStripped()),
+		Weight:       int32(blockchain.GetBlockWeight(blk)),
+		Bits:         strconv.FormatInt(int64(blockHeader.Bits), 16),
+		Difficulty:   getDifficultyRatio(blockHeader.Bits, chainParams),
+		RawTx:        rawTxns,
+	}, nil
+}
+
+// decodeTxUtil implements the decodetx utility subcommand.
+func decodeTxUtil(data []byte, chainParams *chaincfg.Params) (interface{}, error) {
+	var mtx wire.MsgTx
+	if err := mtx.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("TX decode failed: %v", err)
+	}
+
+	txHash := mtx.TxHash()
+	return createTxRawResult(chainParams, &mtx, txHash.String(), nil, "",
+		0, 0)
+}
+
+// decodeScriptUtil implements the decodescript utility subcommand.
+func decodeScriptUtil(data []byte, chainParams *chaincfg.Params) (interface{}, error) {
+	return createDecodeScriptResult(data, chainParams)
+}
+
+// psbtUtxoResult models the witness UTXO of a decoded PSBT input.
+type psbtUtxoResult struct {
+	Amount       float64                    `json:"amount"`
+	ScriptPubKey btcjson.DecodeScriptResult `json:"scriptPubKey"`
+	Hex          string                     `json:"hex"`
+}
+
+// psbtBip32DerivResult models a BIP 32 key derivation entry of a decoded PSBT
+// input or output.
+type psbtBip32DerivResult struct {
+	PubKey            string `json:"pubkey"`
+	MasterFingerprint string `json:"master_fingerprint"`
+	Path              string `json:"path"`
+}
+
+// psbtInputResult models a single input of a decoded PSBT.
+type psbtInputResult struct {
+	NonWitnessUtxo     *btcjson.TxRawDecodeResult  `json:"non_witness_utxo,omitempty"`
+	WitnessUtxo        *psbtUtxoResult             `json:"witness_utxo,omitempty"`
+	PartialSignatures  map[string]string           `json:"partial_signatures,omitempty"`
+	SigHash            string                      `json:"sighash,omitempty"`
+	RedeemScript       *btcjson.DecodeScriptResult `json:"redeem_script,omitempty"`
+	WitnessScript      *btcjson.DecodeScriptResult `json:"witness_script,omitempty"`
+	Bip32Derivs        []psbtBip32DerivResult      `json:"bip32_derivs,omitempty"`
+	FinalScriptSig     *btcjson.ScriptSig          `json:"final_scriptSig,omitempty"`
+	FinalScriptWitness string                      `json:"final_scriptwitness,omitempty"`
+	TaprootKeyPathSig  string                      `json:"taproot_key_path_sig,omitempty"`
+	TaprootInternalKey string                      `json:"taproot_internal_key,omitempty"`
+	TaprootMerkleRoot  string                      `json:"taproot_merkle_root,omitempty"`
+	Unknown            map[string]string           `json:"unknown,omitempty"`
+}
+
+// psbtOutputResult models a single output of a decoded PSBT.
+type psbtOutputResult struct {
+	RedeemScript       *btcjson.DecodeScriptResult `json:"redeem_script,omitempty"`
+	WitnessScript      *btcjson.DecodeScriptResult `json:"witness_script,omitempty"`
+	Bip32Derivs        []psbtBip32DerivResult      `json:"bip32_derivs,omitempty"`
+	TaprootInternalKey string                      `json:"taproot_internal_key,omitempty"`
+}
+
+// psbtDecodeResult models the result of the decodepsbt utility subcommand.  It
+// follows the layout of the decodepsbt RPC of the reference implementation.
+type psbtDecodeResult struct {
+	Tx      btcjson.TxRawDecodeResult `json:"tx"`
+	Unknown map[string]string         `json:"unknown"`
+	Inputs  []psbtInputResult         `json:"inputs"`
+	Outputs []psbtOutputResult        `json:"outputs"`
+	Fee     *float64                  `json:"fee,omitempty"`
+}
+
+// createPsbtBip32Derivs converts the passed BIP 32 derivations to their JSON
+// representation.
+func createPsbtBip32Derivs(derivs []*psbt.Bip32Derivation) []psbtBip32DerivResult {
+	if len(derivs) == 0 {
+		return nil
+	}
+
+	results := make([]psbtBip32DerivResult, 0, len(derivs))
+	for _, deriv := range derivs {
+		path := "m"
+		for _, index := range deriv.Bip32Path {
+			if index >= 0x80000000 {
+				path += fmt.Sprintf("/%d'", index-0x80000000)
+				continue
+			}
+			path += fmt.Sprintf("/%d", index)
+		}
+		results = append(results, psbtBip32DerivResult{
+			PubKey:            hex.EncodeToString(deriv.PubKey),
+			MasterFingerprint: fmt.Sprintf("%08x", deriv.MasterKeyFingerprint),
+			Path:              path,
+		})
+	}
+	return results
+}
+
+// optionalDecodeScript returns the decoded script or nil when the passed
+// script is empty.
+func optionalDecodeScript(script []byte, chainParams *chaincfg.Params) (*btcjson.DecodeScriptResult, error) {
+	if len(script) == 0 {
+		return nil, nil
+	}
+	return createDecodeScriptResult(script, chainParams)
+}
+
+// decodePsbtUtil implements the decodepsbt utility subcommand.
+func decodePsbtUtil(data []byte, chainParams *chaincfg.Params) (interface{}, error) {
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(data), false)
+	if err != nil {
+		return nil, fmt.Errorf("PSBT decode failed: %v", err)
+	}
+
+	result := psbtDecodeResult{
+		Tx:      *createTxRawDecodeResult(packet.UnsignedTx, chainParams),
+		Unknown: make(map[string]string, len(packet.Unknowns)),
+		Inputs:  make([]psbtInputResult, len(packet.Inputs)),
+		Outputs: make([]psbtOutputResult, len(packet.Outputs)),
+	}
+	for _, unknown := range packet.Unknowns {
+		result.Unknown[hex.EncodeToString(unknown.Key)] =
+			hex.EncodeToString(unknown.Value)
+	}
+
+	for i := range packet.Inputs {
+		pIn := &packet.Inputs[i]
+		in := &result.Inputs[i]
+
+		if pIn.NonWitnessUtxo != nil {
+			in.NonWitnessUtxo = createTxRawDecodeResult(
+				pIn.NonWitnessUtxo, chainParams,
+			)
+		}
+		if pIn.WitnessUtxo != nil {
+			script, err := createDecodeScriptResult(
+				pIn.WitnessUtxo.PkScript, chainParams,
+			)
+			if err != nil {
+				return nil, err
+			}
+			in.WitnessUtxo = &psbtUtxoResult{
+				Amount:       btcutil.Amount(pIn.WitnessUtxo.Value).ToBTC(),
+				ScriptPubKey: *script,
+				Hex:          hex.EncodeToString(pIn.WitnessUtxo.PkScript),
+			}
+		}
+		if len(pIn.PartialSigs) > 0 {
+			in.PartialSignatures = make(map[string]string,
+				len(pIn.PartialSigs))
+			for _, sig := range pIn.PartialSigs {
+				in.PartialSignatures[hex.EncodeToString(sig.PubKey)] =
+					hex.EncodeToString(sig.Signature)
+			}
+		}
+		if pIn.SighashType != 0 {
+			in.SigHash = fmt.Sprintf("0x%02x", uint32(pIn.SighashType))
+		}
+		in.RedeemScript, err = optionalDecodeScript(pIn.RedeemScript,
+			chainParams)
+		if err != nil {
+			return nil, err
+		}
+		in.WitnessScript, err = optionalDecodeScript(pIn.WitnessScript,
+			chainParams)
+		if err != nil {
+			return nil, err
+		}
+		in.Bip32Derivs = createPsbtBip32Derivs(pIn.Bip32Derivation)
+		if len(pIn.FinalScriptSig) > 0 {
+			in.FinalScriptSig = &btcjson.ScriptSig{
+				Hex: hex.EncodeToString(pIn.FinalScriptSig),
+			}
+			in.FinalScriptSig.Asm, _ = txscript.DisasmString(
+				pIn.FinalScriptSig,
+			)
+		}
+		if len(pIn.FinalScriptWitness) > 0 {
+			in.FinalScriptWitness = hex.EncodeToString(
+				pIn.FinalScriptWitness,
+			)
+		}
+		if len(pIn.TaprootKeySpendSig) > 0 {
+			in.TaprootKeyPathSig = hex.EncodeToString(
+				pIn.TaprootKeySpendSig,
+			)
+		}
+		if len(pIn.TaprootInternalKey) > 0 {
+			in.TaprootInternalKey = hex.EncodeToString(
+				pIn.TaprootInternalKey,
+			)
+		}
+		if len(pIn.TaprootMerkleRoot) > 0 {
+			in.TaprootMerkleRoot = hex.EncodeToString(
+				pIn.TaprootMerkleRoot,
+			)
+		}
+		if len(pIn.Unknowns) > 0 {
+			in.Unknown = make(map[string]string, len(pIn.Unknowns))
+			for _, unknown := range pIn.Unknowns {
+				in.Unknown[hex.EncodeToString(unknown.Key)] =
+					hex.EncodeToString(unknown.Value)
+			}
+		}
+	}
+
+	for i := range packet.Outputs {
+		pOut := &packet.Outputs[i]
+		out := &result.Outputs[i]
+
+		out.RedeemScript, err = optionalDecodeScript(pOut.RedeemScript,
+			chainParams)
+		if err != nil {
+			return nil, err
+		}
+		out.WitnessScript, err = optionalDecodeScript(pOut.WitnessScript,
+			chainParams)
+		if err != nil {
+			return nil, err
+		}
+		out.Bip32Derivs = createPsbtBip32Derivs(pOut.Bip32Derivation)
+		if len(pOut.TaprootInternalKey) > 0 {
+			out.TaprootInternalKey = hex.EncodeToString(
+				pOut.TaprootInternalKey,
+			)
+		}
+	}
+
+	// The fee can only be calculated when the previous outputs of every
+	// input are known.
+	inputSum, err := psbt.SumUtxoInputValues(packet)
+	if err == nil {
+		var outputSum int64
+		for _, txOut := range packet.UnsignedTx.TxOut {
+			outputSum += txOut.Value
+		}
+		fee := btcutil.Amount(inputSum - outputSum).ToBTC()
+		result.Fee = &fee
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dogesuite/doged/btcjson"
+	"github.com/dogesuite/doged/btcutil/psbt"
+	"github.com/dogesuite/doged/chaincfg"
+	"github.com/dogesuite/doged/wire"
+)
+
+// runUtilTest runs the named utility subcommand against the provided input and
+// unmarshals the JSON output into result.
+func runUtilTest(t *testing.T, args []string, input string, result interface{}) {
+	t.Helper()
+
+	var stdout bytes.Buffer
+	err := runUtilCommand("doged", args, strings.NewReader(input), &stdout)
+	if err != nil {
+		t.Fatalf("%v: unexpected error: %v", args, err)
+	}
+	if err := json.Unmarshal(stdout.Bytes(), result); err != nil {
+		t.Fatalf("%v: unable to unmarshal output %q: %v", args,
+			stdout.String(), err)
+	}
+}
+
+// TestUtilDecodeBlockAndTx ensures the decodeblock and decodetx utility
+// subcommands decode the genesis block and its coinbase transaction.
+func TestUtilDecodeBlockAndTx(t *testing.T) {
+	genesis := chaincfg.MainNetParams.GenesisBlock
+	var blockBuf bytes.Buffer
+	if err := genesis.Serialize(&blockBuf); err != nil {
+		t.Fatalf("unable to serialize genesis block: %v", err)
+	}
+
+	// Split the hex over several lines to ensure whitespace is ignored.
+	blockHex := hex.EncodeToString(blockBuf.Bytes())
+	blockHex = blockHex[:80] + "\n  " + blockHex[80:] + "\n"
+
+	var blockResult btcjson.GetBlockVerboseResult
+	runUtilTest(t, []string{"decodeblock"}, blockHex, &blockResult)
+	if blockResult.Hash != genesis.BlockHash().String() {
+		t.Fatalf("decodeblock: unexpected hash - got %v, want %v",
+			blockResult.Hash, genesis.BlockHash())
+	}
+	if len(blockResult.RawTx) != len(genesis.Transactions) {
+		t.Fatalf("decodeblock: unexpected number of transactions - "+
+			"got %d, want %d", len(blockResult.RawTx),
+			len(genesis.Transactions))
+	}
+
+	coinbase := genesis.Transactions[0]
+	var txBuf bytes.Buffer
+	if err := coinbase.Serialize(&txBuf); err != nil {
+		t.Fatalf("unable to serialize coinbase: %v", err)
+	}
+	var txResult btcjson.TxRawResult
+	runUtilTest(t, []string{"decodetx"}, hex.EncodeToString(txBuf.Bytes()),
+		&txResult)
+	if txResult.Txid != coinbase.TxHash().String() {
+		t.Fatalf("decodetx: unexpected txid - got %v, want %v",
+			txResult.Txid, coinbase.TxHash())
+	}
+	if len(txResult.Vin) != 1 || txResult.Vin[0].Coinbase == "" {
+		t.Fatalf("decodetx: coinbase input not decoded: %+v",
+			txResult.Vin)
+	}
+}
+
+// TestUtilDecodeScript ensures the decodescript utility subcommand honors the
+// selected network when encoding addresses.
+func TestUtilDecodeScript(t *testing.T) {
+	// Pay-to-pubkey-hash script with a zero hash.
+	script := "76a914" + strings.Repeat("00", 20) + "88ac"
+
+	var mainResult, testResult btcjson.DecodeScriptResult
+	runUtilTest(t, []string{"decodescript"}, script, &mainResult)
+	runUtilTest(t, []string{"decodescript", "--testnet"}, script,
+		&testResult)
+
+	if mainResult.Type != "pubkeyhash" {
+		t.Fatalf("unexpected script type %q", mainResult.Type)
+	}
+	if len(mainResult.Addresses) != 1 || len(testResult.Addresses) != 1 {
+		t.Fatalf("unexpected addresses - mainnet %v, testnet %v",
+			mainResult.Addresses, testResult.Addresses)
+	}
+	if mainResult.Addresses[0] == testResult.Addresses[0] {
+		t.Fatalf("address not encoded for the selected network: %v",
+			mainResult.Addresses[0])
+	}
+}
+
+// TestUtilDecodePsbt ensures the decodepsbt utility subcommand decodes a
+// base64 encoded PSBT including its fee when the input values are known.
+func TestUtilDecodePsbt(t *testing.T) {
+	packet, err := psbt.New(
+		[]*wire.OutPoint{{Index: 1}}, []*wire.TxOut{{
+			Value:    90000,
+			PkScript: []byte{0x51},
+		}}, 2, 0, []uint32{wire.MaxTxInSequenceNum},
+	)
+	if err != nil {
+		t.Fatalf("unable to create psbt: %v", err)
+	}
+	packet.Inputs[0].WitnessUtxo = &wire.TxOut{
+		Value:    100000,
+		PkScript: []byte{0x51},
+	}
+	encoded, err := packet.B64Encode()
+	if err != nil {
+		t.Fatalf("unable to encode psbt: %v", err)
+	}
+
+	var result psbtDecodeResult
+	runUtilTest(t, []string{"decodepsbt"}, encoded, &result)
+	if result.Tx.Txid != packet.UnsignedTx.TxHash().String() {
+		t.Fatalf("unexpected txid - got %v, want %v", result.Tx.Txid,
+			packet.UnsignedTx.TxHash())
+	}
+	if len(result.Inputs) != 1 || result.Inputs[0].WitnessUtxo == nil {
+		t.Fatalf("witness utxo not decoded: %+v", result.Inputs)
+	}
+	if result.Fee == nil || *result.Fee != 0.0001 {
+		t.Fatalf("unexpected fee %v", result.Fee)
+	}
+}
+
+// TestUtilCommandErrors ensures invalid utility invocations are rejected.
+func TestUtilCommandErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+	}{
+		{"unknown subcommand", []string{"decodefoo"}, "00"},
+		{"invalid hex", []string{"decodetx"}, "zz"},
+		{"empty input", []string{"decodetx"}, " \n"},
+		{"multiple networks", []string{"decodetx", "--testnet", "--simnet"}, "00"},
+		{"malformed tx", []string{"decodetx"}, "0100"},
+	}
+
+	for _, test := range tests {
+		var stdout bytes.Buffer
+		err := runUtilCommand("doged", test.args,
+			strings.NewReader(test.input), &stdout)
+		if err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+}